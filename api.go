@@ -3,7 +3,14 @@
 // edamame provides SQL AST capabilities.
 package vecna
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
 
 // Errors returned by vecna.
 var (
@@ -15,6 +22,9 @@ var (
 
 	// ErrInvalidFilter is returned when a filter contains validation errors.
 	ErrInvalidFilter = errors.New("vecna: invalid filter")
+
+	// ErrInvalidSchema is returned when a schema cannot be used to derive fields.
+	ErrInvalidSchema = errors.New("vecna: invalid schema")
 )
 
 // Op represents a filter operator.
@@ -22,53 +32,81 @@ type Op uint8
 
 // Filter operators.
 const (
-	Eq       Op = iota // Equal
-	Ne                 // Not equal
-	Gt                 // Greater than
-	Gte                // Greater than or equal
-	Lt                 // Less than
-	Lte                // Less than or equal
-	In                 // In set
-	Nin                // Not in set
-	Like               // Pattern match
-	Contains           // Array contains
-	And                // Logical AND
-	Or                 // Logical OR
-	Not                // Logical NOT
+	Eq          Op = iota // Equal
+	Ne                    // Not equal
+	Gt                    // Greater than
+	Gte                   // Greater than or equal
+	Lt                    // Less than
+	Lte                   // Less than or equal
+	In                    // In set
+	Nin                   // Not in set
+	Like                  // Pattern match
+	Contains              // Array contains
+	And                   // Logical AND
+	Or                    // Logical OR
+	Not                   // Logical NOT
+	Between               // Inclusive range
+	Regex                 // Regular expression match
+	Prefix                // String starts with
+	Suffix                // String ends with
+	Approx                // Approximately equal within a tolerance
+	ContainsAll           // Array contains every value
+	ContainsAny           // Array contains at least one value
+	GeoBox                // Point within a latitude/longitude box
+	Raw                   // Backend-specific predicate
+	All                   // Matches everything; omitted from And, absorbs Or
+	None                  // Matches nothing
+	ILike                 // Case-insensitive pattern match
+	NotLike               // Negated pattern match
+	IsEmpty               // Array has no elements
+	IsNotEmpty            // Array has at least one element
+	Len                   // Array length comparison
+	Xor                   // Logical exclusive OR of two filters
+
+	numOps // Number of operators; keep last
 )
 
-// String returns the string representation of the operator.
+// opNames holds the string form of each operator, indexed by Op.
+var opNames = [numOps]string{
+	Eq:          "eq",
+	Ne:          "ne",
+	Gt:          "gt",
+	Gte:         "gte",
+	Lt:          "lt",
+	Lte:         "lte",
+	In:          "in",
+	Nin:         "nin",
+	Like:        "like",
+	Contains:    "contains",
+	And:         "and",
+	Or:          "or",
+	Not:         "not",
+	Between:     "between",
+	Regex:       "regex",
+	Prefix:      "prefix",
+	Suffix:      "suffix",
+	Approx:      "approx",
+	ContainsAll: "contains_all",
+	ContainsAny: "contains_any",
+	GeoBox:      "geo_box",
+	Raw:         "raw",
+	All:         "all",
+	None:        "none",
+	ILike:       "ilike",
+	NotLike:     "not_like",
+	IsEmpty:     "is_empty",
+	IsNotEmpty:  "is_not_empty",
+	Len:         "len",
+	Xor:         "xor",
+}
+
+// String returns the string representation of the operator, or "unknown"
+// for values outside the declared operators.
 func (o Op) String() string {
-	switch o {
-	case Eq:
-		return "eq"
-	case Ne:
-		return "ne"
-	case Gt:
-		return "gt"
-	case Gte:
-		return "gte"
-	case Lt:
-		return "lt"
-	case Lte:
-		return "lte"
-	case In:
-		return "in"
-	case Nin:
-		return "nin"
-	case Like:
-		return "like"
-	case Contains:
-		return "contains"
-	case And:
-		return "and"
-	case Or:
-		return "or"
-	case Not:
-		return "not"
-	default:
+	if o >= numOps {
 		return "unknown"
 	}
+	return opNames[o]
 }
 
 // Filter represents a filter condition or logical group.
@@ -76,9 +114,14 @@ func (o Op) String() string {
 type Filter struct {
 	op       Op
 	field    string
+	key      string // Key within the KindMap field when field is "field.key"
 	value    any
 	children []*Filter
-	err      error // Deferred error for invalid field
+	kind     FieldKind      // Kind of the field, for kind-aware compilers
+	regex    *regexp.Regexp // Compiled pattern for Regex
+	fold     bool           // Compare strings case-insensitively (Eq, Ne, In, Nin)
+	absent   bool           // All standing for a condition skipped by WhereIf
+	err      error          // Deferred error for invalid field
 }
 
 // Op returns the filter operator.
@@ -92,6 +135,16 @@ func (f *Filter) Field() string {
 	return f.field
 }
 
+// entry splits the field of a condition on a KindMap entry into the map
+// field and the key, e.g. "attributes.color" into "attributes" and "color".
+// ok is false for conditions on plain fields.
+func (f *Filter) entry() (field, key string, ok bool) {
+	if f.key == "" || !strings.HasSuffix(f.field, "."+f.key) {
+		return "", "", false
+	}
+	return f.field[:len(f.field)-len(f.key)-1], f.key, true
+}
+
 // Value returns the comparison value for field conditions.
 // Returns nil for logical operators (And, Or).
 func (f *Filter) Value() any {
@@ -104,22 +157,214 @@ func (f *Filter) Children() []*Filter {
 	return f.children
 }
 
+// And returns a filter matching when f and all of others match. If f is
+// already an And, others are appended to a copy of its children rather than
+// nesting it. f is not modified.
+func (f *Filter) And(others ...*Filter) *Filter {
+	return f.combine(And, others)
+}
+
+// Or returns a filter matching when f or any of others match. If f is
+// already an Or, others are appended to a copy of its children rather than
+// nesting it. f is not modified.
+func (f *Filter) Or(others ...*Filter) *Filter {
+	return f.combine(Or, others)
+}
+
+// Not returns a filter matching when f does not match.
+func (f *Filter) Not() *Filter {
+	return &Filter{op: Not, children: []*Filter{f}}
+}
+
+// combine joins f and others under op, flattening f if it is already op.
+func (f *Filter) combine(op Op, others []*Filter) *Filter {
+	if f != nil && f.op == op && f.err == nil {
+		return group(op, slices.Concat(f.children, others))
+	}
+	return group(op, append([]*Filter{f}, others...))
+}
+
+// group joins filters under And or Or. Conditions skipped by WhereIf are
+// omitted from both; if every filter is skipped, the result is a skipped
+// All as well. Otherwise an And omits All, which cannot narrow it, and an
+// Or containing All is All, unless a sibling carries an error that Err
+// must still report.
+func group(op Op, filters []*Filter) *Filter {
+	children := filters
+	if slices.ContainsFunc(filters, isAbsent) {
+		children = slices.DeleteFunc(slices.Clone(filters), isAbsent)
+		if len(children) == 0 {
+			return &Filter{op: All, absent: true}
+		}
+	}
+	if slices.ContainsFunc(children, isAll) {
+		if op == Or {
+			if !slices.ContainsFunc(children, invalid) {
+				return &Filter{op: All}
+			}
+			return &Filter{op: op, children: children}
+		}
+		children = slices.DeleteFunc(slices.Clone(children), isAll)
+		if len(children) == 0 {
+			return &Filter{op: All}
+		}
+	}
+	return &Filter{op: op, children: children}
+}
+
+// expandXor rewrites an Xor of a and b as (a OR b) AND NOT (a AND b), for
+// compilers without a native exclusive or.
+func expandXor(f *Filter) (*Filter, error) {
+	if len(f.children) != 2 {
+		return nil, fmt.Errorf("%w: %s requires exactly two children", ErrInvalidFilter, f.op)
+	}
+	a, b := f.children[0], f.children[1]
+	return &Filter{op: And, children: []*Filter{
+		{op: Or, children: []*Filter{a, b}},
+		{op: Not, children: []*Filter{{op: And, children: []*Filter{a, b}}}},
+	}}, nil
+}
+
+// isAll reports whether f is a valid All filter.
+func isAll(f *Filter) bool {
+	return f != nil && f.op == All && f.err == nil
+}
+
+// isAbsent reports whether f is the All filter yielded by a condition
+// skipped by WhereIf.
+func isAbsent(f *Filter) bool {
+	return isAll(f) && f.absent
+}
+
+// invalid reports whether f is nil or carries an error anywhere in its tree.
+func invalid(f *Filter) bool {
+	return f == nil || f.Err() != nil
+}
+
+// isNone reports whether f is a valid None filter.
+func isNone(f *Filter) bool {
+	return f != nil && f.op == None && f.err == nil
+}
+
+// CaseInsensitive reports whether the filter compares strings without
+// regard to case, for fields registered with WithCaseInsensitive.
+func (f *Filter) CaseInsensitive() bool {
+	return f.fold
+}
+
 // Err returns any error that occurred during filter construction.
 // This enables deferred error checking after building complex filters.
+// The tree is walked depth-first with an explicit stack, so arbitrarily
+// deep filters cannot overflow the call stack.
 func (f *Filter) Err() error {
+	var buf [16]*Filter
+	stack := append(buf[:0], f)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n == nil {
+			continue
+		}
+		if n.err != nil {
+			return n.err
+		}
+		// Push children in reverse so the first is checked first
+		for i := len(n.children) - 1; i >= 0; i-- {
+			stack = append(stack, n.children[i])
+		}
+	}
+	return nil
+}
+
+// Errors returns every construction error in the filter tree, in depth-first
+// order, unlike Err which stops at the first. Errors from nested nodes are
+// prefixed with their position, e.g. "children[1].children[0]: ...", and
+// still satisfy errors.Is for the sentinel errors. Returns nil if the
+// filter is valid.
+func (f *Filter) Errors() []error {
+	var errs []error
+	collectErrors(f, "", &errs)
+	return errs
+}
+
+// ErrPath returns the first construction error in the filter tree, as Err
+// does, together with a JSON-pointer-style path to the node that produced
+// it, e.g. "/children/1/children/0". The path is empty when the error is on
+// the root, and both results are zero if the filter is valid. The path
+// indexes children as they appear in ToSpec output, so API layers can
+// report which part of a submitted spec was rejected.
+func (f *Filter) ErrPath() (error, string) { //nolint:revive,staticcheck // error paired with its location
 	if f == nil {
-		return nil
+		return nil, ""
 	}
 	if f.err != nil {
-		return f.err
+		return f.err, ""
 	}
-	// Check children for errors
-	for _, child := range f.children {
-		if err := child.Err(); err != nil {
-			return err
+	for i, child := range f.children {
+		if err, path := child.ErrPath(); err != nil {
+			return err, "/children/" + strconv.Itoa(i) + path
 		}
 	}
-	return nil
+	return nil, ""
+}
+
+// Depth returns the maximum nesting depth of the filter tree: 1 for a single
+// condition, plus one for each enclosing And, Or, or Not. Returns 0 for nil.
+func (f *Filter) Depth() int {
+	if f == nil {
+		return 0
+	}
+	depth := 0
+	for _, child := range f.children {
+		depth = max(depth, child.Depth())
+	}
+	return depth + 1
+}
+
+// collectErrors appends the errors of f and its descendants to errs.
+func collectErrors(f *Filter, path string, errs *[]error) {
+	if f == nil {
+		return
+	}
+	if f.err != nil {
+		*errs = append(*errs, withPath(path, f.err))
+	}
+	for i, child := range f.children {
+		collectErrors(child, childPath(path, i), errs)
+	}
+}
+
+// pathError annotates an error with the position of the node that produced it.
+type pathError struct {
+	path string
+	err  error
+}
+
+// Error returns the path-prefixed message.
+func (e *pathError) Error() string {
+	return e.path + ": " + e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *pathError) Unwrap() error {
+	return e.err
+}
+
+// withPath wraps err with path unless path is empty or err already carries one.
+func withPath(path string, err error) error {
+	var pe *pathError
+	if path == "" || errors.As(err, &pe) {
+		return err
+	}
+	return &pathError{path: path, err: err}
+}
+
+// joinPath appends a path segment.
+func joinPath(path, segment string) string {
+	if path == "" {
+		return segment
+	}
+	return path + "." + segment
 }
 
 // FieldKind categorizes field types for validation.
@@ -133,31 +378,69 @@ const (
 	KindBool
 	KindSlice
 	KindUnknown
+	KindTime
+	KindUint
+	KindMap // Map with string keys; filter its entries as "field.key"
+
+	numKinds // Number of field kinds; keep last
 )
 
-// String returns the string representation of the field kind.
+// kindNames holds the string form of each field kind, indexed by FieldKind.
+var kindNames = [numKinds]string{
+	KindString:  "string",
+	KindInt:     "int",
+	KindFloat:   "float",
+	KindBool:    "bool",
+	KindSlice:   "slice",
+	KindUnknown: "unknown",
+	KindTime:    "time",
+	KindUint:    "uint",
+	KindMap:     "map",
+}
+
+// String returns the string representation of the field kind, or
+// "unknown" for KindUnknown and values outside the declared kinds.
 func (k FieldKind) String() string {
+	if k >= numKinds {
+		return "unknown"
+	}
+	return kindNames[k]
+}
+
+// ValidOps returns the field operators accepted on fields of kind k, in Op
+// order. It is the single source of truth for operator validation, so UIs
+// can offer exactly the operators Where will accept. GeoBox additionally
+// requires its longitude field to be KindFloat; fields whose type
+// implements Comparable also accept the ordering operators. KindMap fields
+// accept none themselves; their entries take the operators of the value kind.
+func (k FieldKind) ValidOps() []Op {
+	if k == KindMap {
+		return nil
+	}
+	ops := []Op{Eq, Ne, In, Nin}
 	switch k {
 	case KindString:
-		return "string"
-	case KindInt:
-		return "int"
+		ops = append(ops, Like, Regex, Prefix, Suffix, ILike, NotLike)
+	case KindInt, KindUint:
+		ops = append(ops, Gt, Gte, Lt, Lte, Between, Approx)
 	case KindFloat:
-		return "float"
-	case KindBool:
-		return "bool"
+		ops = append(ops, Gt, Gte, Lt, Lte, Between, Approx, GeoBox)
+	case KindTime:
+		ops = append(ops, Gt, Gte, Lt, Lte, Between)
 	case KindSlice:
-		return "slice"
-	default:
-		return "unknown"
+		ops = append(ops, Contains, ContainsAll, ContainsAny, IsEmpty, IsNotEmpty, Len)
 	}
+	slices.Sort(ops)
+	return ops
 }
 
 // FieldSpec describes a single filterable field.
 type FieldSpec struct {
-	Name   string    // JSON field name (from tag or Go name)
-	GoName string    // Original Go field name
-	Kind   FieldKind // Type category
+	Name      string    // JSON field name (from tag or Go name)
+	GoName    string    // Original Go field name
+	Kind      FieldKind // Type category
+	ValueKind FieldKind // Kind of the values of a KindMap field; unused otherwise
+	ID        int       // Stable index of Name among the sorted field names
 }
 
 // Spec describes the metadata schema extracted from T.
@@ -166,6 +449,17 @@ type Spec struct {
 	Fields   []FieldSpec // Filterable fields
 }
 
+// Operators returns the operators valid for the named field, as given by
+// FieldKind.ValidOps for its kind. Returns ErrFieldNotFound for unknown
+// fields.
+func (s *Spec) Operators(field string) ([]Op, error) {
+	f := s.Field(field)
+	if f == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFieldNotFound, field)
+	}
+	return f.Kind.ValidOps(), nil
+}
+
 // Field returns the FieldSpec for the given field name, or nil if not found.
 func (s *Spec) Field(name string) *FieldSpec {
 	for i := range s.Fields {
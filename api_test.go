@@ -2,7 +2,10 @@ package vecna
 
 import (
 	"errors"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestOp_String(t *testing.T) {
@@ -23,6 +26,23 @@ func TestOp_String(t *testing.T) {
 		{And, "and"},
 		{Or, "or"},
 		{Not, "not"},
+		{Between, "between"},
+		{Regex, "regex"},
+		{Prefix, "prefix"},
+		{Suffix, "suffix"},
+		{Approx, "approx"},
+		{ContainsAll, "contains_all"},
+		{ContainsAny, "contains_any"},
+		{GeoBox, "geo_box"},
+		{Raw, "raw"},
+		{All, "all"},
+		{None, "none"},
+		{ILike, "ilike"},
+		{NotLike, "not_like"},
+		{IsEmpty, "is_empty"},
+		{IsNotEmpty, "is_not_empty"},
+		{Len, "len"},
+		{Xor, "xor"},
 		{Op(99), "unknown"},
 	}
 
@@ -46,6 +66,9 @@ func TestFieldKind_String(t *testing.T) {
 		{KindBool, "bool"},
 		{KindSlice, "slice"},
 		{KindUnknown, "unknown"},
+		{KindTime, "time"},
+		{KindUint, "uint"},
+		{KindMap, "map"},
 		{FieldKind(99), "unknown"},
 	}
 
@@ -58,6 +81,129 @@ func TestFieldKind_String(t *testing.T) {
 	}
 }
 
+func TestOp_String_Declared(t *testing.T) {
+	seen := make(map[string]Op)
+	for op := Op(0); op < numOps; op++ {
+		name := op.String()
+		if name == "" || name == "unknown" {
+			t.Errorf("Op(%d).String() = %q, want a name", op, name)
+			continue
+		}
+		if prev, ok := seen[name]; ok {
+			t.Errorf("Op(%d).String() = %q, same as Op(%d)", op, name, prev)
+		}
+		seen[name] = op
+	}
+	if got := numOps.String(); got != "unknown" {
+		t.Errorf("numOps.String() = %q, want unknown", got)
+	}
+
+	for k := FieldKind(0); k < numKinds; k++ {
+		if name := k.String(); name == "" || (name == "unknown") != (k == KindUnknown) {
+			t.Errorf("FieldKind(%d).String() = %q", k, name)
+		}
+	}
+}
+
+func TestFieldKind_ValidOps(t *testing.T) {
+	tests := []struct {
+		kind FieldKind
+		want []Op
+	}{
+		{KindString, []Op{Eq, Ne, In, Nin, Like, Regex, Prefix, Suffix, ILike, NotLike}},
+		{KindInt, []Op{Eq, Ne, Gt, Gte, Lt, Lte, In, Nin, Between, Approx}},
+		{KindUint, []Op{Eq, Ne, Gt, Gte, Lt, Lte, In, Nin, Between, Approx}},
+		{KindFloat, []Op{Eq, Ne, Gt, Gte, Lt, Lte, In, Nin, Between, Approx, GeoBox}},
+		{KindBool, []Op{Eq, Ne, In, Nin}},
+		{KindSlice, []Op{Eq, Ne, In, Nin, Contains, ContainsAll, ContainsAny, IsEmpty, IsNotEmpty, Len}},
+		{KindTime, []Op{Eq, Ne, Gt, Gte, Lt, Lte, In, Nin, Between}},
+		{KindUnknown, []Op{Eq, Ne, In, Nin}},
+		{KindMap, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind.String(), func(t *testing.T) {
+			if got := tt.kind.ValidOps(); !slices.Equal(got, tt.want) {
+				t.Errorf("FieldKind.ValidOps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFieldKind_ValidOps_Validation checks that Where accepts exactly the
+// operators ValidOps advertises for each field kind.
+func TestFieldKind_ValidOps_Validation(t *testing.T) {
+	type allKinds struct {
+		Str   string    `json:"str"`
+		Int   int       `json:"int"`
+		Uint  uint      `json:"uint"`
+		Float float64   `json:"float"`
+		Bool  bool      `json:"bool"`
+		Tags  []string  `json:"tags"`
+		At    time.Time `json:"at"`
+		Any   any       `json:"any"`
+	}
+	builder, _ := New[allKinds]()
+	values := map[string]any{
+		"str": "a", "int": 1, "uint": 1, "float": 1.5, "bool": true,
+		"tags": "a", "at": time.Unix(0, 0), "any": "a",
+	}
+	fieldOps := []Op{Eq, Ne, Gt, Gte, Lt, Lte, In, Nin, Like, Contains, Between, Regex, Prefix, Suffix, Approx, ContainsAll, ContainsAny, ILike, NotLike, IsEmpty, IsNotEmpty, Len}
+
+	spec := builder.Spec()
+	for _, field := range spec.Fields {
+		v := values[field.Name]
+		for _, op := range fieldOps {
+			fb := builder.Where(field.Name)
+			var f *Filter
+			switch op {
+			case In:
+				f = fb.In(v)
+			case Nin:
+				f = fb.Nin(v)
+			case ContainsAll:
+				f = fb.ContainsAll(v)
+			case ContainsAny:
+				f = fb.ContainsAny(v)
+			case Between:
+				f = fb.Between(v, v)
+			case Approx:
+				f = fb.Approx(1, 0.5)
+			case Like, ILike, NotLike, Regex, Prefix, Suffix:
+				f = fb.makeFilter(op, "a")
+			case IsEmpty, IsNotEmpty:
+				f = fb.makeFilter(op, nil)
+			case Len:
+				f = fb.Len(Gte, 1)
+			default:
+				f = fb.makeFilter(op, v)
+			}
+
+			rejected := f.Err() != nil && strings.Contains(f.Err().Error(), "not valid for")
+			if valid := slices.Contains(field.Kind.ValidOps(), op); rejected == valid {
+				t.Errorf("Where(%s).%s() error = %v, but ValidOps() contains it = %v", field.Name, op, f.Err(), valid)
+			}
+		}
+	}
+}
+
+func TestSpec_Operators(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	spec := builder.Spec()
+
+	ops, err := spec.Operators("tags")
+	if err != nil {
+		t.Fatalf("Spec.Operators() error = %v", err)
+	}
+	if want := KindSlice.ValidOps(); !slices.Equal(ops, want) {
+		t.Errorf("Spec.Operators(tags) = %v, want %v", ops, want)
+	}
+
+	if _, err := spec.Operators("missing"); !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("Spec.Operators(missing) error = %v, want %v", err, ErrFieldNotFound)
+	}
+}
+
 func TestFilter_Accessors(t *testing.T) {
 	f := &Filter{
 		op:    Eq,
@@ -124,6 +270,31 @@ func TestFilter_Err(t *testing.T) {
 			t.Errorf("Filter.Err() = %v, want %v", f.Err(), ErrFieldNotFound)
 		}
 	})
+
+	t.Run("first error in depth-first order", func(t *testing.T) {
+		first := &Filter{err: ErrFieldNotFound}
+		second := &Filter{err: ErrInvalidFilter}
+		f := &Filter{
+			op: Or,
+			children: []*Filter{
+				{op: And, children: []*Filter{{op: Eq}, {op: Not, children: []*Filter{first}}}},
+				second,
+			},
+		}
+		if err := f.Err(); err != first.err {
+			t.Errorf("Filter.Err() = %v, want %v", err, first.err)
+		}
+	})
+
+	t.Run("deeply nested", func(t *testing.T) {
+		f := &Filter{op: Eq, err: ErrFieldNotFound}
+		for range 1_000_000 {
+			f = &Filter{op: Not, children: []*Filter{f}}
+		}
+		if !errors.Is(f.Err(), ErrFieldNotFound) {
+			t.Errorf("Filter.Err() = %v, want %v", f.Err(), ErrFieldNotFound)
+		}
+	})
 }
 
 func TestSpec_Field(t *testing.T) {
@@ -152,3 +323,193 @@ func TestSpec_Field(t *testing.T) {
 		}
 	})
 }
+
+func TestOp_StringRoundTrip(t *testing.T) {
+	ops := []Op{Eq, Ne, Gt, Gte, Lt, Lte, In, Nin, Like, Contains, And, Or, Not, Between, Regex, Prefix, Suffix, Approx, ContainsAll, ContainsAny, GeoBox, Raw, All, None, ILike, NotLike, IsEmpty, IsNotEmpty, Len}
+
+	for _, op := range ops {
+		t.Run(op.String(), func(t *testing.T) {
+			got, err := parseOp(op.String())
+			if err != nil {
+				t.Fatalf("parseOp(%q) error = %v", op.String(), err)
+			}
+			if got != op {
+				t.Errorf("parseOp(%q) = %v, want %v", op.String(), got, op)
+			}
+		})
+	}
+}
+
+func TestFilter_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	t.Run("valid", func(t *testing.T) {
+		f := builder.And(builder.Where("category").Eq("tech"), builder.Where("score").Gt(0.5))
+		if errs := f.Errors(); errs != nil {
+			t.Errorf("Filter.Errors() = %v, want nil", errs)
+		}
+	})
+
+	t.Run("nil filter", func(t *testing.T) {
+		var f *Filter
+		if errs := f.Errors(); errs != nil {
+			t.Errorf("nil Filter.Errors() = %v, want nil", errs)
+		}
+	})
+
+	t.Run("all errors", func(t *testing.T) {
+		f := builder.And(
+			builder.Where("missing").Eq("x"),
+			builder.Where("category").Eq("tech"),
+			builder.Or(
+				builder.Where("category").Gt(1),
+				builder.Not(builder.Where("unknown").Eq(1)),
+			),
+		)
+
+		errs := f.Errors()
+		if len(errs) != 3 {
+			t.Fatalf("len(Filter.Errors()) = %d, want 3: %v", len(errs), errs)
+		}
+
+		wants := []struct {
+			sentinel error
+			message  string
+		}{
+			{ErrFieldNotFound, "children[0]: vecna: field not found: missing"},
+			{ErrInvalidFilter, "children[2].children[0]: vecna: invalid filter: operator gt not valid for string field category"},
+			{ErrFieldNotFound, "children[2].children[1].children[0]: vecna: field not found: unknown"},
+		}
+		for i, want := range wants {
+			if !errors.Is(errs[i], want.sentinel) {
+				t.Errorf("Errors()[%d] = %v, want %v", i, errs[i], want.sentinel)
+			}
+			if errs[i].Error() != want.message {
+				t.Errorf("Errors()[%d] = %q, want %q", i, errs[i].Error(), want.message)
+			}
+		}
+
+		// Err is unchanged and still returns the first error
+		if !errors.Is(f.Err(), ErrFieldNotFound) {
+			t.Errorf("Filter.Err() = %v, want %v", f.Err(), ErrFieldNotFound)
+		}
+	})
+}
+
+func TestFilter_ErrPath(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	valid := builder.Where("category").Eq("tech")
+	missing := builder.Where("missing").Eq("x")
+
+	tests := []struct {
+		name     string
+		filter   *Filter
+		wantErr  error
+		wantPath string
+	}{
+		{"nil", nil, nil, ""},
+		{"valid", builder.And(valid, builder.Where("score").Gt(0.5)), nil, ""},
+		{"root", missing, ErrFieldNotFound, ""},
+		{"child", builder.Or(valid, missing), ErrFieldNotFound, "/children/1"},
+		{"nested", builder.And(valid, builder.Or(builder.Where("category").Gt(1), missing)), ErrInvalidFilter, "/children/1/children/0"},
+		{"under not", builder.And(valid, valid, builder.Not(missing)), ErrFieldNotFound, "/children/2/children/0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err, path := tt.filter.ErrPath()
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("ErrPath() error = %v, want %v", err, tt.wantErr)
+			}
+			if path != tt.wantPath {
+				t.Errorf("ErrPath() path = %q, want %q", path, tt.wantPath)
+			}
+			if !errors.Is(tt.filter.Err(), err) {
+				t.Errorf("ErrPath() error = %v, want Err() = %v", err, tt.filter.Err())
+			}
+		})
+	}
+}
+
+func TestFilter_Depth(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   int
+	}{
+		{"nil", nil, 0},
+		{"leaf", builder.Where("category").Eq("tech"), 1},
+		{"not", builder.Not(builder.Where("active").Eq(true)), 2},
+		{
+			"uneven",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Or(
+					builder.Where("score").Gte(0.5),
+					builder.Not(builder.Where("count").Lt(3)),
+				),
+			),
+			4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Depth(); got != tt.want {
+				t.Errorf("Depth() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_Combinators(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	a := builder.Where("category").Eq("a")
+	b := builder.Where("count").Eq(1)
+	c := builder.Where("active").Eq(true)
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"and", a.And(b), `(category == "a" AND count == 1)`},
+		{"and flattens", a.And(b).And(c), `(category == "a" AND count == 1 AND active == true)`},
+		{"or", a.Or(b, c), `(category == "a" OR count == 1 OR active == true)`},
+		{"or flattens", a.Or(b).Or(c), `(category == "a" OR count == 1 OR active == true)`},
+		{"mixed nests", a.And(b).Or(c), `((category == "a" AND count == 1) OR active == true)`},
+		{"not", a.Not(), `NOT (category == "a")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.String(); got != tt.want {
+				t.Errorf("Filter = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("original untouched", func(t *testing.T) {
+		ab := a.And(b)
+		abc := ab.And(c)
+		abd := ab.And(builder.Where("score").Gt(0.5))
+		if len(ab.Children()) != 2 {
+			t.Errorf("original children = %d, want 2", len(ab.Children()))
+		}
+		if abc.Children()[2] != c || abd.Children()[2] == c {
+			t.Error("combined filters should not share appended children")
+		}
+	})
+
+	t.Run("propagates errors", func(t *testing.T) {
+		invalid := builder.Where("missing").Eq("x")
+		if err := a.And(invalid).Err(); !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("And().Err() = %v, want %v", err, ErrFieldNotFound)
+		}
+		if err := invalid.Not().Err(); !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("Not().Err() = %v, want %v", err, ErrFieldNotFound)
+		}
+	})
+}
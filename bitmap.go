@@ -0,0 +1,180 @@
+package vecna
+
+import "fmt"
+
+// BitmapOp is the kind of a BitmapPlan node.
+type BitmapOp uint8
+
+// Bitmap plan operations.
+const (
+	BitmapAll      BitmapOp = iota // Every row (the universe bitmap)
+	BitmapEq                       // Rows whose field index holds any of Values
+	BitmapRange                    // Rows whose field lies within Low/High
+	BitmapAnd                      // Intersection of Children
+	BitmapOr                       // Union of Children
+	BitmapAndNot                   // Rows of Children[0] not in Children[1]
+	BitmapResidual                 // Rows that must be checked against Filter
+)
+
+// String returns the string representation of the bitmap operation.
+func (o BitmapOp) String() string {
+	switch o {
+	case BitmapAll:
+		return "all"
+	case BitmapEq:
+		return "eq"
+	case BitmapRange:
+		return "range"
+	case BitmapAnd:
+		return "and"
+	case BitmapOr:
+		return "or"
+	case BitmapAndNot:
+		return "andnot"
+	case BitmapResidual:
+		return "residual"
+	default:
+		return "unknown"
+	}
+}
+
+// BitmapPlan is a tree of set operations over named field indexes, for
+// stores that execute filters against bitmap indexes (e.g. roaring bitmaps).
+// Leaves reference a field index; inner nodes combine their children.
+type BitmapPlan struct {
+	Op            BitmapOp
+	Field         string       // Index name (Eq and Range leaves)
+	Values        []any        // Values to look up (Eq leaves)
+	Low, High     any          // Range bounds; nil means unbounded (Range leaves)
+	LowInclusive  bool         // Whether Low itself matches
+	HighInclusive bool         // Whether High itself matches
+	Children      []BitmapPlan // Operands (And, Or, AndNot)
+	Filter        *Filter      // Predicate to evaluate per row (Residual leaves)
+}
+
+// HasResidual reports whether any node in the plan is a residual predicate,
+// meaning candidate rows must be rechecked, e.g. with Builder.Match.
+func (p BitmapPlan) HasResidual() bool {
+	if p.Op == BitmapResidual {
+		return true
+	}
+	for _, child := range p.Children {
+		if child.HasResidual() {
+			return true
+		}
+	}
+	return false
+}
+
+// ToBitmapPlan translates a filter into bitmap set operations. Eq and In
+// become index lookups; Gt, Gte, Lt, Lte, Between, and Approx become range
+// scans; Contains, ContainsAny, and ContainsAll look up elements of a
+// multi-valued index. Ne, Nin, and Not subtract from the universe with
+// AndNot. Operators an index cannot answer (Like, Regex, Prefix, Suffix,
+// GeoBox, Raw, equality on slice or unknown fields, and case-insensitive
+// comparisons) become Residual leaves carrying the original filter, to be
+// evaluated row by row.
+func (b *Builder[T]) ToBitmapPlan(f *Filter) (BitmapPlan, error) {
+	if err := checkCompilable(f); err != nil {
+		return BitmapPlan{}, err
+	}
+	return bitmapPlan(f)
+}
+
+// bitmapPlan translates a single filter node.
+func bitmapPlan(f *Filter) (BitmapPlan, error) {
+	switch f.op {
+	case And, Or:
+		if len(f.children) == 0 {
+			return BitmapPlan{}, fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		plan := BitmapPlan{Op: BitmapAnd, Children: make([]BitmapPlan, len(f.children))}
+		if f.op == Or {
+			plan.Op = BitmapOr
+		}
+		for i, child := range f.children {
+			var err error
+			if plan.Children[i], err = bitmapPlan(child); err != nil {
+				return BitmapPlan{}, err
+			}
+		}
+		return plan, nil
+	case Not:
+		if len(f.children) != 1 {
+			return BitmapPlan{}, fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := bitmapPlan(f.children[0])
+		if err != nil {
+			return BitmapPlan{}, err
+		}
+		return complement(inner), nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return BitmapPlan{}, err
+		}
+		return bitmapPlan(expanded)
+	}
+
+	switch f.op {
+	case All:
+		return BitmapPlan{Op: BitmapAll}, nil
+	case None:
+		return complement(BitmapPlan{Op: BitmapAll}), nil
+	case Eq, Ne:
+		if f.kind == KindSlice || f.kind == KindUnknown || f.fold {
+			return BitmapPlan{Op: BitmapResidual, Filter: f}, nil
+		}
+		lookup := BitmapPlan{Op: BitmapEq, Field: f.field, Values: []any{f.value}}
+		if f.op == Ne {
+			return complement(lookup), nil
+		}
+		return lookup, nil
+	case In, Nin, ContainsAny:
+		if f.fold {
+			return BitmapPlan{Op: BitmapResidual, Filter: f}, nil
+		}
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return BitmapPlan{}, err
+		}
+		lookup := BitmapPlan{Op: BitmapEq, Field: f.field, Values: values}
+		if f.op == Nin {
+			return complement(lookup), nil
+		}
+		return lookup, nil
+	case Contains:
+		return BitmapPlan{Op: BitmapEq, Field: f.field, Values: []any{f.value}}, nil
+	case ContainsAll:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return BitmapPlan{}, err
+		}
+		plan := BitmapPlan{Op: BitmapAnd, Children: make([]BitmapPlan, len(values))}
+		for i, v := range values {
+			plan.Children[i] = BitmapPlan{Op: BitmapEq, Field: f.field, Values: []any{v}}
+		}
+		return plan, nil
+	case Gt:
+		return BitmapPlan{Op: BitmapRange, Field: f.field, Low: f.value}, nil
+	case Gte:
+		return BitmapPlan{Op: BitmapRange, Field: f.field, Low: f.value, LowInclusive: true}, nil
+	case Lt:
+		return BitmapPlan{Op: BitmapRange, Field: f.field, High: f.value}, nil
+	case Lte:
+		return BitmapPlan{Op: BitmapRange, Field: f.field, High: f.value, HighInclusive: true}, nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return BitmapPlan{}, err
+		}
+		return BitmapPlan{Op: BitmapRange, Field: f.field, Low: low, High: high, LowInclusive: true, HighInclusive: true}, nil
+	default:
+		return BitmapPlan{Op: BitmapResidual, Filter: f}, nil
+	}
+}
+
+// complement returns the plan for every row not matched by plan.
+func complement(plan BitmapPlan) BitmapPlan {
+	return BitmapPlan{Op: BitmapAndNot, Children: []BitmapPlan{{Op: BitmapAll}, plan}}
+}
@@ -0,0 +1,147 @@
+package vecna
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBuilder_ToBitmapPlan(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	all := BitmapPlan{Op: BitmapAll}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   BitmapPlan
+	}{
+		{
+			"eq",
+			builder.Where("category").Eq("tech"),
+			BitmapPlan{Op: BitmapEq, Field: "category", Values: []any{"tech"}},
+		},
+		{
+			"in",
+			builder.Where("category").In("a", "b"),
+			BitmapPlan{Op: BitmapEq, Field: "category", Values: []any{"a", "b"}},
+		},
+		{
+			"ne",
+			builder.Where("category").Ne("tech"),
+			BitmapPlan{Op: BitmapAndNot, Children: []BitmapPlan{all, {Op: BitmapEq, Field: "category", Values: []any{"tech"}}}},
+		},
+		{
+			"gte",
+			builder.Where("score").Gte(0.5),
+			BitmapPlan{Op: BitmapRange, Field: "score", Low: 0.5, LowInclusive: true},
+		},
+		{
+			"lt",
+			builder.Where("count").Lt(10),
+			BitmapPlan{Op: BitmapRange, Field: "count", High: 10},
+		},
+		{
+			"between",
+			builder.Where("count").Between(1, 5),
+			BitmapPlan{Op: BitmapRange, Field: "count", Low: 1, High: 5, LowInclusive: true, HighInclusive: true},
+		},
+		{
+			"contains all",
+			builder.Where("tags").ContainsAll("go", "db"),
+			BitmapPlan{Op: BitmapAnd, Children: []BitmapPlan{
+				{Op: BitmapEq, Field: "tags", Values: []any{"go"}},
+				{Op: BitmapEq, Field: "tags", Values: []any{"db"}},
+			}},
+		},
+		{
+			"logical",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Or(builder.Where("score").Gt(0.5), builder.Not(builder.Where("active").Eq(true))),
+			),
+			BitmapPlan{Op: BitmapAnd, Children: []BitmapPlan{
+				{Op: BitmapEq, Field: "category", Values: []any{"tech"}},
+				{Op: BitmapOr, Children: []BitmapPlan{
+					{Op: BitmapRange, Field: "score", Low: 0.5},
+					{Op: BitmapAndNot, Children: []BitmapPlan{all, {Op: BitmapEq, Field: "active", Values: []any{true}}}},
+				}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.ToBitmapPlan(tt.filter)
+			if err != nil {
+				t.Fatalf("ToBitmapPlan() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToBitmapPlan() = %+v, want %+v", got, tt.want)
+			}
+			if got.HasResidual() {
+				t.Error("BitmapPlan.HasResidual() = true, want false")
+			}
+		})
+	}
+}
+
+func TestBuilder_ToBitmapPlan_Residual(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	like := builder.Where("category").Like("te%")
+
+	plan, err := builder.ToBitmapPlan(builder.And(builder.Where("active").Eq(true), like))
+	if err != nil {
+		t.Fatalf("ToBitmapPlan() error = %v", err)
+	}
+	if !plan.HasResidual() {
+		t.Error("BitmapPlan.HasResidual() = false, want true")
+	}
+	residual := plan.Children[1]
+	if residual.Op != BitmapResidual || residual.Filter != like {
+		t.Errorf("BitmapPlan.Children[1] = %+v, want residual carrying the Like filter", residual)
+	}
+}
+
+func TestBuilder_ToBitmapPlan_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   error
+	}{
+		{"filter error", builder.Where("missing").Eq("x"), ErrFieldNotFound},
+		{"nil filter", nil, ErrInvalidFilter},
+		{"empty group", builder.Or(), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := builder.ToBitmapPlan(tt.filter); !errors.Is(err, tt.want) {
+				t.Errorf("ToBitmapPlan() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBitmapOp_String(t *testing.T) {
+	tests := []struct {
+		op   BitmapOp
+		want string
+	}{
+		{BitmapAll, "all"},
+		{BitmapEq, "eq"},
+		{BitmapRange, "range"},
+		{BitmapAnd, "and"},
+		{BitmapOr, "or"},
+		{BitmapAndNot, "andnot"},
+		{BitmapResidual, "residual"},
+		{BitmapOp(99), "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.op.String(); got != tt.want {
+			t.Errorf("BitmapOp.String() = %v, want %v", got, tt.want)
+		}
+	}
+}
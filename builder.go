@@ -2,8 +2,14 @@ package vecna
 
 import (
 	"fmt"
+	"maps"
+	"math"
 	"reflect"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/zoobzio/sentinel"
 )
@@ -11,74 +17,349 @@ import (
 // Builder provides schema-validated filter construction for type T.
 // Create a Builder using New[T]().
 type Builder[T any] struct {
-	spec   Spec
-	fields map[string]*FieldSpec // field name -> spec for O(1) lookup
+	spec    Spec
+	fields  map[string]*FieldSpec  // field name -> spec for O(1) lookup
+	index   map[string][]int       // field name -> struct field index path
+	columns map[string]string      // field name -> SQL column override
+	parsers map[string]ValueParser // field name -> custom value parser
+	oneOf   map[string][]any       // field name -> the only values it may be compared with
+	ordered map[string]bool        // fields whose type implements Comparable
+	fold    map[string]bool        // string fields compared case-insensitively
+
+	allowEmptyIn    bool // accept empty In/Nin sets
+	maxDepth        int  // deepest spec FromSpec accepts; 0 for no limit
+	exampleSkipZero bool // FromExample skips listed fields holding zero values
+
+	base *Filter // ANDed with every filter built by FromSpec and FromExample; nil for none
 }
 
 // New creates a schema-validated Builder for metadata type T.
 // Uses sentinel to extract field metadata from T.
-// Field names are resolved from: json tag > Go field name.
-// Fields with json:"-" are excluded.
-func New[T any]() (*Builder[T], error) {
-	// Register json tag for extraction before inspection
-	sentinel.Tag("json")
+// Field names are resolved from: json tag > Go field name, or from the tags
+// given to WithTagPriority. Fields with json:"-" are excluded.
+// Options customize the builder; see Option.
+//
+// The extracted schema is cached per type and schema-shaping options, so
+// repeated calls for the same T are cheap. New is safe for concurrent use.
+func New[T any](opts ...Option) (*Builder[T], error) {
+	cfg := newConfig(opts)
+	t := reflect.TypeFor[T]()
+
+	s, err := loadSchema[T](t, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.failOnUnknown && len(s.unknown) > 0 {
+		return nil, unknownKindError(s.unknown)
+	}
+
+	b := &Builder[T]{
+		spec:    s.spec,
+		fields:  s.fields,
+		index:   s.index,
+		columns: cfg.columns,
+		parsers: cfg.parsers,
+		oneOf:   cfg.oneOf,
+		ordered: s.ordered,
+		fold:    cfg.foldFields,
+
+		allowEmptyIn:    cfg.allowEmptyIn,
+		maxDepth:        cfg.maxDepth,
+		exampleSkipZero: cfg.exampleSkipZero,
+	}
+	if err := b.setBase(cfg.baseFilter); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// setBase calls the WithBaseFilter function, if any, and stores its filter
+// as the builder's base.
+func (b *Builder[T]) setBase(baseFilter any) error {
+	if baseFilter == nil {
+		return nil
+	}
+	fn, ok := baseFilter.(func(*Builder[T]) *Filter)
+	if !ok {
+		return fmt.Errorf("%w: base filter is a %T, not a func(*Builder[%s]) *Filter", ErrInvalidFilter, baseFilter, reflect.TypeFor[T]())
+	}
+	base := fn(b)
+	if base == nil {
+		return fmt.Errorf("%w: base filter is nil", ErrInvalidFilter)
+	}
+	if err := base.Err(); err != nil {
+		return fmt.Errorf("base filter: %w", err)
+	}
+	b.base = base
+	return nil
+}
+
+// schema is the field layout New extracts from a type. Cached schemas are
+// shared by every builder with the same schemaKey and never modified.
+type schema struct {
+	spec    Spec
+	fields  map[string]*FieldSpec // field name -> spec, pointing into spec.Fields
+	index   map[string][]int      // field name -> struct field index path
+	ordered map[string]bool       // fields whose type implements Comparable
+	unknown []string              // KindUnknown fields, for WithFailOnUnknownKind
+}
+
+// schemaKey identifies a cached schema: the type and the options that
+// decide which fields it has and what they are called.
+type schemaKey struct {
+	t                 reflect.Type
+	tags              string // name tags in priority order, NUL-separated
+	optInTag          string
+	includeUnexported bool
+	excludeKinds      string // excluded kinds as sorted bytes
+}
+
+// schemaCache maps a schemaKey to its *schema.
+var schemaCache sync.Map
+
+// loadSchema returns the schema of T for cfg, extracting and caching it on
+// first use.
+func loadSchema[T any](t reflect.Type, cfg *config) (*schema, error) {
+	var kinds []byte
+	for kind, excluded := range cfg.excludeKinds {
+		if excluded {
+			kinds = append(kinds, byte(kind))
+		}
+	}
+	slices.Sort(kinds)
+	key := schemaKey{
+		t:                 t,
+		tags:              strings.Join(cfg.tags, "\x00"),
+		optInTag:          cfg.optInTag,
+		includeUnexported: cfg.includeUnexported,
+		excludeKinds:      string(kinds),
+	}
+
+	if cached, ok := schemaCache.Load(key); ok {
+		return cached.(*schema), nil
+	}
+	s, err := inspectSchema[T](t, cfg)
+	if err != nil {
+		return nil, err
+	}
+	cached, _ := schemaCache.LoadOrStore(key, s)
+	return cached.(*schema), nil
+}
+
+// inspectSchema extracts the schema of T for cfg.
+func inspectSchema[T any](t reflect.Type, cfg *config) (*schema, error) {
+	// Register name tags for extraction before inspection
+	for _, tag := range cfg.tags {
+		sentinel.Tag(tag)
+	}
 
 	metadata, err := sentinel.TryInspect[T]()
 	if err != nil {
 		return nil, ErrNotStruct
 	}
 
-	spec := Spec{
-		TypeName: metadata.TypeName,
-		Fields:   make([]FieldSpec, 0, len(metadata.Fields)),
+	// Sentinel caches metadata per type with the tags registered at first
+	// inspection, so re-read the name tags to honor this call's options
+	candidates := make([]sentinel.FieldMetadata, len(metadata.Fields))
+	for i, field := range metadata.Fields {
+		candidates[i] = withTags(t, field, cfg.tags)
+	}
+	if cfg.includeUnexported {
+		candidates = append(candidates, unexportedFields(t, cfg.tags)...)
 	}
 
-	fields := make(map[string]*FieldSpec)
+	s := &schema{
+		spec: Spec{
+			TypeName: metadata.TypeName,
+			Fields:   make([]FieldSpec, 0, len(candidates)),
+		},
+		fields:  make(map[string]*FieldSpec),
+		index:   make(map[string][]int),
+		ordered: make(map[string]bool),
+	}
 
-	for _, field := range metadata.Fields {
-		// Get field name from json tag or use Go name
-		name := resolveFieldName(field)
+	for _, field := range candidates {
+		// Get field name from the priority tags or use Go name
+		name := resolveFieldName(field, cfg.tags)
 		if name == "-" || name == "" {
 			continue // Skip excluded fields
 		}
+		if cfg.optInTag != "" && !hasTagOption(t, field.Index, cfg.optInTag, "filterable") {
+			continue // Skip fields not opted in
+		}
 
 		kind := resolveFieldKind(field.Kind, field.Type)
+		var valueKind FieldKind
+		if field.Kind == sentinel.KindMap {
+			kind, valueKind = resolveMapKind(field.ReflectType)
+		}
+		if cfg.excludeKinds[kind] {
+			continue // Skip fields of excluded kinds
+		}
+		if kind == KindUnknown {
+			s.unknown = append(s.unknown, field.Name+" ("+field.Type+")")
+		}
 
-		fieldSpec := FieldSpec{
-			Name:   name,
-			GoName: field.Name,
-			Kind:   kind,
+		s.spec.Fields = append(s.spec.Fields, FieldSpec{
+			Name:      name,
+			GoName:    field.Name,
+			Kind:      kind,
+			ValueKind: valueKind,
+		})
+		s.fields[name] = &s.spec.Fields[len(s.spec.Fields)-1]
+		s.index[name] = field.Index
+		if implementsComparable(field.ReflectType) {
+			s.ordered[name] = true
 		}
-		spec.Fields = append(spec.Fields, fieldSpec)
-		fields[name] = &spec.Fields[len(spec.Fields)-1]
 	}
 
-	return &Builder[T]{
-		spec:   spec,
-		fields: fields,
-	}, nil
+	assignFieldIDs(s.fields)
+	return s, nil
+}
+
+// withBase nests f under the builder's base filter, if it has one.
+func (b *Builder[T]) withBase(f *Filter) *Filter {
+	if b.base == nil {
+		return f
+	}
+	return &Filter{op: And, children: []*Filter{b.base, f}}
+}
+
+// assignFieldIDs numbers fields by their position in sorted name order, so
+// IDs depend only on the set of field names and are stable across runs.
+func assignFieldIDs(fields map[string]*FieldSpec) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for id, name := range names {
+		fields[name].ID = id
+	}
 }
 
-// resolveFieldName extracts the field name from json tag or falls back to Go name.
-func resolveFieldName(field sentinel.FieldMetadata) string {
-	if jsonTag, ok := field.Tags["json"]; ok {
-		// Parse json tag (format: "name,omitempty")
-		parts := strings.Split(jsonTag, ",")
-		if len(parts) > 0 && parts[0] != "" {
-			return parts[0]
+// unknownKindError reports fields that resolved to KindUnknown.
+func unknownKindError(fields []string) error {
+	return fmt.Errorf("%w: fields with unknown kind: %s", ErrInvalidSchema, strings.Join(fields, ", "))
+}
+
+// withTags returns a copy of field whose Tags hold the named struct tags as
+// declared on t, leaving sentinel's cached metadata untouched.
+func withTags(t reflect.Type, field sentinel.FieldMetadata, names []string) sentinel.FieldMetadata {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	tag := t.FieldByIndex(field.Index).Tag
+
+	tags := make(map[string]string, len(field.Tags)+len(names))
+	maps.Copy(tags, field.Tags)
+	for _, name := range names {
+		if value, ok := tag.Lookup(name); ok {
+			tags[name] = value
+		}
+	}
+	field.Tags = tags
+	return field
+}
+
+// hasTagOption reports whether the struct field at index of t has the given
+// option among the comma-separated values of tag, e.g. vecna:"filterable".
+func hasTagOption(t reflect.Type, index []int, tag, option string) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return slices.Contains(strings.Split(t.FieldByIndex(index).Tag.Get(tag), ","), option)
+}
+
+// unexportedFields returns metadata for unexported fields of t that carry one of
+// the name tags or a vecna tag. Sentinel skips unexported fields, so they are
+// extracted here in the same shape. The vecna tag exists because go vet rejects
+// json tags on unexported fields; it is used when no name tag is present.
+func unexportedFields(t reflect.Type, tags []string) []sentinel.FieldMetadata {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var fields []sentinel.FieldMetadata
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.IsExported() || field.Anonymous {
+			continue
+		}
+		found := make(map[string]string)
+		for _, name := range tags {
+			if tag := field.Tag.Get(name); tag != "" {
+				found[name] = tag
+			}
+		}
+		if len(found) == 0 && len(tags) > 0 {
+			if tag := field.Tag.Get("vecna"); tag != "" {
+				found[tags[0]] = tag
+			}
+		}
+		if len(found) == 0 {
+			continue // Only explicitly tagged fields opt in
+		}
+		fields = append(fields, sentinel.FieldMetadata{
+			Index:       field.Index,
+			Name:        field.Name,
+			Type:        field.Type.String(),
+			Kind:        sentinelKind(field.Type),
+			ReflectType: field.Type,
+			Tags:        found,
+		})
+	}
+	return fields
+}
+
+// sentinelKind categorizes a reflect.Type the same way sentinel does.
+func sentinelKind(t reflect.Type) sentinel.FieldKind {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return sentinel.KindPointer
+	case reflect.Slice, reflect.Array:
+		return sentinel.KindSlice
+	case reflect.Struct:
+		return sentinel.KindStruct
+	case reflect.Map:
+		return sentinel.KindMap
+	case reflect.Interface:
+		return sentinel.KindInterface
+	default:
+		return sentinel.KindScalar
+	}
+}
+
+// resolveFieldName extracts the field name from the first of tags that names
+// the field, or falls back to Go name.
+func resolveFieldName(field sentinel.FieldMetadata, tags []string) string {
+	for _, name := range tags {
+		if tag, ok := field.Tags[name]; ok {
+			// Parse tag (format: "name,omitempty")
+			parts := strings.Split(tag, ",")
+			if len(parts) > 0 && parts[0] != "" {
+				return parts[0]
+			}
 		}
 	}
 	return field.Name
 }
 
-// resolveFieldKind maps sentinel's FieldKind to vecna's FieldKind.
+// resolveFieldKind maps sentinel's FieldKind to vecna's FieldKind. A pointer
+// is classified by its element type, one level deep, so *float64 is
+// KindFloat; a nil pointer reads as an absent field.
 func resolveFieldKind(kind sentinel.FieldKind, typeName string) FieldKind {
+	if typeName == "time.Time" || typeName == "*time.Time" {
+		return KindTime
+	}
 	switch kind {
 	case sentinel.KindScalar:
 		// Further classify scalars by type name
 		switch {
-		case strings.HasPrefix(typeName, "int"), strings.HasPrefix(typeName, "uint"):
+		case strings.HasPrefix(typeName, "int"):
 			return KindInt
+		case strings.HasPrefix(typeName, "uint"):
+			return KindUint
 		case strings.HasPrefix(typeName, "float"):
 			return KindFloat
 		case typeName == "bool":
@@ -90,21 +371,98 @@ func resolveFieldKind(kind sentinel.FieldKind, typeName string) FieldKind {
 		}
 	case sentinel.KindSlice:
 		return KindSlice
+	case sentinel.KindPointer:
+		elem, ok := strings.CutPrefix(typeName, "*")
+		switch {
+		case !ok || strings.HasPrefix(elem, "*"):
+			return KindUnknown
+		case strings.HasPrefix(elem, "["):
+			return KindSlice
+		default:
+			return resolveFieldKind(sentinel.KindScalar, elem)
+		}
 	default:
 		return KindUnknown
 	}
 }
 
-// Spec returns the schema for documentation/export.
+// resolveMapKind classifies a map type: KindMap with the kind of its values
+// when its keys are strings, otherwise KindUnknown.
+func resolveMapKind(t reflect.Type) (kind, valueKind FieldKind) {
+	if t == nil || t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return KindUnknown, 0
+	}
+	elem := t.Elem()
+	return KindMap, resolveFieldKind(sentinelKind(elem), elem.String())
+}
+
+// Spec returns the schema for documentation/export. The result is a copy;
+// modifying it does not affect the builder.
 func (b *Builder[T]) Spec() Spec {
-	return b.spec
+	spec := b.spec
+	spec.Fields = slices.Clone(b.spec.Fields)
+	return spec
+}
+
+// Fields returns the names of all filterable fields, sorted, e.g. to list
+// the valid choices when a client names an unknown field.
+func (b *Builder[T]) Fields() []string {
+	return slices.Sorted(maps.Keys(b.fields))
+}
+
+// HasField reports whether name is a filterable field or an entry of a
+// KindMap field, such as "attributes.color".
+func (b *Builder[T]) HasField(name string) bool {
+	_, ok := b.lookupField(name)
+	return ok
+}
+
+// lookupField returns the spec of a field, or of a map entry addressed as
+// "field.key", whose kind is the map's value kind.
+func (b *Builder[T]) lookupField(name string) (*FieldSpec, bool) {
+	if spec, ok := b.fields[name]; ok {
+		return spec, true
+	}
+	field, _, ok := b.mapPath(name)
+	if !ok {
+		return nil, false
+	}
+	spec := b.fields[field]
+	return &FieldSpec{Name: name, GoName: spec.GoName, Kind: spec.ValueKind, ID: spec.ID}, true
+}
+
+// mapPath splits name into a KindMap field and a key, for names that are
+// not themselves fields. The field is the longest prefix before a dot that
+// names a KindMap field, so keys may contain dots.
+func (b *Builder[T]) mapPath(name string) (field, key string, ok bool) {
+	for i := len(name) - 1; i > 0; i-- {
+		if name[i] != '.' {
+			continue
+		}
+		if spec, found := b.fields[name[:i]]; found && spec.Kind == KindMap && i < len(name)-1 {
+			return name[:i], name[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// entryKey returns the key of name when it addresses a KindMap entry rather
+// than a field, or "".
+func (b *Builder[T]) entryKey(name string) string {
+	if _, ok := b.fields[name]; ok {
+		return ""
+	}
+	_, key, _ := b.mapPath(name)
+	return key
 }
 
 // Where begins a filter condition on a field.
 // If the field doesn't exist in T, the returned FieldBuilder will
-// produce a Filter with an error accessible via Filter.Err().
+// produce a Filter with an error accessible via Filter.Err(). An entry of
+// a KindMap field is addressed as "field.key", e.g. "attributes.color",
+// and validated against the map's value kind.
 func (b *Builder[T]) Where(field string) *FieldBuilder[T] {
-	spec, ok := b.fields[field]
+	spec, ok := b.lookupField(field)
 	if !ok {
 		return &FieldBuilder[T]{
 			builder: b,
@@ -117,43 +475,123 @@ func (b *Builder[T]) Where(field string) *FieldBuilder[T] {
 		builder: b,
 		field:   field,
 		spec:    spec,
+		key:     b.entryKey(field),
 		err:     nil,
 	}
 }
 
+// WhereIf is like Where, but when cond is false every operator on the
+// returned FieldBuilder yields an All filter marking the condition as
+// absent, which And and Or omit. This keeps optional conditions, e.g. from
+// query parameters, inline:
+//
+//	builder.And(
+//	    builder.Where("active").Eq(true),
+//	    builder.WhereIf(category != "", "category").Eq(category),
+//	)
+//
+// An unknown field is reported regardless of cond.
+func (b *Builder[T]) WhereIf(cond bool, field string) *FieldBuilder[T] {
+	fb := b.Where(field)
+	fb.skip = !cond
+	return fb
+}
+
+// All returns a filter that matches everything, for callers that need an
+// explicit "no filter" value. And omits it, and an Or containing it is All.
+// Unlike the All yielded by WhereIf for a skipped condition, it is not
+// omitted from an Or.
+func (*Builder[T]) All() *Filter {
+	return &Filter{op: All}
+}
+
+// None returns a filter that matches nothing.
+func (*Builder[T]) None() *Filter {
+	return &Filter{op: None}
+}
+
 // And combines filters with logical AND.
 // Returns a Filter that matches when all child filters match.
+// With no filters, or a nil filter, the result carries ErrInvalidFilter.
 func (*Builder[T]) And(filters ...*Filter) *Filter {
-	return &Filter{
-		op:       And,
-		children: filters,
-	}
+	return logicalGroup(And, filters)
 }
 
 // Or combines filters with logical OR.
 // Returns a Filter that matches when any child filter matches.
+// With no filters, or a nil filter, the result carries ErrInvalidFilter.
 func (*Builder[T]) Or(filters ...*Filter) *Filter {
-	return &Filter{
-		op:       Or,
-		children: filters,
+	return logicalGroup(Or, filters)
+}
+
+// logicalGroup joins filters under And or Or, rejecting an empty group as
+// FromSpec does, and nil children as Not does.
+func logicalGroup(op Op, filters []*Filter) *Filter {
+	if len(filters) == 0 {
+		return &Filter{
+			op:  op,
+			err: fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, op),
+		}
 	}
+	if err := checkNonNil(op, filters); err != nil {
+		return &Filter{op: op, children: filters, err: err}
+	}
+	return group(op, filters)
+}
+
+// checkNonNil reports a nil child of a logical filter.
+func checkNonNil(op Op, filters []*Filter) error {
+	if i := slices.Index(filters, nil); i >= 0 {
+		return fmt.Errorf("%w: %s requires non-nil children, got nil at index %d", ErrInvalidFilter, op, i)
+	}
+	return nil
 }
 
 // Not negates a filter.
 // Returns a Filter that matches when the child filter does not match.
+// A nil filter yields ErrInvalidFilter; an error on the child surfaces
+// through Err as usual.
 func (*Builder[T]) Not(filter *Filter) *Filter {
+	if filter == nil {
+		return &Filter{
+			op:       Not,
+			children: []*Filter{filter},
+			err:      fmt.Errorf("%w: %s requires a non-nil child", ErrInvalidFilter, Not),
+		}
+	}
 	return &Filter{
 		op:       Not,
 		children: []*Filter{filter},
 	}
 }
 
+// Xor combines filters with logical exclusive OR.
+// Returns a Filter that matches when exactly one of the two child filters
+// matches. Any other number of filters, or a nil filter, yields
+// ErrInvalidFilter. Compilers without a native exclusive or render it as
+// (a OR b) AND NOT (a AND b).
+func (*Builder[T]) Xor(filters ...*Filter) *Filter {
+	if len(filters) != 2 {
+		return &Filter{
+			op:       Xor,
+			children: filters,
+			err:      fmt.Errorf("%w: %s requires exactly two children, got %d", ErrInvalidFilter, Xor, len(filters)),
+		}
+	}
+	if err := checkNonNil(Xor, filters); err != nil {
+		return &Filter{op: Xor, children: filters, err: err}
+	}
+	return &Filter{op: Xor, children: filters}
+}
+
 // FieldBuilder constructs conditions for a specific field.
 type FieldBuilder[T any] struct {
 	builder *Builder[T]
 	field   string
 	spec    *FieldSpec
+	key     string // Map key when field addresses a KindMap entry
 	err     error
+	skip    bool // build All instead of a condition (WhereIf)
 }
 
 // Eq creates an equality filter (field == value).
@@ -204,11 +642,91 @@ func (fb *FieldBuilder[T]) Like(pattern string) *Filter {
 	return fb.makeFilter(Like, pattern)
 }
 
+// ILike creates a case-insensitive pattern matching filter
+// (field ILIKE pattern), with the same % and _ wildcards as Like.
+func (fb *FieldBuilder[T]) ILike(pattern string) *Filter {
+	return fb.makeFilter(ILike, pattern)
+}
+
+// NotLike creates a negated pattern matching filter (field NOT LIKE
+// pattern), with the same wildcards as Like.
+func (fb *FieldBuilder[T]) NotLike(pattern string) *Filter {
+	return fb.makeFilter(NotLike, pattern)
+}
+
 // Contains creates an array membership filter (array field contains value).
 func (fb *FieldBuilder[T]) Contains(value any) *Filter {
 	return fb.makeFilter(Contains, value)
 }
 
+// ContainsAll creates an array filter matching when the field contains every value.
+func (fb *FieldBuilder[T]) ContainsAll(values ...any) *Filter {
+	return fb.makeFilter(ContainsAll, values)
+}
+
+// ContainsAny creates an array filter matching when the field contains at least one value.
+func (fb *FieldBuilder[T]) ContainsAny(values ...any) *Filter {
+	return fb.makeFilter(ContainsAny, values)
+}
+
+// IsEmpty creates a filter matching an array field with no elements.
+func (fb *FieldBuilder[T]) IsEmpty() *Filter {
+	return fb.makeFilter(IsEmpty, nil)
+}
+
+// IsNotEmpty creates a filter matching an array field with at least one element.
+func (fb *FieldBuilder[T]) IsNotEmpty() *Filter {
+	return fb.makeFilter(IsNotEmpty, nil)
+}
+
+// Between creates an inclusive range filter (low <= field <= high).
+func (fb *FieldBuilder[T]) Between(low, high any) *Filter {
+	return fb.makeFilter(Between, []any{low, high})
+}
+
+// Regex creates a regular expression filter (field matches pattern).
+// The pattern uses Go RE2 syntax and is compiled when the filter is built;
+// a compile error surfaces through Filter.Err().
+func (fb *FieldBuilder[T]) Regex(pattern string) *Filter {
+	filter := fb.makeFilter(Regex, pattern)
+	if filter.err != nil {
+		return filter
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		filter.err = fmt.Errorf("%w: field %s: %w", ErrInvalidFilter, fb.field, err)
+		return filter
+	}
+	filter.regex = re
+	return filter
+}
+
+// StartsWith creates a prefix filter (field starts with prefix).
+func (fb *FieldBuilder[T]) StartsWith(prefix string) *Filter {
+	return fb.makeFilter(Prefix, prefix)
+}
+
+// EndsWith creates a suffix filter (field ends with suffix).
+func (fb *FieldBuilder[T]) EndsWith(suffix string) *Filter {
+	return fb.makeFilter(Suffix, suffix)
+}
+
+// Approx creates an approximate equality filter (|field - value| <= tolerance).
+// Backend compilers expand it to an inclusive range, like Between.
+func (fb *FieldBuilder[T]) Approx(value, tolerance float64) *Filter {
+	filter := fb.makeFilter(Approx, []any{value, tolerance})
+	if filter.err != nil {
+		return filter
+	}
+	switch {
+	case math.IsNaN(value) || math.IsInf(value, 0):
+		filter.err = fmt.Errorf("%w: field %s: value must be finite, got %v", ErrInvalidFilter, fb.field, value)
+	case tolerance < 0 || math.IsNaN(tolerance):
+		filter.err = fmt.Errorf("%w: field %s: tolerance must be non-negative, got %v", ErrInvalidFilter, fb.field, tolerance)
+	}
+	return filter
+}
+
 // makeFilter creates a Filter with the given operator and value.
 func (fb *FieldBuilder[T]) makeFilter(op Op, value any) *Filter {
 	if fb.err != nil {
@@ -219,6 +737,23 @@ func (fb *FieldBuilder[T]) makeFilter(op Op, value any) *Filter {
 			err:   fb.err,
 		}
 	}
+	if fb.skip {
+		return &Filter{op: All, absent: true}
+	}
+
+	// Normalize the value through a custom parser, if one is registered
+	if parse, ok := fb.builder.parsers[fb.field]; ok && !isStringOp(op) && !isValuelessOp(op) && op != Approx && op != Len {
+		parsed, err := parseValue(op, value, parse)
+		if err != nil {
+			return &Filter{
+				op:    op,
+				field: fb.field,
+				value: value,
+				err:   fmt.Errorf("%w: field %s: %w", ErrInvalidFilter, fb.field, err),
+			}
+		}
+		value = parsed
+	}
 
 	// Validate value type against field kind
 	if err := fb.validateValue(op, value); err != nil {
@@ -230,11 +765,118 @@ func (fb *FieldBuilder[T]) makeFilter(op Op, value any) *Filter {
 		}
 	}
 
+	// Restrict fields declared with WithOneOf to their allowed values
+	if allowed, ok := fb.builder.oneOf[fb.field]; ok {
+		if err := checkOneOf(op, value, allowed); err != nil {
+			return &Filter{
+				op:    op,
+				field: fb.field,
+				value: value,
+				err:   fmt.Errorf("%w: field %s: %w", ErrInvalidFilter, fb.field, err),
+			}
+		}
+	}
+
+	// Coerce RFC3339 strings to time.Time for time fields
+	if fb.spec.Kind == KindTime {
+		coerced, err := coerceTimeValue(op, value)
+		if err != nil {
+			return &Filter{
+				op:    op,
+				field: fb.field,
+				value: value,
+				err:   fmt.Errorf("%w: field %s: %w", ErrInvalidFilter, fb.field, err),
+			}
+		}
+		value = coerced
+	}
+
 	return &Filter{
 		op:    op,
 		field: fb.field,
+		key:   fb.key,
 		value: value,
+		kind:  fb.spec.Kind,
+		fold:  fb.spec.Kind == KindString && fb.builder.fold[fb.field] && isFoldOp(op),
+	}
+}
+
+// checkOneOf verifies that op is an equality or set operator and that every
+// value it compares against is one of allowed.
+func checkOneOf(op Op, value any, allowed []any) error {
+	values := []any{value}
+	switch op {
+	case Eq, Ne:
+	case In, Nin:
+		var err error
+		if values, err = sliceValues(value); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("operator %s not allowed on a field restricted to fixed values", op)
+	}
+	for _, v := range values {
+		if !slices.ContainsFunc(allowed, func(a any) bool { return valuesEqual(a, v) }) {
+			return fmt.Errorf("value %v is not one of %v", v, allowed)
+		}
+	}
+	return nil
+}
+
+// parseValue applies a custom parser to a value, element-wise for list operators.
+func parseValue(op Op, value any, parse ValueParser) (any, error) {
+	if !isListOp(op) && op != Between {
+		return parse(value)
+	}
+	values, err := sliceValues(value)
+	if err != nil {
+		return nil, err
+	}
+	parsed := make([]any, len(values))
+	for i, v := range values {
+		if parsed[i], err = parse(v); err != nil {
+			return nil, err
+		}
+	}
+	return parsed, nil
+}
+
+// coerceTimeValue converts a time field's value to time.Time, element-wise for
+// list operators and Between.
+func coerceTimeValue(op Op, value any) (any, error) {
+	if !isListOp(op) && op != Between {
+		return toTime(value)
+	}
+	values, err := sliceValues(value)
+	if err != nil {
+		return nil, err
+	}
+	coerced := make([]any, len(values))
+	for i, v := range values {
+		if coerced[i], err = toTime(v); err != nil {
+			return nil, err
+		}
+	}
+	return coerced, nil
+}
+
+// toTime converts a time.Time, non-nil *time.Time, or RFC3339 string to time.Time.
+func toTime(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v != nil {
+			return *v, nil
+		}
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("expected RFC3339 time, got %q", v)
+		}
+		return t, nil
 	}
+	return time.Time{}, fmt.Errorf("expected time.Time or RFC3339 string, got %T", value)
 }
 
 // validateValue checks if the value type is compatible with the field kind and operator.
@@ -243,29 +885,177 @@ func (fb *FieldBuilder[T]) validateValue(op Op, value any) error {
 		return nil // Already has an error
 	}
 
-	// For In/Nin operators, validate the slice elements
-	if op == In || op == Nin {
-		return validateInValue(value)
+	// For list operators, validate the slice elements
+	if isListOp(op) {
+		if err := validateInValue(value); err != nil {
+			return err
+		}
 	}
 
-	// For Like operator, require string field
-	if op == Like && fb.spec.Kind != KindString {
-		return fmt.Errorf("%w: operator %s not valid for %s field %s",
-			ErrInvalidFilter, op, fb.spec.Kind, fb.field)
+	// For set membership, reject empty sets unless explicitly allowed
+	if (op == In || op == Nin) && !fb.builder.allowEmptyIn {
+		if values, err := sliceValues(value); err == nil && len(values) == 0 {
+			return fmt.Errorf("%w: %s requires at least one value for field %s", ErrInvalidFilter, op, fb.field)
+		}
 	}
 
-	// For Contains operator, require slice field
-	if op == Contains && fb.spec.Kind != KindSlice {
-		return fmt.Errorf("%w: operator %s not valid for %s field %s",
-			ErrInvalidFilter, op, fb.spec.Kind, fb.field)
+	// Fields with a Comparable type define their own order over any values
+	if fb.builder.ordered[fb.field] && isOrderedOp(op) {
+		return nil
 	}
 
-	// For comparison operators on non-numeric fields
-	if isComparisonOp(op) && !isNumericKind(fb.spec.Kind) {
+	// Require an operator valid for the field's kind
+	if !slices.Contains(fb.spec.Kind.ValidOps(), op) {
 		return fmt.Errorf("%w: operator %s not valid for %s field %s",
 			ErrInvalidFilter, op, fb.spec.Kind, fb.field)
 	}
 
+	// For Contains operators on slices of structs, require element-typed values
+	if op == Contains || op == ContainsAll || op == ContainsAny {
+		if err := fb.validateElements(op, value); err != nil {
+			return err
+		}
+	}
+
+	// For equality and comparison operators, require values of the field's type
+	if err := validateValueTypes(op, fb.spec.Kind, value); err != nil {
+		return fmt.Errorf("%w: field %s: %w", ErrInvalidFilter, fb.field, err)
+	}
+
+	// For unsigned fields, reject negative numbers
+	if fb.spec.Kind == KindUint {
+		if err := validateUnsigned(op, value); err != nil {
+			return fmt.Errorf("%w: field %s: %w", ErrInvalidFilter, fb.field, err)
+		}
+	}
+
+	return nil
+}
+
+// validateElements checks that Contains values are assignable to the element
+// type of a slice-of-struct field, since such elements match by deep equality.
+func (fb *FieldBuilder[T]) validateElements(op Op, value any) error {
+	elem := fb.builder.sliceElemType(fb.field)
+	if elem == nil || !isStructType(elem) {
+		return nil
+	}
+	values := []any{value}
+	if op != Contains {
+		var err error
+		if values, err = sliceValues(value); err != nil {
+			return err
+		}
+	}
+	for _, v := range values {
+		if t := reflect.TypeOf(v); t == nil || !t.AssignableTo(elem) {
+			return fmt.Errorf("%w: operator %s on field %s requires %s values, got %T",
+				ErrInvalidFilter, op, fb.field, elem, v)
+		}
+	}
+	return nil
+}
+
+// sliceElemType returns the element type of slice field name of T,
+// or nil if T is not a struct or the field is not a slice.
+func (b *Builder[T]) sliceElemType(name string) reflect.Type {
+	index, ok := b.index[name]
+	if !ok {
+		return nil
+	}
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	ft := t.FieldByIndex(index).Type
+	if ft.Kind() != reflect.Slice && ft.Kind() != reflect.Array {
+		return nil
+	}
+	return ft.Elem()
+}
+
+// isStructType reports whether t is a struct or pointer to struct, other than time.Time.
+func isStructType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != reflect.TypeFor[time.Time]()
+}
+
+// validateValueTypes checks that the values of an equality, comparison, or
+// set operator fit a scalar field kind: strings for KindString, bools for
+// KindBool, finite numbers for KindFloat, and integers for KindInt and
+// KindUint. Integral float64 values, as produced by JSON decoding, count as
+// integers. NaN and infinities are rejected, since they compare unequal or
+// unbounded in every backend.
+func validateValueTypes(op Op, kind FieldKind, value any) error {
+	var values []any
+	switch op {
+	case Eq, Ne, Gt, Gte, Lt, Lte:
+		values = []any{value}
+	case In, Nin, Between:
+		var err error
+		if values, err = sliceValues(value); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	for _, v := range values {
+		if !valueMatchesKind(v, kind) {
+			return fmt.Errorf("%T value %v does not match %s field", v, v, kind)
+		}
+	}
+	return nil
+}
+
+// valueMatchesKind reports whether v is a valid value for a scalar field kind.
+// Non-scalar kinds accept any value.
+func valueMatchesKind(v any, kind FieldKind) bool {
+	switch kind {
+	case KindString:
+		_, ok := v.(string)
+		return ok
+	case KindBool:
+		_, ok := v.(bool)
+		return ok
+	case KindFloat:
+		f, ok := toFloat64(v)
+		return ok && !math.IsNaN(f) && !math.IsInf(f, 0)
+	case KindInt, KindUint:
+		switch reflect.ValueOf(v).Kind() {
+		case reflect.Float32, reflect.Float64:
+			f, _ := toFloat64(v)
+			return f == math.Trunc(f) && !math.IsInf(f, 0)
+		default:
+			_, ok := toFloat64(v)
+			return ok
+		}
+	default:
+		return true
+	}
+}
+
+// validateUnsigned rejects negative numeric values, element-wise for list
+// operators and Between. For Approx only the center value is checked.
+func validateUnsigned(op Op, value any) error {
+	values := []any{value}
+	if isListOp(op) || op == Between || op == Approx {
+		var err error
+		if values, err = sliceValues(value); err != nil {
+			return err
+		}
+		if op == Approx && len(values) > 0 {
+			values = values[:1]
+		}
+	}
+	for _, v := range values {
+		if f, ok := toFloat64(v); ok && f < 0 {
+			return fmt.Errorf("negative value %v for unsigned field", v)
+		}
+	}
 	return nil
 }
 
@@ -278,12 +1068,29 @@ func validateInValue(value any) error {
 	return nil
 }
 
-// isComparisonOp returns true if the operator is a comparison (not equality).
-func isComparisonOp(op Op) bool {
-	return op == Gt || op == Gte || op == Lt || op == Lte
+// isListOp returns true if the operator takes a list of values.
+func isListOp(op Op) bool {
+	return op == In || op == Nin || op == ContainsAll || op == ContainsAny
+}
+
+// isFoldOp reports whether op compares case-insensitively on fields
+// registered with WithCaseInsensitive.
+func isFoldOp(op Op) bool {
+	return op == Eq || op == Ne || op == In || op == Nin
+}
+
+// isStringOp returns true if the operator matches a string pattern.
+// Pattern values are never passed through value parsers.
+func isStringOp(op Op) bool {
+	return op == Like || op == ILike || op == NotLike || op == Regex || op == Prefix || op == Suffix
+}
+
+// isValuelessOp reports whether op tests a field without a value.
+func isValuelessOp(op Op) bool {
+	return op == IsEmpty || op == IsNotEmpty
 }
 
 // isNumericKind returns true if the field kind is numeric.
 func isNumericKind(kind FieldKind) bool {
-	return kind == KindInt || kind == KindFloat
+	return kind == KindInt || kind == KindUint || kind == KindFloat
 }
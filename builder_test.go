@@ -2,7 +2,12 @@ package vecna
 
 import (
 	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/zoobzio/sentinel"
 )
@@ -79,6 +84,71 @@ func TestBuilder_Spec(t *testing.T) {
 	}
 }
 
+func TestBuilder_Fields(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	want := []string{"NoTag", "active", "category", "count", "score", "tags"}
+	if got := builder.Fields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"category", true},
+		{"NoTag", true},
+		{"Category", false},
+		{"Internal", false},
+		{"-", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := builder.HasField(tt.name); got != tt.want {
+			t.Errorf("HasField(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuilder_FieldIDs(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	spec := builder.Spec()
+
+	// IDs follow sorted name order (byte order, so "NoTag" sorts first)
+	want := map[string]int{
+		"NoTag":    0,
+		"active":   1,
+		"category": 2,
+		"count":    3,
+		"score":    4,
+		"tags":     5,
+	}
+	for name, id := range want {
+		if got := spec.Field(name).ID; got != id {
+			t.Errorf("Field %q ID = %d, want %d", name, got, id)
+		}
+	}
+
+	other, _ := New[testMetadata]()
+	otherSpec := other.Spec()
+	for _, field := range spec.Fields {
+		if got := otherSpec.Field(field.Name).ID; got != field.ID {
+			t.Errorf("Field %q ID = %d in second builder, want %d", field.Name, got, field.ID)
+		}
+	}
+
+	t.Run("excluded fields are not numbered", func(t *testing.T) {
+		builder, _ := New[testMetadata](WithExcludeKinds(KindBool))
+		spec := builder.Spec()
+		if got := spec.Field("score").ID; got != 3 {
+			t.Errorf("Field score ID = %d, want 3", got)
+		}
+		if len(spec.Fields) != 5 {
+			t.Errorf("len(Spec.Fields) = %d, want 5", len(spec.Fields))
+		}
+	})
+}
+
 func TestBuilder_Where(t *testing.T) {
 	builder, _ := New[testMetadata]()
 
@@ -218,6 +288,47 @@ func TestBuilder_Or(t *testing.T) {
 	}
 }
 
+func TestBuilder_EmptyGroups(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"and", builder.And(), "and requires at least one child"},
+		{"or", builder.Or(), "or requires at least one child"},
+		{"and nil", builder.And(nil, builder.Where("category").Eq("tech")), "and requires non-nil children, got nil at index 0"},
+		{"or nil", builder.Or(builder.Where("category").Eq("tech"), nil), "or requires non-nil children, got nil at index 1"},
+		{"xor nil", builder.Xor(builder.Where("category").Eq("tech"), nil), "xor requires non-nil children, got nil at index 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Err()
+			if !errors.Is(err, ErrInvalidFilter) {
+				t.Fatalf("Err() = %v, want %v", err, ErrInvalidFilter)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Err() = %q, want it to contain %q", err, tt.want)
+			}
+			if _, _, err := builder.ToSQL(tt.filter); !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("ToSQL() error = %v, want %v", err, ErrInvalidFilter)
+			}
+			if _, err := builder.Match(tt.filter, testMetadata{}); !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("Match() error = %v, want %v", err, ErrInvalidFilter)
+			}
+		})
+	}
+
+	t.Run("all skipped", func(t *testing.T) {
+		f := builder.And(builder.WhereIf(false, "category").Eq("tech"))
+		if f.Err() != nil || f.Op() != All {
+			t.Errorf("And(skipped) = %s, %v, want all, nil", f, f.Err())
+		}
+	})
+}
+
 func TestBuilder_NestedFilters(t *testing.T) {
 	builder, _ := New[testMetadata]()
 
@@ -270,7 +381,8 @@ func TestResolveFieldKind(t *testing.T) {
 	}{
 		{"int", KindInt},
 		{"int64", KindInt},
-		{"uint32", KindInt},
+		{"uint32", KindUint},
+		{"uint", KindUint},
 		{"float64", KindFloat},
 		{"float32", KindFloat},
 		{"bool", KindBool},
@@ -288,6 +400,15 @@ func TestResolveFieldKind(t *testing.T) {
 	}
 }
 
+func TestResolveFieldKind_Time(t *testing.T) {
+	if got := resolveFieldKind(sentinel.KindStruct, "time.Time"); got != KindTime {
+		t.Errorf("resolveFieldKind(time.Time) = %v, want %v", got, KindTime)
+	}
+	if got := resolveFieldKind(sentinel.KindPointer, "*time.Time"); got != KindTime {
+		t.Errorf("resolveFieldKind(*time.Time) = %v, want %v", got, KindTime)
+	}
+}
+
 func TestResolveFieldKind_Slice(t *testing.T) {
 	got := resolveFieldKind(sentinel.KindSlice, "[]string")
 	if got != KindSlice {
@@ -357,6 +478,89 @@ func TestFieldBuilder_Like(t *testing.T) {
 	}
 }
 
+func TestFieldBuilder_ILike(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.Where("category").ILike("%TECH%")
+
+	if filter.Op() != ILike {
+		t.Errorf("Filter.Op() = %v, want %v", filter.Op(), ILike)
+	}
+	if filter.Value() != "%TECH%" {
+		t.Errorf("Filter.Value() = %v, want %%TECH%%", filter.Value())
+	}
+	if filter.Err() != nil {
+		t.Errorf("Filter.Err() = %v, want nil", filter.Err())
+	}
+
+	if err := builder.Where("score").ILike("%value%").Err(); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("ILike on float field Err() = %v, want %v", err, ErrInvalidFilter)
+	}
+}
+
+func TestFieldBuilder_NotLike(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.Where("category").NotLike("%tech%")
+
+	if filter.Op() != NotLike {
+		t.Errorf("Filter.Op() = %v, want %v", filter.Op(), NotLike)
+	}
+	if filter.Field() != "category" {
+		t.Errorf("Filter.Field() = %v, want category", filter.Field())
+	}
+	if filter.Value() != "%tech%" {
+		t.Errorf("Filter.Value() = %v, want %%tech%%", filter.Value())
+	}
+	if filter.Err() != nil {
+		t.Errorf("Filter.Err() = %v, want nil", filter.Err())
+	}
+}
+
+func TestFieldBuilder_NotLikeOnNonString(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.Where("score").NotLike("%value%")
+
+	if filter.Err() == nil {
+		t.Error("NotLike on float field should have error")
+	}
+	if !errors.Is(filter.Err(), ErrInvalidFilter) {
+		t.Errorf("Filter.Err() = %v, want %v", filter.Err(), ErrInvalidFilter)
+	}
+}
+
+func TestFieldBuilder_IsEmpty(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   Op
+	}{
+		{"is empty", builder.Where("tags").IsEmpty(), IsEmpty},
+		{"is not empty", builder.Where("tags").IsNotEmpty(), IsNotEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.filter.Err() != nil {
+				t.Fatalf("Filter.Err() = %v, want nil", tt.filter.Err())
+			}
+			if tt.filter.Op() != tt.want {
+				t.Errorf("Filter.Op() = %v, want %v", tt.filter.Op(), tt.want)
+			}
+			if tt.filter.Value() != nil {
+				t.Errorf("Filter.Value() = %v, want nil", tt.filter.Value())
+			}
+		})
+	}
+
+	if err := builder.Where("category").IsEmpty().Err(); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("IsEmpty on string field Err() = %v, want %v", err, ErrInvalidFilter)
+	}
+}
+
 func TestFieldBuilder_LikeOnNonString(t *testing.T) {
 	builder, _ := New[testMetadata]()
 
@@ -404,6 +608,149 @@ func TestFieldBuilder_ContainsOnNonSlice(t *testing.T) {
 	}
 }
 
+func TestFieldBuilder_Between(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.Where("score").Between(0.2, 0.8)
+
+	if filter.Op() != Between {
+		t.Errorf("Filter.Op() = %v, want %v", filter.Op(), Between)
+	}
+	if !reflect.DeepEqual(filter.Value(), []any{0.2, 0.8}) {
+		t.Errorf("Filter.Value() = %v, want [0.2 0.8]", filter.Value())
+	}
+	if filter.Err() != nil {
+		t.Errorf("Filter.Err() = %v, want nil", filter.Err())
+	}
+}
+
+func TestFieldBuilder_BetweenOnNonNumeric(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.Where("category").Between("a", "m")
+
+	if !errors.Is(filter.Err(), ErrInvalidFilter) {
+		t.Errorf("Filter.Err() = %v, want %v", filter.Err(), ErrInvalidFilter)
+	}
+}
+
+func TestFieldBuilder_Regex(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	t.Run("valid", func(t *testing.T) {
+		filter := builder.Where("category").Regex("^tech(nology)?$")
+		if filter.Err() != nil {
+			t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+		}
+		if filter.Op() != Regex {
+			t.Errorf("Filter.Op() = %v, want %v", filter.Op(), Regex)
+		}
+		if filter.regex == nil {
+			t.Error("Filter.regex = nil, want compiled pattern")
+		}
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		filter := builder.Where("category").Regex("tech(")
+		if !errors.Is(filter.Err(), ErrInvalidFilter) {
+			t.Errorf("Filter.Err() = %v, want %v", filter.Err(), ErrInvalidFilter)
+		}
+	})
+
+	t.Run("non-string field", func(t *testing.T) {
+		filter := builder.Where("count").Regex("^1")
+		if !errors.Is(filter.Err(), ErrInvalidFilter) {
+			t.Errorf("Filter.Err() = %v, want %v", filter.Err(), ErrInvalidFilter)
+		}
+	})
+}
+
+func TestFieldBuilder_StartsWithEndsWith(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		wantOp  Op
+		wantErr bool
+	}{
+		{"starts with", builder.Where("category").StartsWith("te"), Prefix, false},
+		{"ends with", builder.Where("category").EndsWith("ch"), Suffix, false},
+		{"starts with non-string", builder.Where("count").StartsWith("1"), Prefix, true},
+		{"ends with non-string", builder.Where("tags").EndsWith("o"), Suffix, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.filter.Op() != tt.wantOp {
+				t.Errorf("Filter.Op() = %v, want %v", tt.filter.Op(), tt.wantOp)
+			}
+			if tt.wantErr != errors.Is(tt.filter.Err(), ErrInvalidFilter) {
+				t.Errorf("Filter.Err() = %v, wantErr %v", tt.filter.Err(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFieldBuilder_Approx(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		wantErr bool
+	}{
+		{"valid", builder.Where("score").Approx(0.5, 0.01), false},
+		{"zero tolerance", builder.Where("count").Approx(3, 0), false},
+		{"negative tolerance", builder.Where("score").Approx(0.5, -0.01), true},
+		{"nan tolerance", builder.Where("score").Approx(0.5, math.NaN()), true},
+		{"non-numeric field", builder.Where("category").Approx(0.5, 0.01), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.filter.Op() != Approx {
+				t.Errorf("Filter.Op() = %v, want %v", tt.filter.Op(), Approx)
+			}
+			if tt.wantErr != errors.Is(tt.filter.Err(), ErrInvalidFilter) {
+				t.Errorf("Filter.Err() = %v, wantErr %v", tt.filter.Err(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFieldBuilder_ContainsAllAny(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		wantOp  Op
+		wantErr bool
+	}{
+		{"contains all", builder.Where("tags").ContainsAll("go", "db"), ContainsAll, false},
+		{"contains any", builder.Where("tags").ContainsAny("go", "db"), ContainsAny, false},
+		{"contains all non-slice", builder.Where("category").ContainsAll("go"), ContainsAll, true},
+		{"contains any non-slice", builder.Where("count").ContainsAny(1), ContainsAny, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.filter.Op() != tt.wantOp {
+				t.Errorf("Filter.Op() = %v, want %v", tt.filter.Op(), tt.wantOp)
+			}
+			if tt.wantErr != errors.Is(tt.filter.Err(), ErrInvalidFilter) {
+				t.Errorf("Filter.Err() = %v, wantErr %v", tt.filter.Err(), tt.wantErr)
+			}
+		})
+	}
+
+	filter := builder.Where("tags").ContainsAll("go", "db")
+	if !reflect.DeepEqual(filter.Value(), []any{"go", "db"}) {
+		t.Errorf("Filter.Value() = %v, want [go db]", filter.Value())
+	}
+}
+
 func TestBuilder_Not(t *testing.T) {
 	builder, _ := New[testMetadata]()
 
@@ -424,6 +771,47 @@ func TestBuilder_Not(t *testing.T) {
 	}
 }
 
+func TestBuilder_Not_Invalid(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	if err := builder.Not(nil).Err(); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("Not(nil).Err() = %v, want %v", err, ErrInvalidFilter)
+	}
+
+	f := builder.Not(builder.Where("missing").Eq("x"))
+	if err := f.Err(); !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("Not(invalid).Err() = %v, want %v", err, ErrFieldNotFound)
+	}
+	if got := len(f.Errors()); got != 1 {
+		t.Errorf("len(Not(invalid).Errors()) = %d, want 1", got)
+	}
+}
+
+func TestBuilder_Xor(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	f := builder.Xor(builder.Where("active").Eq(true), builder.Where("score").Gte(0.5))
+	if f.Err() != nil {
+		t.Fatalf("Xor().Err() = %v, want nil", f.Err())
+	}
+	if f.Op() != Xor || len(f.Children()) != 2 {
+		t.Errorf("Xor() = %v with %d children, want xor with 2", f.Op(), len(f.Children()))
+	}
+	if got, want := f.String(), `(active == true XOR score >= 0.5)`; got != want {
+		t.Errorf("Xor().String() = %s, want %s", got, want)
+	}
+
+	for _, n := range []int{0, 1, 3} {
+		filters := make([]*Filter, n)
+		for i := range filters {
+			filters[i] = builder.Where("active").Eq(true)
+		}
+		if err := builder.Xor(filters...).Err(); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("Xor(%d filters).Err() = %v, want %v", n, err, ErrInvalidFilter)
+		}
+	}
+}
+
 func TestBuilder_NotNested(t *testing.T) {
 	builder, _ := New[testMetadata]()
 
@@ -450,3 +838,650 @@ func TestBuilder_NotNested(t *testing.T) {
 		t.Errorf("Filter.Err() = %v, want nil", filter.Err())
 	}
 }
+
+// Test metadata struct with a tagged unexported field.
+type unexportedMetadata struct {
+	Category string `json:"category"`
+	tenant   string `vecna:"tenant"`
+	untagged string
+}
+
+func TestNew_IncludeUnexported(t *testing.T) {
+	_ = unexportedMetadata{tenant: "acme", untagged: "x"}
+
+	t.Run("excluded by default", func(t *testing.T) {
+		builder, err := New[unexportedMetadata]()
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		spec := builder.Spec()
+		if spec.Field("tenant") != nil {
+			t.Error("unexported field should not be registered by default")
+		}
+	})
+
+	t.Run("included with option", func(t *testing.T) {
+		builder, err := New[unexportedMetadata](WithIncludeUnexported())
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		spec := builder.Spec()
+		field := spec.Field("tenant")
+		if field == nil {
+			t.Fatal("tagged unexported field should be registered")
+		}
+		if field.GoName != "tenant" || field.Kind != KindString {
+			t.Errorf("FieldSpec = %+v, want GoName tenant, Kind string", *field)
+		}
+		if spec.Field("untagged") != nil {
+			t.Error("untagged unexported field should not be registered")
+		}
+
+		filter := builder.Where("tenant").Eq("acme")
+		if filter.Err() != nil {
+			t.Errorf("Filter.Err() = %v, want nil", filter.Err())
+		}
+
+		sql, _, err := builder.ToSQL(filter)
+		if err != nil {
+			t.Fatalf("ToSQL() error = %v", err)
+		}
+		if sql != `"tenant" = $1` {
+			t.Errorf("ToSQL() sql = %s", sql)
+		}
+	})
+}
+
+// Test metadata struct with time fields.
+type eventMetadata struct {
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+func TestFieldBuilder_Time(t *testing.T) {
+	builder, err := New[eventMetadata]()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	spec := builder.Spec()
+	for _, name := range []string{"created_at", "deleted_at"} {
+		if field := spec.Field(name); field == nil || field.Kind != KindTime {
+			t.Errorf("Spec.Field(%s) = %+v, want Kind time", name, field)
+		}
+	}
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("time value", func(t *testing.T) {
+		filter := builder.Where("created_at").Gte(ts)
+		if filter.Err() != nil {
+			t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+		}
+		if filter.Value() != ts {
+			t.Errorf("Filter.Value() = %v, want %v", filter.Value(), ts)
+		}
+	})
+
+	t.Run("rfc3339 string coerced", func(t *testing.T) {
+		filter := builder.Where("created_at").Lte("2024-01-02T03:04:05Z")
+		if got, ok := filter.Value().(time.Time); !ok || !got.Equal(ts) {
+			t.Errorf("Filter.Value() = %#v, want %v", filter.Value(), ts)
+		}
+	})
+
+	t.Run("between coerced element-wise", func(t *testing.T) {
+		filter := builder.Where("deleted_at").Between("2024-01-01T00:00:00Z", ts)
+		values, ok := filter.Value().([]any)
+		if filter.Err() != nil || !ok || len(values) != 2 {
+			t.Fatalf("Filter = %v, %v, want two-element range", filter.Value(), filter.Err())
+		}
+		if _, ok := values[0].(time.Time); !ok {
+			t.Errorf("Filter.Value()[0] = %T, want time.Time", values[0])
+		}
+	})
+
+	tests := []struct {
+		name   string
+		filter *Filter
+	}{
+		{"malformed string", builder.Where("created_at").Gt("yesterday")},
+		{"wrong type", builder.Where("created_at").Gt(42)},
+		{"approx", builder.Where("created_at").Approx(1, 1)},
+		{"like", builder.Where("created_at").Like("2024%")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.filter.Err(), ErrInvalidFilter) {
+				t.Errorf("Filter.Err() = %v, want %v", tt.filter.Err(), ErrInvalidFilter)
+			}
+		})
+	}
+}
+
+// Test metadata struct with a slice-of-struct field.
+type taggedMetadata struct {
+	Name string `json:"name"`
+	Tags []tag  `json:"tags"`
+}
+
+type tag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func TestFieldBuilder_ContainsStruct(t *testing.T) {
+	builder, _ := New[taggedMetadata]()
+	item := taggedMetadata{Name: "a", Tags: []tag{{"env", "prod"}, {"team", "search"}}}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   bool
+	}{
+		{"matching element", builder.Where("tags").Contains(tag{"env", "prod"}), true},
+		{"partial element", builder.Where("tags").Contains(tag{"env", "dev"}), false},
+		{"contains all", builder.Where("tags").ContainsAll(tag{"env", "prod"}, tag{"team", "search"}), true},
+		{"contains any", builder.Where("tags").ContainsAny(tag{"env", "dev"}, tag{"team", "search"}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.filter.Err() != nil {
+				t.Fatalf("Filter.Err() = %v, want nil", tt.filter.Err())
+			}
+			got, err := builder.Match(tt.filter, item)
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name   string
+		filter *Filter
+	}{
+		{"string value", builder.Where("tags").Contains("env")},
+		{"map value", builder.Where("tags").Contains(map[string]any{"key": "env"})},
+		{"mixed list", builder.Where("tags").ContainsAny(tag{"env", "prod"}, "team")},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.filter.Err(), ErrInvalidFilter) {
+				t.Errorf("Filter.Err() = %v, want %v", tt.filter.Err(), ErrInvalidFilter)
+			}
+		})
+	}
+
+	t.Run("compilers", func(t *testing.T) {
+		filter := builder.Where("tags").Contains(tag{"env", "prod"})
+		if _, _, err := builder.ToSQL(filter); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("ToSQL() error = %v, want %v", err, ErrInvalidFilter)
+		}
+		if _, err := CompileToSurreal(filter); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("CompileToSurreal() error = %v, want %v", err, ErrInvalidFilter)
+		}
+		sql, _, err := CompileToJSONB(filter, "metadata")
+		if err != nil || sql != `"metadata" @> $1` {
+			t.Errorf("CompileToJSONB() = %s, %v, want containment", sql, err)
+		}
+	})
+}
+
+// Test metadata struct with unsigned fields.
+type counterMetadata struct {
+	Hits  uint64 `json:"hits"`
+	Delta int    `json:"delta"`
+}
+
+func TestFieldBuilder_Unsigned(t *testing.T) {
+	builder, _ := New[counterMetadata]()
+
+	spec := builder.Spec()
+	if field := spec.Field("hits"); field == nil || field.Kind != KindUint {
+		t.Fatalf("Spec.Field(hits) = %+v, want Kind uint", field)
+	}
+
+	valid := []*Filter{
+		builder.Where("hits").Gte(0),
+		builder.Where("hits").Eq(uint64(10)),
+		builder.Where("hits").Between(1, 100),
+		builder.Where("delta").Gt(-5),
+	}
+	for _, filter := range valid {
+		if filter.Err() != nil {
+			t.Errorf("Filter.Err() = %v, want nil", filter.Err())
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+	}{
+		{"eq", builder.Where("hits").Eq(-1)},
+		{"gt", builder.Where("hits").Gt(-0.5)},
+		{"in", builder.Where("hits").In(1, -2)},
+		{"between", builder.Where("hits").Between(-10, 10)},
+		{"approx", builder.Where("hits").Approx(-1, 2)},
+		{"spec", builder.FromSpec(&FilterSpec{Op: "lt", Field: "hits", Value: -3.0})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.filter.Err(), ErrInvalidFilter) {
+				t.Errorf("Filter.Err() = %v, want %v", tt.filter.Err(), ErrInvalidFilter)
+			}
+		})
+	}
+}
+
+func TestFieldBuilder_ValueTypeMismatch(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+	}{
+		{"string on float", builder.Where("score").Eq("not a number")},
+		{"string on int", builder.Where("count").Ne("ten")},
+		{"fractional float on int", builder.Where("count").Eq(1.5)},
+		{"bool on string", builder.Where("category").Eq(true)},
+		{"int on string", builder.Where("category").Ne(1)},
+		{"string on bool", builder.Where("active").Eq("true")},
+		{"string comparison on float", builder.Where("score").Gt("0.5")},
+		{"mixed in", builder.Where("count").In(1, "two")},
+		{"string between", builder.Where("score").Between("a", "z")},
+		{"spec", builder.FromSpec(&FilterSpec{Op: "eq", Field: "score", Value: "high"})},
+		{"nan on float", builder.Where("score").Eq(math.NaN())},
+		{"infinity on float", builder.Where("score").Gt(math.Inf(1))},
+		{"infinity in list", builder.Where("score").In(0.5, math.Inf(-1))},
+		{"nan between", builder.Where("score").Between(0.0, math.NaN())},
+		{"nan approx", builder.Where("score").Approx(math.NaN(), 0.1)},
+		{"nan on int", builder.Where("count").Eq(math.NaN())},
+		{"nan spec", builder.FromSpec(&FilterSpec{Op: "eq", Field: "score", Value: math.NaN()})},
+		{"infinity spec list", builder.FromSpec(&FilterSpec{Op: "in", Field: "score", Value: []any{math.Inf(1)}})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.filter.Err(), ErrInvalidFilter) {
+				t.Errorf("Filter.Err() = %v, want %v", tt.filter.Err(), ErrInvalidFilter)
+			}
+		})
+	}
+
+	valid := []struct {
+		name   string
+		filter *Filter
+	}{
+		{"int on float", builder.Where("score").Eq(1)},
+		{"integral float on int", builder.Where("count").Eq(10.0)},
+		{"json spec number on int", builder.FromSpec(&FilterSpec{Op: "in", Field: "count", Value: []any{1.0, 2.0}})},
+		{"int64 on int", builder.Where("count").Gte(int64(3))},
+		{"slice eq", builder.Where("tags").Eq([]string{"go"})},
+	}
+
+	for _, tt := range valid {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.filter.Err() != nil {
+				t.Errorf("Filter.Err() = %v, want nil", tt.filter.Err())
+			}
+		})
+	}
+}
+
+func TestBuilder_WhereIf(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	t.Run("true adds condition", func(t *testing.T) {
+		filter := builder.And(
+			builder.Where("active").Eq(true),
+			builder.WhereIf(true, "category").Eq("tech"),
+		)
+		if len(filter.Children()) != 2 {
+			t.Errorf("len(Children()) = %d, want 2", len(filter.Children()))
+		}
+	})
+
+	t.Run("false adds no child", func(t *testing.T) {
+		filter := builder.And(
+			builder.Where("active").Eq(true),
+			builder.WhereIf(false, "category").Eq("tech"),
+			builder.WhereIf(false, "score").Gte(0.5),
+		)
+		if len(filter.Children()) != 1 || filter.Children()[0].Field() != "active" {
+			t.Errorf("Filter = %s, want only the active condition", filter)
+		}
+
+		or := builder.Or(builder.WhereIf(false, "category").Eq("tech"), builder.Where("count").Gt(1))
+		if len(or.Children()) != 1 {
+			t.Errorf("len(Or().Children()) = %d, want 1", len(or.Children()))
+		}
+
+		nested := builder.Or(builder.And(builder.WhereIf(false, "category").Eq("tech")), builder.Where("count").Gt(1))
+		if len(nested.Children()) != 1 {
+			t.Errorf("len(Or(And()).Children()) = %d, want 1", len(nested.Children()))
+		}
+	})
+
+	t.Run("all skipped", func(t *testing.T) {
+		filter := builder.And(builder.WhereIf(false, "category").Eq("tech"))
+		if filter.Op() != All {
+			t.Fatalf("Filter.Op() = %v, want %v", filter.Op(), All)
+		}
+		ok, err := builder.Match(filter, testMetadata{})
+		if err != nil || !ok {
+			t.Errorf("Match(All) = %v, %v, want true", ok, err)
+		}
+	})
+
+	t.Run("unknown field reported", func(t *testing.T) {
+		filter := builder.WhereIf(false, "missing").Eq("x")
+		if !errors.Is(filter.Err(), ErrFieldNotFound) {
+			t.Errorf("Filter.Err() = %v, want %v", filter.Err(), ErrFieldNotFound)
+		}
+	})
+
+	t.Run("fluent", func(t *testing.T) {
+		filter := builder.Where("active").Eq(true).And(builder.WhereIf(false, "category").Eq("tech"))
+		if filter.Op() != And || len(filter.Children()) != 1 {
+			t.Errorf("Filter = %s, want single-child And", filter)
+		}
+	})
+
+	t.Run("spec round trip", func(t *testing.T) {
+		spec, err := builder.WhereIf(false, "category").Eq("tech").ToSpec()
+		if err != nil {
+			t.Fatalf("ToSpec() error = %v", err)
+		}
+		if spec.Op != "all" || builder.FromSpec(spec).Op() != All {
+			t.Errorf("ToSpec().Op = %q, want all", spec.Op)
+		}
+	})
+}
+
+func TestBuilder_AllNone(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	doc := testMetadata{Category: "tech"}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   bool
+	}{
+		{"all", builder.All(), true},
+		{"none", builder.None(), false},
+		{"not none", builder.Not(builder.None()), true},
+		{"and with none", builder.And(builder.Where("category").Eq("tech"), builder.None()), false},
+		{"or with none", builder.Or(builder.Where("category").Eq("tech"), builder.None()), true},
+		{"and with all", builder.And(builder.Where("category").Eq("tech"), builder.All()), true},
+		{"or with all", builder.Or(builder.Where("category").Eq("sports"), builder.All()), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.Match(tt.filter, doc)
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+			if normalized, _ := builder.Match(tt.filter.Normalize(), doc); normalized != tt.want {
+				t.Errorf("Match(Normalize()) = %v, want %v", normalized, tt.want)
+			}
+		})
+	}
+
+	t.Run("sql", func(t *testing.T) {
+		for filter, want := range map[*Filter]string{
+			builder.All():  "TRUE",
+			builder.None(): "FALSE",
+			builder.Or(builder.Where("count").Gt(1), builder.None()): `("Count" > $1 OR FALSE)`,
+		} {
+			sql, _, err := builder.ToSQL(filter)
+			if err != nil || sql != want {
+				t.Errorf("ToSQL() = %s, %v, want %s", sql, err, want)
+			}
+		}
+	})
+
+	t.Run("spec round trip", func(t *testing.T) {
+		for _, filter := range []*Filter{builder.All(), builder.None()} {
+			spec, err := filter.ToSpec()
+			if err != nil {
+				t.Fatalf("ToSpec() error = %v", err)
+			}
+			if got := builder.FromSpec(spec); got.Op() != filter.Op() || got.Err() != nil {
+				t.Errorf("FromSpec(ToSpec()) = %s, want %s", got, filter)
+			}
+		}
+	})
+}
+
+func TestNew_SchemaCache(t *testing.T) {
+	first, _ := New[testMetadata]()
+	second, _ := New[testMetadata](WithAllowEmptyIn())
+	if reflect.ValueOf(first.fields).Pointer() != reflect.ValueOf(second.fields).Pointer() {
+		t.Error("New() did not reuse the cached schema for the same type")
+	}
+
+	renamed, _ := New[testMetadata](WithTagPriority("db", "json"))
+	if reflect.ValueOf(first.fields).Pointer() == reflect.ValueOf(renamed.fields).Pointer() {
+		t.Error("New() shared a schema across different tag options")
+	}
+	excluded, _ := New[testMetadata](WithExcludeKinds(KindSlice))
+	if excluded.HasField("tags") || !first.HasField("tags") {
+		t.Errorf("HasField(tags) = %v, %v, want false, true", excluded.HasField("tags"), first.HasField("tags"))
+	}
+
+	if _, err := New[testMetadata](WithFailOnUnknownKind()); err != nil {
+		t.Errorf("New(WithFailOnUnknownKind()) error = %v", err)
+	}
+}
+
+func TestBuilder_Spec_Copy(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	spec := builder.Spec()
+	spec.Fields[0].Kind = KindUnknown
+	spec.Fields[0].Name = "changed"
+
+	other, _ := New[testMetadata]()
+	if got := other.Spec().Fields[0]; got.Name == "changed" || got.Kind == KindUnknown {
+		t.Errorf("Spec().Fields[0] = %+v, modified through an earlier Spec()", got)
+	}
+	if !builder.HasField("category") {
+		t.Error("HasField(category) = false after modifying Spec()")
+	}
+}
+
+func TestNew_Concurrent(t *testing.T) {
+	schemaCache.Clear()
+
+	var wg sync.WaitGroup
+	builders := make([]*Builder[testMetadata], 16)
+	for i := range builders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			builders[i], _ = New[testMetadata]()
+		}()
+	}
+	wg.Wait()
+
+	for i, builder := range builders {
+		if builder == nil {
+			t.Fatalf("New() #%d returned nil", i)
+		}
+		if err := builder.Where("score").Gte(0.5).Err(); err != nil {
+			t.Errorf("builder #%d Where(score).Gte() error = %v", i, err)
+		}
+		if reflect.ValueOf(builder.fields).Pointer() != reflect.ValueOf(builders[0].fields).Pointer() {
+			t.Errorf("builder #%d does not share the cached schema", i)
+		}
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.Run("cold", func(b *testing.B) {
+		for b.Loop() {
+			schemaCache.Clear()
+			_, _ = New[testMetadata]()
+		}
+	})
+	b.Run("warm", func(b *testing.B) {
+		_, _ = New[testMetadata]()
+		for b.Loop() {
+			_, _ = New[testMetadata]()
+		}
+	})
+}
+
+type attributedMetadata struct {
+	Name       string              `json:"name"`
+	Attributes map[string]string   `json:"attributes"`
+	Scores     map[string]float64  `json:"scores"`
+	Labels     map[string][]string `json:"labels"`
+	Extra      map[string]any      `json:"extra"`
+	Codes      map[int]string      `json:"codes"`
+}
+
+func TestBuilder_MapFields(t *testing.T) {
+	builder, err := New[attributedMetadata]()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	spec := builder.Spec()
+	kinds := []struct {
+		field     string
+		kind      FieldKind
+		valueKind FieldKind
+	}{
+		{"attributes", KindMap, KindString},
+		{"scores", KindMap, KindFloat},
+		{"labels", KindMap, KindSlice},
+		{"extra", KindMap, KindUnknown},
+		{"codes", KindUnknown, 0},
+	}
+	for _, tt := range kinds {
+		field := spec.Field(tt.field)
+		if field == nil || field.Kind != tt.kind || field.ValueKind != tt.valueKind {
+			t.Errorf("Spec.Field(%s) = %+v, want Kind %s, ValueKind %s", tt.field, field, tt.kind, tt.valueKind)
+		}
+	}
+
+	valid := []*Filter{
+		builder.Where("attributes.color").Eq("red"),
+		builder.Where("attributes.color").Like("r%"),
+		builder.Where("attributes.a.b").In("x", "y"),
+		builder.Where("scores.relevance").Gte(0.5),
+		builder.Where("labels.tags").Contains("go"),
+		builder.Where("extra.note").Eq(1),
+	}
+	for _, filter := range valid {
+		if filter.Err() != nil {
+			t.Errorf("Filter.Err() = %v, want nil", filter.Err())
+		}
+	}
+	if f := builder.Where("attributes.a.b").Eq("x"); f.Field() != "attributes.a.b" {
+		t.Errorf("Filter.Field() = %s, want attributes.a.b", f.Field())
+	}
+
+	invalid := []struct {
+		name    string
+		filter  *Filter
+		wantErr error
+	}{
+		{"map itself", builder.Where("attributes").Eq("red"), ErrInvalidFilter},
+		{"value kind", builder.Where("attributes.color").Gt(1), ErrInvalidFilter},
+		{"value type", builder.Where("scores.relevance").Eq("high"), ErrInvalidFilter},
+		{"empty key", builder.Where("attributes.").Eq("red"), ErrFieldNotFound},
+		{"non-map field", builder.Where("name.first").Eq("x"), ErrFieldNotFound},
+		{"non-string keys", builder.Where("codes.1").Eq("x"), ErrFieldNotFound},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Err(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Filter.Err() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if !builder.HasField("attributes.color") || builder.HasField("name.first") {
+		t.Error("HasField() does not resolve map entries")
+	}
+}
+
+type optionalMetadata struct {
+	Score *float64   `json:"score"`
+	Title *string    `json:"title"`
+	Count *uint      `json:"count"`
+	Tags  *[]string  `json:"tags"`
+	Seen  *time.Time `json:"seen"`
+	Deep  **int      `json:"deep"`
+}
+
+func TestNew_PointerFields(t *testing.T) {
+	builder, err := New[optionalMetadata]()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	spec := builder.Spec()
+	kinds := map[string]FieldKind{
+		"score": KindFloat,
+		"title": KindString,
+		"count": KindUint,
+		"tags":  KindSlice,
+		"seen":  KindTime,
+		"deep":  KindUnknown,
+	}
+	for name, want := range kinds {
+		if field := spec.Field(name); field == nil || field.Kind != want {
+			t.Errorf("Spec.Field(%s) = %+v, want Kind %s", name, field, want)
+		}
+	}
+
+	valid := []*Filter{
+		builder.Where("score").Gte(0.5),
+		builder.Where("title").Like("intro%"),
+		builder.Where("count").Between(1, 10),
+		builder.Where("tags").Contains("go"),
+	}
+	for _, filter := range valid {
+		if filter.Err() != nil {
+			t.Errorf("Filter.Err() = %v, want nil", filter.Err())
+		}
+	}
+	if err := builder.Where("score").Eq("high").Err(); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("Where(score).Eq(string) error = %v, want %v", err, ErrInvalidFilter)
+	}
+}
+
+func TestResolveFieldKind_Pointer(t *testing.T) {
+	tests := []struct {
+		typeName string
+		want     FieldKind
+	}{
+		{"*float64", KindFloat},
+		{"*string", KindString},
+		{"*int32", KindInt},
+		{"*[]string", KindSlice},
+		{"**int", KindUnknown},
+		{"*pkg.Struct", KindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeName, func(t *testing.T) {
+			if got := resolveFieldKind(sentinel.KindPointer, tt.typeName); got != tt.want {
+				t.Errorf("resolveFieldKind(%q) = %v, want %v", tt.typeName, got, tt.want)
+			}
+		})
+	}
+}
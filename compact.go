@@ -0,0 +1,125 @@
+package vecna
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// FromCompact parses a filter in compact array form, a terser alternative
+// to the object form accepted by FromSpec:
+//
+//	["and", ["eq", "category", "tech"], ["gte", "score", 0.8]]
+//
+// The first element is the operator. Field operators take the field and the
+// value, approx a tolerance after those, and is_empty and is_not_empty only
+// the field; raw takes the backend and the payload; and, or, and not take
+// their children as nested arrays; all and none take nothing. Any other shape yields ErrInvalidFilter, prefixed with
+// the offending node's location. The result is then validated as by
+// FromSpec.
+func (b *Builder[T]) FromCompact(data []byte) *Filter {
+	spec, err := b.compactToSpec(data, "", 1)
+	if err != nil {
+		return &Filter{err: err}
+	}
+	return b.FromSpec(spec)
+}
+
+// compactToSpec converts the compact node at path, depth levels deep, into
+// a FilterSpec.
+func (b *Builder[T]) compactToSpec(data json.RawMessage, path string, depth int) (*FilterSpec, error) {
+	if b.maxDepth > 0 && depth > b.maxDepth {
+		return nil, fmt.Errorf("%w: spec exceeds maximum depth of %d", ErrInvalidFilter, b.maxDepth)
+	}
+
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil || len(elems) == 0 {
+		return nil, withPath(path, fmt.Errorf("%w: compact filter must be a non-empty array", ErrInvalidFilter))
+	}
+	var name string
+	if err := json.Unmarshal(elems[0], &name); err != nil {
+		return nil, withPath(path, fmt.Errorf("%w: compact filter must start with an operator string", ErrInvalidFilter))
+	}
+	op, err := parseOp(name)
+	if err != nil {
+		return nil, withPath(path, err)
+	}
+	args := elems[1:]
+	spec := &FilterSpec{Op: name}
+
+	switch op {
+	case And, Or, Not, Xor:
+		spec.Children = make([]*FilterSpec, len(args))
+		for i, arg := range args {
+			if spec.Children[i], err = b.compactToSpec(arg, childPath(path, i), depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return spec, nil
+	case All, None:
+		if len(args) != 0 {
+			return nil, withPath(path, fmt.Errorf("%w: compact %s takes no arguments", ErrInvalidFilter, op))
+		}
+		return spec, nil
+	case Raw:
+		if len(args) != 2 || json.Unmarshal(args[0], &spec.Backend) != nil {
+			return nil, withPath(path, fmt.Errorf("%w: compact %s requires [op, backend, payload]", ErrInvalidFilter, op))
+		}
+		spec.Raw = args[1]
+		return spec, nil
+	}
+
+	want, shape := 2, "[op, field, value]"
+	switch {
+	case op == Approx:
+		want, shape = 3, "[op, field, value, tolerance]"
+	case isValuelessOp(op):
+		want, shape = 1, "[op, field]"
+	}
+	if len(args) != want || json.Unmarshal(args[0], &spec.Field) != nil || spec.Field == "" ||
+		(want > 1 && json.Unmarshal(args[1], &spec.Value) != nil) {
+		return nil, withPath(path, fmt.Errorf("%w: compact %s requires %s", ErrInvalidFilter, op, shape))
+	}
+	if op == Approx && json.Unmarshal(args[2], &spec.Tolerance) != nil {
+		return nil, withPath(path, fmt.Errorf("%w: compact %s requires numeric tolerance", ErrInvalidFilter, op))
+	}
+	return spec, nil
+}
+
+// ToCompact encodes a filter in the compact array form read by FromCompact.
+// Returns the filter's construction error if it has one.
+func (f *Filter) ToCompact() ([]byte, error) {
+	spec, err := f.ToSpec()
+	if err != nil {
+		return nil, err
+	}
+	// Leave <, >, and & unescaped, as raw payloads often hold comparisons
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(specToCompact(spec)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// specToCompact converts a FilterSpec produced by ToSpec into compact form.
+func specToCompact(spec *FilterSpec) []any {
+	node := []any{spec.Op}
+	switch spec.Op {
+	case "and", "or", "not":
+		for _, child := range spec.Children {
+			node = append(node, specToCompact(child))
+		}
+	case "all", "none":
+	case "raw":
+		node = append(node, spec.Backend, spec.Raw)
+	case "approx":
+		node = append(node, spec.Field, spec.Value, spec.Tolerance)
+	case "is_empty", "is_not_empty":
+		node = append(node, spec.Field)
+	default:
+		node = append(node, spec.Field, spec.Value)
+	}
+	return node
+}
@@ -0,0 +1,115 @@
+package vecna
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuilder_FromCompact(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	f := builder.FromCompact([]byte(`["and", ["eq", "category", "tech"], ["gte", "score", 0.8]]`))
+	if f.Err() != nil {
+		t.Fatalf("FromCompact() error = %v", f.Err())
+	}
+	if got, want := f.String(), `(category == "tech" AND score >= 0.8)`; got != want {
+		t.Errorf("FromCompact() = %s, want %s", got, want)
+	}
+}
+
+func TestFilter_ToCompact_RoundTrip(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"leaf", builder.Where("category").Eq("tech"), `["eq","category","tech"]`},
+		{"and", builder.And(builder.Where("category").Eq("tech"), builder.Where("score").Gte(0.8)),
+			`["and",["eq","category","tech"],["gte","score",0.8]]`},
+		{"nested", builder.Or(
+			builder.Where("tags").ContainsAny("go", "db"),
+			builder.Not(builder.Where("category").In("spam", "junk")),
+		), `["or",["contains_any","tags",["go","db"]],["not",["in","category",["spam","junk"]]]]`},
+		{"between", builder.Where("count").Between(1, 10), `["between","count",[1,10]]`},
+		{"approx", builder.Where("score").Approx(0.5, 0.25), `["approx","score",0.5,0.25]`},
+		{"raw", builder.Raw("sql", []byte(`"score > 1"`)), `["raw","sql","score > 1"]`},
+		{"is empty", builder.Where("tags").IsEmpty(), `["is_empty","tags"]`},
+		{"all", builder.All(), `["all"]`},
+		{"none", builder.None(), `["none"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.filter.ToCompact()
+			if err != nil {
+				t.Fatalf("ToCompact() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("ToCompact() = %s, want %s", data, tt.want)
+			}
+
+			rebuilt := builder.FromCompact(data)
+			if rebuilt.Err() != nil {
+				t.Fatalf("FromCompact() error = %v", rebuilt.Err())
+			}
+			if rebuilt.String() != tt.filter.String() {
+				t.Errorf("FromCompact(ToCompact()) = %s, want %s", rebuilt, tt.filter)
+			}
+		})
+	}
+
+	t.Run("invalid filter", func(t *testing.T) {
+		if _, err := builder.Where("missing").Eq(1).ToCompact(); !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("ToCompact() error = %v, want %v", err, ErrFieldNotFound)
+		}
+	})
+}
+
+func TestBuilder_FromCompact_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+		wantMsg string
+	}{
+		{"not json", `["eq"`, ErrInvalidFilter, "vecna: invalid filter: compact filter must be a non-empty array"},
+		{"object", `{"op": "eq"}`, ErrInvalidFilter, "vecna: invalid filter: compact filter must be a non-empty array"},
+		{"empty", `[]`, ErrInvalidFilter, "vecna: invalid filter: compact filter must be a non-empty array"},
+		{"op not string", `[1, "category", "tech"]`, ErrInvalidFilter, "vecna: invalid filter: compact filter must start with an operator string"},
+		{"extra argument", `["eq", "category", "tech", 1]`, ErrInvalidFilter, "vecna: invalid filter: compact eq requires [op, field, value]"},
+		{"missing value", `["and", ["eq", "category"]]`, ErrInvalidFilter, "children[0]: vecna: invalid filter: compact eq requires [op, field, value]"},
+		{"field not string", `["eq", 1, "tech"]`, ErrInvalidFilter, "vecna: invalid filter: compact eq requires [op, field, value]"},
+		{"bad operator", `["or", ["eq", "category", "a"], ["and", ["nand", "category", "b"]]]`, ErrInvalidFilter,
+			`children[1].children[0]: vecna: invalid filter: unknown operator "nand"`},
+		{"approx tolerance", `["approx", "score", 0.5, "wide"]`, ErrInvalidFilter, "vecna: invalid filter: compact approx requires numeric tolerance"},
+		{"is empty with value", `["is_empty", "tags", []]`, ErrInvalidFilter, "vecna: invalid filter: compact is_empty requires [op, field]"},
+		{"all with args", `["all", 1]`, ErrInvalidFilter, "vecna: invalid filter: compact all takes no arguments"},
+		{"unknown field", `["and", ["eq", "category", "a"], ["eq", "missing", 1]]`, ErrFieldNotFound,
+			`children[1].field "missing": vecna: field not found: missing`},
+		{"empty and", `["and"]`, ErrInvalidFilter, "vecna: invalid filter: and requires at least one child"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := builder.FromCompact([]byte(tt.data)).Err()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FromCompact() error = %v, want %v", err, tt.wantErr)
+			}
+			if err.Error() != tt.wantMsg {
+				t.Errorf("FromCompact() error = %q, want %q", err.Error(), tt.wantMsg)
+			}
+		})
+	}
+
+	t.Run("max depth", func(t *testing.T) {
+		shallow, _ := New[testMetadata](WithMaxDepth(2))
+		err := shallow.FromCompact([]byte(`["not", ["not", ["eq", "active", true]]]`)).Err()
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("FromCompact() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package vecna
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Comparable is implemented by field types with a domain-specific order,
+// such as semantic versions. CompareTo returns a negative number, zero, or a
+// positive number as the receiver sorts before, equal to, or after other,
+// which is the filter value as given to Where or decoded from a spec. It
+// returns an error if other cannot be compared.
+//
+// Fields whose type (or pointed-to type) implements Comparable with a value
+// receiver accept Eq, Ne, Gt, Gte, Lt, Lte, Between, In, and Nin with
+// values of any type, and Match evaluates those operators through
+// CompareTo. Backend compilers still see the field's underlying kind.
+type Comparable interface {
+	CompareTo(other any) (int, error)
+}
+
+// comparableType is the reflect.Type of Comparable.
+var comparableType = reflect.TypeFor[Comparable]()
+
+// implementsComparable reports whether values of t, with pointers removed,
+// implement Comparable.
+func implementsComparable(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Implements(comparableType)
+}
+
+// isOrderedOp reports whether op is evaluated through Comparable on fields
+// that implement it.
+func isOrderedOp(op Op) bool {
+	switch op {
+	case Eq, Ne, Gt, Gte, Lt, Lte, Between, In, Nin:
+		return true
+	default:
+		return false
+	}
+}
+
+// evalComparable evaluates an ordered operator against a Comparable actual
+// value. handled is false for operators Comparable does not cover.
+func evalComparable(f *Filter, actual Comparable) (ok, handled bool, err error) {
+	if !isOrderedOp(f.op) {
+		return false, false, nil
+	}
+	compare := func(v any) (int, error) {
+		cmp, err := actual.CompareTo(v)
+		if err != nil {
+			return 0, fmt.Errorf("%w: field %s: %w", ErrInvalidFilter, f.field, err)
+		}
+		return cmp, nil
+	}
+
+	switch f.op {
+	case Between:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return false, true, err
+		}
+		lower, err := compare(low)
+		if err != nil {
+			return false, true, err
+		}
+		upper, err := compare(high)
+		if err != nil {
+			return false, true, err
+		}
+		return lower >= 0 && upper <= 0, true, nil
+	case In, Nin:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return false, true, err
+		}
+		for _, v := range values {
+			cmp, err := compare(v)
+			if err != nil {
+				return false, true, err
+			}
+			if cmp == 0 {
+				return f.op == In, true, nil
+			}
+		}
+		return f.op == Nin, true, nil
+	}
+
+	cmp, err := compare(f.value)
+	if err != nil {
+		return false, true, err
+	}
+	switch f.op {
+	case Eq:
+		return cmp == 0, true, nil
+	case Ne:
+		return cmp != 0, true, nil
+	case Gt:
+		return cmp > 0, true, nil
+	case Gte:
+		return cmp >= 0, true, nil
+	case Lt:
+		return cmp < 0, true, nil
+	default:
+		return cmp <= 0, true, nil
+	}
+}
@@ -0,0 +1,123 @@
+package vecna
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Version is a dotted version string ordered numerically by component.
+type Version string
+
+// CompareTo orders v against a Version or string, padding missing
+// components with zeros.
+func (v Version) CompareTo(other any) (int, error) {
+	var o string
+	switch x := other.(type) {
+	case Version:
+		o = string(x)
+	case string:
+		o = x
+	default:
+		return 0, fmt.Errorf("cannot compare version with %T", other)
+	}
+	a, err := versionParts(string(v))
+	if err != nil {
+		return 0, err
+	}
+	b, err := versionParts(o)
+	if err != nil {
+		return 0, err
+	}
+	for len(a) < len(b) {
+		a = append(a, 0)
+	}
+	for len(b) < len(a) {
+		b = append(b, 0)
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func versionParts(s string) ([]int, error) {
+	parts := strings.Split(s, ".")
+	nums := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", s)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+type releaseMetadata struct {
+	Name    string   `json:"name"`
+	Version Version  `json:"version"`
+	Min     *Version `json:"min"`
+}
+
+func TestBuilder_Match_Comparable(t *testing.T) {
+	builder, err := New[releaseMetadata]()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	minVersion := Version("1.2")
+	doc := releaseMetadata{Name: "vecna", Version: "1.10.0", Min: &minVersion}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   bool
+	}{
+		{"gt numeric order", builder.Where("version").Gt("1.9.0"), true},
+		{"lt numeric order", builder.Where("version").Lt("1.9.0"), false},
+		{"gte equal", builder.Where("version").Gte("1.10"), true},
+		{"lte", builder.Where("version").Lte(Version("2.0.0")), true},
+		{"eq padded", builder.Where("version").Eq("1.10"), true},
+		{"ne", builder.Where("version").Ne("1.10.0"), false},
+		{"between", builder.Where("version").Between("1.9", "1.11"), true},
+		{"between below", builder.Where("version").Between("1.11", "2"), false},
+		{"in", builder.Where("version").In("1.9", "1.10.0.0"), true},
+		{"nin", builder.Where("version").Nin("1.9", "1.10"), false},
+		{"pointer field", builder.Where("min").Lt("1.10"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Err(); err != nil {
+				t.Fatalf("Filter.Err() = %v", err)
+			}
+			got, err := builder.Match(tt.filter, doc)
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("compare error", func(t *testing.T) {
+		_, err := builder.Match(builder.Where("version").Gt("one"), doc)
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("Match() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+
+	t.Run("plain fields still validated", func(t *testing.T) {
+		if err := builder.Where("name").Gt("a").Err(); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("Where(name).Gt() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+}
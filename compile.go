@@ -0,0 +1,153 @@
+package vecna
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Shared helpers for the string-based backend compilers.
+
+// checkCompilable returns an error if f is nil or carries a construction error.
+func checkCompilable(f *Filter) error {
+	if f == nil {
+		return fmt.Errorf("%w: nil filter", ErrInvalidFilter)
+	}
+	return f.Err()
+}
+
+// checkCaseSensitive returns ErrInvalidFilter if any node of f compares
+// case-insensitively, for backends that cannot express it.
+func checkCaseSensitive(f *Filter, backend string) error {
+	return f.Walk(func(n *Filter) error {
+		if n.fold {
+			return fmt.Errorf("%w: case-insensitive %s on field %s not supported by %s", ErrInvalidFilter, n.op, n.field, backend)
+		}
+		return nil
+	})
+}
+
+// checkNoEntries returns ErrInvalidFilter if any node of f addresses a
+// KindMap entry, for backends with no nested path to render it as.
+func checkNoEntries(f *Filter, backend string) error {
+	return f.Walk(func(n *Filter) error {
+		if _, _, ok := n.entry(); ok {
+			return fmt.Errorf("%w: map entry %s not supported by %s", ErrInvalidFilter, n.field, backend)
+		}
+		return nil
+	})
+}
+
+// isGroup reports whether f is a logical group that needs parentheses when nested.
+func isGroup(f *Filter) bool {
+	return f.op == And || f.op == Or
+}
+
+// scalarLiteral renders a numeric or boolean value, or a string or RFC3339
+// time via quote.
+// Any other type is rejected with ErrInvalidFilter.
+func scalarLiteral(value any, quote func(string) string) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return quote(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int8, int16, int32, int64:
+		return strconv.FormatInt(reflect.ValueOf(v).Int(), 10), nil
+	case uint, uint8, uint16, uint32, uint64:
+		return strconv.FormatUint(reflect.ValueOf(v).Uint(), 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return formatFloat(v), nil
+	case time.Time:
+		return quote(v.Format(time.RFC3339Nano)), nil
+	default:
+		return "", fmt.Errorf("%w: unsupported literal type %T", ErrInvalidFilter, value)
+	}
+}
+
+// formatFloat renders a float64 literal in the shortest exact form.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// rangeBounds extracts the inclusive low and high bounds of a Between filter,
+// or of an Approx filter expanded to value ± tolerance.
+func rangeBounds(f *Filter) (low, high any, err error) {
+	values, err := sliceValues(f.value)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(values) != 2 {
+		return nil, nil, fmt.Errorf("%w: %s requires exactly two values, got %d", ErrInvalidFilter, f.op, len(values))
+	}
+	if f.op != Approx {
+		return values[0], values[1], nil
+	}
+
+	center, cok := toFloat64(values[0])
+	tolerance, tok := toFloat64(values[1])
+	if !cok || !tok {
+		return nil, nil, fmt.Errorf("%w: %s requires numeric value and tolerance", ErrInvalidFilter, f.op)
+	}
+	return center - tolerance, center + tolerance, nil
+}
+
+// affixRegex converts a Prefix or Suffix filter into an anchored regular expression.
+func affixRegex(f *Filter) (string, error) {
+	affix, ok := f.value.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+	}
+	if f.op == Prefix {
+		return "^" + regexp.QuoteMeta(affix), nil
+	}
+	return regexp.QuoteMeta(affix) + "$", nil
+}
+
+// checkScalarElements rejects Contains values that are structs, which
+// compilers without structured array elements cannot express.
+func checkScalarElements(f *Filter, backend string) error {
+	values := []any{f.value}
+	if f.op != Contains {
+		var err error
+		if values, err = sliceValues(f.value); err != nil {
+			return err
+		}
+	}
+	for _, v := range values {
+		if t := reflect.TypeOf(v); t != nil && isStructType(t) {
+			return fmt.Errorf("%w: %s on struct elements not supported by %s", ErrInvalidFilter, f.op, backend)
+		}
+	}
+	return nil
+}
+
+// sliceValues flattens a slice value (typed or []any) into []any.
+// A variadic In(typedSlice) call yields []any{typedSlice}; that single
+// nested slice is unwrapped so it behaves like the expanded form.
+func sliceValues(value any) ([]any, error) {
+	if values, ok := value.([]any); ok {
+		if len(values) != 1 {
+			return values, nil
+		}
+		if k := reflect.ValueOf(values[0]).Kind(); k != reflect.Slice && k != reflect.Array {
+			return values, nil
+		}
+		value = values[0]
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%w: expected slice value, got %T", ErrInvalidFilter, value)
+	}
+	values := make([]any, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values, nil
+}
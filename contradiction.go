@@ -0,0 +1,95 @@
+package vecna
+
+import "fmt"
+
+// Contradictions reports conditions that can never hold together, such as
+// category == "a" AND category == "b": within each conjunction (an And,
+// including Ands nested directly inside it), every pair of Eq and In
+// conditions on the same field whose accepted values do not overlap. Each
+// finding reads `<condition> contradicts <condition>`, in filter order.
+// Conjunctions under Or and Not are checked separately.
+//
+// It is a warning tool for logging before a query that will always return
+// nothing; the filter itself stays valid. Values compare as in Match, so
+// Eq(1) and In(1.0) overlap, and a case-insensitive condition overlaps any
+// value equal to it ignoring case. Other operators are not considered.
+// Returns nil when nothing contradicts, and for a filter carrying a
+// construction error.
+func (f *Filter) Contradictions() []string {
+	if f == nil || f.Err() != nil {
+		return nil
+	}
+	var found []string
+	findContradictions(f, &found)
+	return found
+}
+
+// findContradictions checks the conjunction rooted at f, if any, and then
+// every subtree that is not part of it.
+func findContradictions(f *Filter, found *[]string) {
+	if f == nil {
+		return
+	}
+	if f.op != And {
+		for _, child := range f.children {
+			findContradictions(child, found)
+		}
+		return
+	}
+
+	var conjuncts []*Filter
+	collectConjuncts(f, &conjuncts)
+	for i, a := range conjuncts {
+		if a.op != Eq && a.op != In {
+			findContradictions(a, found)
+			continue
+		}
+		for _, b := range conjuncts[i+1:] {
+			if (b.op == Eq || b.op == In) && a.field == b.field && !acceptedOverlap(a, b) {
+				*found = append(*found, fmt.Sprintf("%s contradicts %s", a, b))
+			}
+		}
+	}
+}
+
+// collectConjuncts appends the operands of the And tree rooted at f,
+// looking through nested Ands.
+func collectConjuncts(f *Filter, conjuncts *[]*Filter) {
+	for _, child := range f.children {
+		switch {
+		case child == nil:
+		case child.op == And:
+			collectConjuncts(child, conjuncts)
+		default:
+			*conjuncts = append(*conjuncts, child)
+		}
+	}
+}
+
+// acceptedOverlap reports whether an Eq or In condition a accepts any value
+// that b accepts. Values that cannot be read are assumed to overlap.
+func acceptedOverlap(a, b *Filter) bool {
+	as, aok := acceptedValues(a)
+	bs, bok := acceptedValues(b)
+	if !aok || !bok {
+		return true
+	}
+	fold := &Filter{fold: a.fold || b.fold}
+	for _, x := range as {
+		for _, y := range bs {
+			if valuesMatch(fold, x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// acceptedValues returns the values an Eq or In condition accepts.
+func acceptedValues(f *Filter) ([]any, bool) {
+	if f.op == Eq {
+		return []any{f.value}, true
+	}
+	values, err := sliceValues(f.value)
+	return values, err == nil
+}
@@ -0,0 +1,55 @@
+package vecna
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilter_Contradictions(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	folded, _ := New[testMetadata](WithCaseInsensitive("category"))
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   []string
+	}{
+		{"eq pair", builder.And(builder.Where("category").Eq("a"), builder.Where("category").Eq("b")),
+			[]string{`category == "a" contradicts category == "b"`}},
+		{"same value", builder.And(builder.Where("category").Eq("a"), builder.Where("category").Eq("a")), nil},
+		{"different fields", builder.And(builder.Where("category").Eq("a"), builder.Where("active").Eq(true)), nil},
+		{"numeric types", builder.And(builder.Where("count").Eq(1), builder.Where("count").In(1.0, 2.0)), nil},
+		{"eq outside in", builder.And(builder.Where("category").Eq("c"), builder.Where("category").In("a", "b")),
+			[]string{`category == "c" contradicts category IN ["a", "b"]`}},
+		{"disjoint in", builder.And(builder.Where("category").In("a", "b"), builder.Where("category").In("c")),
+			[]string{`category IN ["a", "b"] contradicts category IN ["c"]`}},
+		{"overlapping in", builder.And(builder.Where("category").In("a", "b"), builder.Where("category").In("b", "c")), nil},
+		{"nested and", builder.And(builder.Where("count").Eq(1), builder.And(builder.Where("score").Gt(0.5), builder.Where("count").Eq(2))),
+			[]string{`count == 1 contradicts count == 2`}},
+		{"across or", builder.Or(builder.Where("category").Eq("a"), builder.Where("category").Eq("b")), nil},
+		{"inside or", builder.Or(
+			builder.Where("active").Eq(true),
+			builder.And(builder.Where("category").Eq("a"), builder.Where("category").Eq("b")),
+		), []string{`category == "a" contradicts category == "b"`}},
+		{"inside not", builder.And(
+			builder.Where("category").Eq("a"),
+			builder.Not(builder.And(builder.Where("count").Eq(1), builder.Where("count").Eq(2))),
+		), []string{`count == 1 contradicts count == 2`}},
+		{"case-insensitive", folded.And(folded.Where("category").Eq("Tech"), builder.Where("category").Eq("TECH")), nil},
+		{"case-sensitive", builder.And(builder.Where("category").Eq("Tech"), builder.Where("category").Eq("TECH")),
+			[]string{`category == "Tech" contradicts category == "TECH"`}},
+		{"other operators", builder.And(builder.Where("count").Eq(1), builder.Where("count").Gt(5)), nil},
+		{"three way", builder.And(builder.Where("count").Eq(1), builder.Where("count").Eq(2), builder.Where("count").In(1, 3)),
+			[]string{`count == 1 contradicts count == 2`, `count == 2 contradicts count IN [1, 3]`}},
+		{"construction error", builder.And(builder.Where("missing").Eq(1), builder.Where("count").Eq(2)), nil},
+		{"nil", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Contradictions(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Contradictions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package vecna
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CompileToCypher compiles a filter into a Neo4j Cypher WHERE-clause body
+// over the properties of the node bound to nodeVar, such as
+// n.category = $p0 AND n.score >= $p1 AND n.category IN $p2. Values are
+// never interpolated; they are returned in the params map under the names
+// p0, p1, ... referenced by the clause. Property and variable names that
+// are not plain identifiers are backtick-quoted.
+//
+// In and Nin map to IN and its negation, and on list properties Contains
+// maps to $p IN n.tags, ContainsAny and ContainsAll to any() and all() list
+// predicates, and IsEmpty, IsNotEmpty, and Len to size(). StartsWith,
+// EndsWith, and Like patterns of the form %text% map to STARTS WITH,
+// ENDS WITH, and CONTAINS; other Like patterns and Regex use =~, which
+// matches the whole string, so Regex patterns are wrapped to match
+// anywhere as in Match. And, Or, and Xor map to AND, OR, and XOR, and Not
+// to NOT (...). All and None render as true and false.
+//
+// Raw filters for backend "cypher" are emitted verbatim in parentheses.
+// Case-insensitive comparisons return ErrInvalidFilter, as does any node
+// with a construction error.
+func CompileToCypher(f *Filter, nodeVar string) (string, map[string]any, error) {
+	if err := checkCompilable(f); err != nil {
+		return "", nil, err
+	}
+	if err := checkCaseSensitive(f, "Cypher"); err != nil {
+		return "", nil, err
+	}
+	if err := checkNoEntries(f, "Cypher"); err != nil {
+		return "", nil, err
+	}
+	if nodeVar == "" {
+		return "", nil, fmt.Errorf("%w: cypher requires a node variable", ErrInvalidFilter)
+	}
+
+	c := &cypherCompiler{node: cypherIdent(nodeVar), params: map[string]any{}}
+	clause, err := c.compile(f)
+	if err != nil {
+		return "", nil, err
+	}
+	return clause, c.params, nil
+}
+
+// cypherCompiler accumulates named parameters while rendering a filter tree.
+type cypherCompiler struct {
+	node   string
+	params map[string]any
+}
+
+// bind adds a value to the params map and returns its $pN reference.
+func (c *cypherCompiler) bind(value any) string {
+	name := "p" + strconv.Itoa(len(c.params))
+	c.params[name] = value
+	return "$" + name
+}
+
+// property renders the property access for a field.
+func (c *cypherCompiler) property(field string) string {
+	return c.node + "." + cypherIdent(field)
+}
+
+// compile renders a single filter node.
+func (c *cypherCompiler) compile(f *Filter) (string, error) {
+	switch f.op {
+	case And, Or, Xor:
+		if f.op == Xor && len(f.children) != 2 {
+			return "", fmt.Errorf("%w: %s requires exactly two children", ErrInvalidFilter, f.op)
+		}
+		if len(f.children) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(f.children))
+		for i, child := range f.children {
+			part, err := c.compile(child)
+			if err != nil {
+				return "", err
+			}
+			if isGroup(child) || child.op == Xor {
+				part = "(" + part + ")"
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, " "+strings.ToUpper(f.op.String())+" "), nil
+	case Not:
+		if len(f.children) != 1 {
+			return "", fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := c.compile(f.children[0])
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + inner + ")", nil
+	case All:
+		return "true", nil
+	case None:
+		return "false", nil
+	case Raw:
+		return rawClause(f, "cypher")
+	}
+
+	prop := c.property(f.field)
+
+	switch f.op {
+	case Eq:
+		return prop + " = " + c.bind(f.value), nil
+	case Ne:
+		return prop + " <> " + c.bind(f.value), nil
+	case Gt:
+		return prop + " > " + c.bind(f.value), nil
+	case Gte:
+		return prop + " >= " + c.bind(f.value), nil
+	case Lt:
+		return prop + " < " + c.bind(f.value), nil
+	case Lte:
+		return prop + " <= " + c.bind(f.value), nil
+	case In, Nin:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		if f.op == Nin {
+			return "NOT (" + prop + " IN " + c.bind(values) + ")", nil
+		}
+		return prop + " IN " + c.bind(values), nil
+	case Contains:
+		return c.bind(f.value) + " IN " + prop, nil
+	case ContainsAny, ContainsAll:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		elem := "x"
+		if c.node == elem {
+			elem = "y"
+		}
+		fn := "any"
+		if f.op == ContainsAll {
+			fn = "all"
+		}
+		return fn + "(" + elem + " IN " + c.bind(values) + " WHERE " + elem + " IN " + prop + ")", nil
+	case IsEmpty:
+		return "size(" + prop + ") = 0", nil
+	case IsNotEmpty:
+		return "size(" + prop + ") > 0", nil
+	case Len:
+		return lenClause(f, "size("+prop+")", c.bind)
+	case Prefix:
+		return prop + " STARTS WITH " + c.bind(f.value), nil
+	case Suffix:
+		return prop + " ENDS WITH " + c.bind(f.value), nil
+	case Like, NotLike:
+		pattern, ok := f.value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		clause := c.like(prop, pattern)
+		if f.op == NotLike {
+			return "NOT (" + clause + ")", nil
+		}
+		return clause, nil
+	case ILike:
+		pattern, ok := f.value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		return prop + " =~ " + c.bind("(?is)"+likeRegex(pattern)), nil
+	case Regex:
+		pattern, ok := f.value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		return prop + " =~ " + c.bind("(?s).*(?:"+pattern+").*"), nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return "", err
+		}
+		return "(" + prop + " >= " + c.bind(low) + " AND " + prop + " <= " + c.bind(high) + ")", nil
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return "", err
+		}
+		lng := c.property(box.LngField)
+		return "(" + prop + " >= " + c.bind(box.MinLat) + " AND " + prop + " <= " + c.bind(box.MaxLat) +
+			" AND " + lng + " >= " + c.bind(box.MinLng) + " AND " + lng + " <= " + c.bind(box.MaxLng) + ")", nil
+	default:
+		return "", fmt.Errorf("%w: operator %s not supported by Cypher", ErrInvalidFilter, f.op)
+	}
+}
+
+// like renders a Like pattern, using STARTS WITH, ENDS WITH, or CONTAINS
+// when the pattern is a literal with % at one or both ends, and =~ with
+// the equivalent regular expression otherwise.
+func (c *cypherCompiler) like(prop, pattern string) string {
+	inner := strings.TrimSuffix(strings.TrimPrefix(pattern, "%"), "%")
+	leading, trailing := strings.HasPrefix(pattern, "%"), strings.HasSuffix(pattern, "%") && len(pattern) > 1
+	if !strings.ContainsAny(inner, "%_") {
+		switch {
+		case leading && trailing:
+			return prop + " CONTAINS " + c.bind(inner)
+		case trailing:
+			return prop + " STARTS WITH " + c.bind(inner)
+		case leading:
+			return prop + " ENDS WITH " + c.bind(inner)
+		default:
+			return prop + " = " + c.bind(inner)
+		}
+	}
+	return prop + " =~ " + c.bind("(?s)"+likeRegex(pattern))
+}
+
+// cypherIdentPattern matches names that need no quoting in Cypher.
+var cypherIdentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// cypherIdent returns name as is if it is a plain identifier, and
+// backtick-quoted with embedded backticks doubled otherwise.
+func cypherIdent(name string) string {
+	if cypherIdentPattern.MatchString(name) {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,142 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCompileToCypher(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name       string
+		filter     *Filter
+		wantClause string
+		wantParams map[string]any
+	}{
+		{"eq", builder.Where("category").Eq("tech"), "n.category = $p0", map[string]any{"p0": "tech"}},
+		{"ne", builder.Where("category").Ne("tech"), "n.category <> $p0", map[string]any{"p0": "tech"}},
+		{"gt", builder.Where("score").Gt(0.5), "n.score > $p0", map[string]any{"p0": 0.5}},
+		{"gte", builder.Where("score").Gte(0.5), "n.score >= $p0", map[string]any{"p0": 0.5}},
+		{"lt", builder.Where("count").Lt(10), "n.count < $p0", map[string]any{"p0": 10}},
+		{"lte", builder.Where("count").Lte(10), "n.count <= $p0", map[string]any{"p0": 10}},
+		{"in", builder.Where("category").In("a", "b"), "n.category IN $p0", map[string]any{"p0": []any{"a", "b"}}},
+		{"nin", builder.Where("category").Nin("a", "b"), "NOT (n.category IN $p0)", map[string]any{"p0": []any{"a", "b"}}},
+		{"contains", builder.Where("tags").Contains("go"), "$p0 IN n.tags", map[string]any{"p0": "go"}},
+		{"contains any", builder.Where("tags").ContainsAny("go", "db"), "any(x IN $p0 WHERE x IN n.tags)", map[string]any{"p0": []any{"go", "db"}}},
+		{"contains all", builder.Where("tags").ContainsAll("go", "db"), "all(x IN $p0 WHERE x IN n.tags)", map[string]any{"p0": []any{"go", "db"}}},
+		{"is empty", builder.Where("tags").IsEmpty(), "size(n.tags) = 0", map[string]any{}},
+		{"len", builder.Where("tags").Len(Gte, 2), "size(n.tags) >= $p0", map[string]any{"p0": 2}},
+		{"prefix", builder.Where("category").StartsWith("te"), "n.category STARTS WITH $p0", map[string]any{"p0": "te"}},
+		{"suffix", builder.Where("category").EndsWith("ch"), "n.category ENDS WITH $p0", map[string]any{"p0": "ch"}},
+		{"like contains", builder.Where("category").Like("%ec%"), "n.category CONTAINS $p0", map[string]any{"p0": "ec"}},
+		{"like prefix", builder.Where("category").Like("te%"), "n.category STARTS WITH $p0", map[string]any{"p0": "te"}},
+		{"like suffix", builder.Where("category").Like("%ch"), "n.category ENDS WITH $p0", map[string]any{"p0": "ch"}},
+		{"like regex", builder.Where("category").Like("t_ch%"), "n.category =~ $p0", map[string]any{"p0": "(?s)" + likeRegex("t_ch%")}},
+		{"not like", builder.Where("category").NotLike("%spam%"), "NOT (n.category CONTAINS $p0)", map[string]any{"p0": "spam"}},
+		{"ilike", builder.Where("category").ILike("TE%"), "n.category =~ $p0", map[string]any{"p0": "(?is)" + likeRegex("TE%")}},
+		{"regex", builder.Where("category").Regex("^te"), "n.category =~ $p0", map[string]any{"p0": "(?s).*(?:^te).*"}},
+		{"between", builder.Where("score").Between(0.2, 0.8), "(n.score >= $p0 AND n.score <= $p1)", map[string]any{"p0": 0.2, "p1": 0.8}},
+		{"untagged property", builder.Where("NoTag").Eq("x"), "n.NoTag = $p0", map[string]any{"p0": "x"}},
+		{"all", builder.All(), "true", map[string]any{}},
+		{"none", builder.None(), "false", map[string]any{}},
+		{"raw", builder.Raw("cypher", json.RawMessage(`"n:Featured"`)), "(n:Featured)", map[string]any{}},
+		{
+			"and",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Where("score").Gte(0.5),
+				builder.Where("category").In("a", "b"),
+			),
+			"n.category = $p0 AND n.score >= $p1 AND n.category IN $p2",
+			map[string]any{"p0": "tech", "p1": 0.5, "p2": []any{"a", "b"}},
+		},
+		{
+			"nested",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Or(
+					builder.Where("score").Gt(0.5),
+					builder.Not(builder.Where("active").Eq(false)),
+				),
+			),
+			"n.category = $p0 AND (n.score > $p1 OR NOT (n.active = $p2))",
+			map[string]any{"p0": "tech", "p1": 0.5, "p2": false},
+		},
+		{
+			"xor",
+			builder.Xor(builder.Where("active").Eq(true), builder.Where("score").Gt(0.5)),
+			"n.active = $p0 XOR n.score > $p1",
+			map[string]any{"p0": true, "p1": 0.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clause, params, err := CompileToCypher(tt.filter, "n")
+			if err != nil {
+				t.Fatalf("CompileToCypher() error = %v", err)
+			}
+			if clause != tt.wantClause {
+				t.Errorf("CompileToCypher() clause = %s, want %s", clause, tt.wantClause)
+			}
+			if !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("CompileToCypher() params = %v, want %v", params, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestCompileToCypher_Identifiers(t *testing.T) {
+	type odd struct {
+		Label string `json:"doc label"`
+	}
+	builder, _ := New[odd]()
+
+	clause, _, err := CompileToCypher(builder.Where("doc label").Eq("a"), "my node")
+	if err != nil {
+		t.Fatalf("CompileToCypher() error = %v", err)
+	}
+	if want := "`my node`.`doc label` = $p0"; clause != want {
+		t.Errorf("CompileToCypher() clause = %s, want %s", clause, want)
+	}
+
+	tags, _ := New[testMetadata]()
+	clause, _, err = CompileToCypher(tags.Where("tags").ContainsAny("go"), "x")
+	if err != nil {
+		t.Fatalf("CompileToCypher() error = %v", err)
+	}
+	if want := "any(y IN $p0 WHERE y IN x.tags)"; clause != want {
+		t.Errorf("CompileToCypher() clause = %s, want %s", clause, want)
+	}
+}
+
+func TestCompileToCypher_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	folded, _ := New[testMetadata](WithCaseInsensitive("category"))
+	attributed, _ := New[attributedMetadata]()
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		nodeVar string
+		wantErr error
+	}{
+		{"filter error", builder.Where("missing").Eq("x"), "n", ErrFieldNotFound},
+		{"nil filter", nil, "n", ErrInvalidFilter},
+		{"empty node variable", builder.Where("category").Eq("tech"), "", ErrInvalidFilter},
+		{"case insensitive", folded.Where("category").Eq("Tech"), "n", ErrInvalidFilter},
+		{"other raw backend", builder.Raw("sql", json.RawMessage(`"1 = 1"`)), "n", ErrInvalidFilter},
+		{"map entry", attributed.Where("attributes.color").Eq("red"), "n", ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := CompileToCypher(tt.filter, tt.nodeVar); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CompileToCypher() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
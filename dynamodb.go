@@ -0,0 +1,201 @@
+package vecna
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CompileToDynamoDB compiles a filter into an Amazon DynamoDB
+// FilterExpression. Attribute names are referenced through #n0, #n1, ...
+// placeholders, since many field names collide with DynamoDB reserved
+// words, and values through :v0, :v1, ...; the returned maps are meant for
+// ExpressionAttributeNames and ExpressionAttributeValues (after marshaling
+// the values, e.g. with attributevalue.MarshalMap). Times are passed as
+// RFC3339 strings.
+//
+// Comparisons map to =, <>, <, <=, >, >=; Between and Approx to BETWEEN;
+// In to IN (...); StartsWith to begins_with; Contains, ContainsAll, and
+// ContainsAny to contains() on the list attribute. Ne is rendered as
+// (attribute_not_exists(#n) OR #n <> :v) and Nin as NOT (#n IN (...)), so
+// items lacking the attribute match, as they do in Match. Like, Regex, and
+// EndsWith cannot be expressed and return ErrInvalidFilter, as do All and
+// None, since FilterExpression has no boolean literal; omit the
+// FilterExpression for All.
+func CompileToDynamoDB(f *Filter) (expr string, names map[string]string, values map[string]any, err error) {
+	if err := checkCompilable(f); err != nil {
+		return "", nil, nil, err
+	}
+	if err := checkCaseSensitive(f, "DynamoDB"); err != nil {
+		return "", nil, nil, err
+	}
+
+	c := &dynamoCompiler{
+		names:    make(map[string]string),
+		values:   make(map[string]any),
+		nameRefs: make(map[string]string),
+	}
+	expr, err = c.compile(f)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return expr, c.names, c.values, nil
+}
+
+// dynamoCompiler accumulates attribute name and value placeholders while
+// rendering a filter tree.
+type dynamoCompiler struct {
+	names    map[string]string // placeholder -> attribute name
+	values   map[string]any    // placeholder -> value
+	nameRefs map[string]string // attribute name -> placeholder, for reuse
+}
+
+// name returns the placeholder for an attribute, allocating one on first use.
+func (c *dynamoCompiler) name(field string) string {
+	if ref, ok := c.nameRefs[field]; ok {
+		return ref
+	}
+	ref := "#n" + strconv.Itoa(len(c.names))
+	c.names[ref] = field
+	c.nameRefs[field] = ref
+	return ref
+}
+
+// path returns the document path of the filter's attribute: its
+// placeholder, or for a KindMap entry such as "attributes.color", the map's
+// placeholder dotted with the key's, as in #n0.#n1.
+func (c *dynamoCompiler) path(f *Filter) string {
+	if field, key, ok := f.entry(); ok {
+		return c.name(field) + "." + c.name(key)
+	}
+	return c.name(f.field)
+}
+
+// value binds a value and returns its placeholder.
+func (c *dynamoCompiler) value(v any) string {
+	if t, ok := v.(time.Time); ok {
+		v = t.Format(time.RFC3339Nano)
+	}
+	ref := ":v" + strconv.Itoa(len(c.values))
+	c.values[ref] = v
+	return ref
+}
+
+// valueList binds each value and returns a comma-separated placeholder list.
+func (c *dynamoCompiler) valueList(values []any) string {
+	refs := make([]string, len(values))
+	for i, v := range values {
+		refs[i] = c.value(v)
+	}
+	return strings.Join(refs, ", ")
+}
+
+// compile renders a single filter node.
+func (c *dynamoCompiler) compile(f *Filter) (string, error) {
+	switch f.op {
+	case And, Or:
+		if len(f.children) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(f.children))
+		for i, child := range f.children {
+			part, err := c.compile(child)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return "(" + strings.Join(parts, " "+strings.ToUpper(f.op.String())+" ") + ")", nil
+	case Not:
+		if len(f.children) != 1 {
+			return "", fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := c.compile(f.children[0])
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + inner + ")", nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return "", err
+		}
+		return c.compile(expanded)
+	case Raw:
+		return rawClause(f, "dynamodb")
+	}
+
+	name := c.path(f)
+
+	switch f.op {
+	case Eq:
+		return name + " = " + c.value(f.value), nil
+	case Ne:
+		return "(attribute_not_exists(" + name + ") OR " + name + " <> " + c.value(f.value) + ")", nil
+	case Gt:
+		return name + " > " + c.value(f.value), nil
+	case Gte:
+		return name + " >= " + c.value(f.value), nil
+	case Lt:
+		return name + " < " + c.value(f.value), nil
+	case Lte:
+		return name + " <= " + c.value(f.value), nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return "", err
+		}
+		return name + " BETWEEN " + c.value(low) + " AND " + c.value(high), nil
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return "", err
+		}
+		lng := c.name(box.LngField)
+		return "(" + name + " BETWEEN " + c.value(box.MinLat) + " AND " + c.value(box.MaxLat) +
+			" AND " + lng + " BETWEEN " + c.value(box.MinLng) + " AND " + c.value(box.MaxLng) + ")", nil
+	case In, Nin:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		if len(values) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one value", ErrInvalidFilter, f.op)
+		}
+		clause := name + " IN (" + c.valueList(values) + ")"
+		if f.op == Nin {
+			return "NOT (" + clause + ")", nil
+		}
+		return clause, nil
+	case Prefix:
+		return "begins_with(" + name + ", " + c.value(f.value) + ")", nil
+	case Contains:
+		return "contains(" + name + ", " + c.value(f.value) + ")", nil
+	case IsEmpty:
+		return "size(" + name + ") = " + c.value(0), nil
+	case IsNotEmpty:
+		return "size(" + name + ") > " + c.value(0), nil
+	case Len:
+		return lenClause(f, "size("+name+")", c.value)
+	case ContainsAll, ContainsAny:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		if len(values) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one value", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(values))
+		for i, v := range values {
+			parts[i] = "contains(" + name + ", " + c.value(v) + ")"
+		}
+		sep := " AND "
+		if f.op == ContainsAny {
+			sep = " OR "
+		}
+		return "(" + strings.Join(parts, sep) + ")", nil
+	default:
+		return "", fmt.Errorf("%w: operator %s not supported by DynamoDB", ErrInvalidFilter, f.op)
+	}
+}
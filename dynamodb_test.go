@@ -0,0 +1,146 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompileToDynamoDB(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name       string
+		filter     *Filter
+		wantExpr   string
+		wantValues map[string]any
+	}{
+		{"eq", builder.Where("category").Eq("tech"), `#n0 = :v0`, map[string]any{":v0": "tech"}},
+		{"ne", builder.Where("category").Ne("tech"), `(attribute_not_exists(#n0) OR #n0 <> :v0)`, map[string]any{":v0": "tech"}},
+		{"gt", builder.Where("score").Gt(0.5), `#n0 > :v0`, map[string]any{":v0": 0.5}},
+		{"lte", builder.Where("count").Lte(10), `#n0 <= :v0`, map[string]any{":v0": 10}},
+		{"between", builder.Where("count").Between(1, 5), `#n0 BETWEEN :v0 AND :v1`, map[string]any{":v0": 1, ":v1": 5}},
+		{"approx", builder.Where("score").Approx(0.5, 0.25), `#n0 BETWEEN :v0 AND :v1`, map[string]any{":v0": 0.25, ":v1": 0.75}},
+		{"in", builder.Where("category").In("a", "b"), `#n0 IN (:v0, :v1)`, map[string]any{":v0": "a", ":v1": "b"}},
+		{"nin", builder.Where("category").Nin("a"), `NOT (#n0 IN (:v0))`, map[string]any{":v0": "a"}},
+		{"prefix", builder.Where("category").StartsWith("te"), `begins_with(#n0, :v0)`, map[string]any{":v0": "te"}},
+		{"contains", builder.Where("tags").Contains("go"), `contains(#n0, :v0)`, map[string]any{":v0": "go"}},
+		{"is empty", builder.Where("tags").IsEmpty(), `size(#n0) = :v0`, map[string]any{":v0": 0}},
+		{"is not empty", builder.Where("tags").IsNotEmpty(), `size(#n0) > :v0`, map[string]any{":v0": 0}},
+		{"contains all", builder.Where("tags").ContainsAll("go", "db"), `(contains(#n0, :v0) AND contains(#n0, :v1))`, map[string]any{":v0": "go", ":v1": "db"}},
+		{"contains any", builder.Where("tags").ContainsAny("go", "db"), `(contains(#n0, :v0) OR contains(#n0, :v1))`, map[string]any{":v0": "go", ":v1": "db"}},
+		{"not", builder.Not(builder.Where("active").Eq(true)), `NOT (#n0 = :v0)`, map[string]any{":v0": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, names, values, err := CompileToDynamoDB(tt.filter)
+			if err != nil {
+				t.Fatalf("CompileToDynamoDB() error = %v", err)
+			}
+			if expr != tt.wantExpr {
+				t.Errorf("CompileToDynamoDB() expr = %s, want %s", expr, tt.wantExpr)
+			}
+			if want := map[string]string{"#n0": tt.filter.Field()}; tt.filter.Op() != Not && !reflect.DeepEqual(names, want) {
+				t.Errorf("CompileToDynamoDB() names = %v, want %v", names, want)
+			}
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("CompileToDynamoDB() values = %v, want %v", values, tt.wantValues)
+			}
+		})
+	}
+}
+
+func TestCompileToDynamoDB_Nested(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.And(
+		builder.Where("category").Eq("tech"),
+		builder.Or(
+			builder.Where("score").Gte(0.5),
+			builder.Where("category").In("ai", "ml"),
+		),
+		builder.Not(builder.Where("tags").Contains("draft")),
+	)
+
+	expr, names, values, err := CompileToDynamoDB(filter)
+	if err != nil {
+		t.Fatalf("CompileToDynamoDB() error = %v", err)
+	}
+
+	wantExpr := `(#n0 = :v0 AND (#n1 >= :v1 OR #n0 IN (:v2, :v3)) AND NOT (contains(#n2, :v4)))`
+	if expr != wantExpr {
+		t.Errorf("CompileToDynamoDB() expr = %s, want %s", expr, wantExpr)
+	}
+	wantNames := map[string]string{"#n0": "category", "#n1": "score", "#n2": "tags"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("CompileToDynamoDB() names = %v, want %v", names, wantNames)
+	}
+	wantValues := map[string]any{":v0": "tech", ":v1": 0.5, ":v2": "ai", ":v3": "ml", ":v4": "draft"}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("CompileToDynamoDB() values = %v, want %v", values, wantValues)
+	}
+}
+
+func TestCompileToDynamoDB_MapEntry(t *testing.T) {
+	builder, _ := New[attributedMetadata]()
+
+	expr, names, values, err := CompileToDynamoDB(builder.Where("attributes.color").Eq("red"))
+	if err != nil {
+		t.Fatalf("CompileToDynamoDB() error = %v", err)
+	}
+	if want := "#n0.#n1 = :v0"; expr != want {
+		t.Errorf("CompileToDynamoDB() expr = %s, want %s", expr, want)
+	}
+	wantNames := map[string]string{"#n0": "attributes", "#n1": "color"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("CompileToDynamoDB() names = %v, want %v", names, wantNames)
+	}
+	if want := map[string]any{":v0": "red"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("CompileToDynamoDB() values = %v, want %v", values, want)
+	}
+}
+
+func TestCompileToDynamoDB_Time(t *testing.T) {
+	builder, _ := New[eventMetadata]()
+	since := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	_, _, values, err := CompileToDynamoDB(builder.Where("created_at").Gte(since))
+	if err != nil {
+		t.Fatalf("CompileToDynamoDB() error = %v", err)
+	}
+	if values[":v0"] != "2024-06-01T00:00:00Z" {
+		t.Errorf("CompileToDynamoDB() values = %v, want RFC3339 string", values)
+	}
+}
+
+func TestCompileToDynamoDB_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   error
+	}{
+		{"filter error", builder.Where("missing").Eq("x"), ErrFieldNotFound},
+		{"nil filter", nil, ErrInvalidFilter},
+		{"all", builder.All(), ErrInvalidFilter},
+		{"none", builder.None(), ErrInvalidFilter},
+		{"empty group", builder.Or(), ErrInvalidFilter},
+		{"like", builder.Where("category").Like("te%"), ErrInvalidFilter},
+		{"regex", builder.Where("category").Regex("^a"), ErrInvalidFilter},
+		{"suffix", builder.Where("category").EndsWith("ch"), ErrInvalidFilter},
+		{"raw other backend", builder.Raw("sql", json.RawMessage(`"x"`)), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := CompileToDynamoDB(tt.filter)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CompileToDynamoDB() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package vecna
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToElasticsearch compiles the filter into an Elasticsearch/OpenSearch query
+// DSL object, such as {"bool": {"must": [{"term": {"category": "tech"}}]}},
+// for use as the query or the filter clause of a bool or knn query. Values
+// are passed through unconverted for the client to encode.
+//
+// And maps to bool.must, Or to bool.should with minimum_should_match 1, and
+// Not to bool.must_not; All and None map to match_all and match_none. Eq
+// and Contains map to term, In and ContainsAny to terms, ContainsAll to a
+// bool.must of terms, and Ne and Nin to the negated term and terms. Gt,
+// Gte, Lt, and Lte map to range, as do Between and Approx (inclusive) and
+// GeoBox (one range per field). Like and ILike map to wildcard with % as *
+// and _ as ?, ILike setting case_insensitive; NotLike negates it,
+// StartsWith maps to prefix, and EndsWith to a leading-* wildcard. Regex
+// maps to regexp; Lucene regular expressions are always anchored and
+// support a smaller syntax than Go's, so patterns are the caller's to
+// adapt. IsNotEmpty maps to exists and IsEmpty to its negation, which also
+// matches documents lacking the field. Len has no equivalent and returns
+// ErrInvalidFilter.
+//
+// Raw filters for backend "elasticsearch" carry a JSON query object that is
+// emitted as is. Case-insensitive comparisons return ErrInvalidFilter, as
+// does any node with a construction error.
+func (f *Filter) ToElasticsearch() (map[string]any, error) {
+	if err := checkCompilable(f); err != nil {
+		return nil, err
+	}
+	if err := checkCaseSensitive(f, "Elasticsearch"); err != nil {
+		return nil, err
+	}
+	return compileElasticsearch(f)
+}
+
+// compileElasticsearch renders a single filter node.
+func compileElasticsearch(f *Filter) (map[string]any, error) {
+	switch f.op {
+	case And, Or:
+		if len(f.children) == 0 {
+			return nil, fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		clauses := make([]any, len(f.children))
+		for i, child := range f.children {
+			clause, err := compileElasticsearch(child)
+			if err != nil {
+				return nil, err
+			}
+			clauses[i] = clause
+		}
+		if f.op == And {
+			return esBool("must", clauses...), nil
+		}
+		return map[string]any{"bool": map[string]any{"should": clauses, "minimum_should_match": 1}}, nil
+	case Not:
+		if len(f.children) != 1 {
+			return nil, fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := compileElasticsearch(f.children[0])
+		if err != nil {
+			return nil, err
+		}
+		return esBool("must_not", inner), nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return nil, err
+		}
+		return compileElasticsearch(expanded)
+	case All:
+		return map[string]any{"match_all": map[string]any{}}, nil
+	case None:
+		return map[string]any{"match_none": map[string]any{}}, nil
+	case Raw:
+		return rawObject(f, "elasticsearch")
+	case Eq, Contains:
+		return esQuery("term", f.field, f.value), nil
+	case Ne:
+		return esBool("must_not", esQuery("term", f.field, f.value)), nil
+	case Gt, Gte, Lt, Lte:
+		return esQuery("range", f.field, map[string]any{f.op.String(): f.value}), nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return nil, err
+		}
+		return esQuery("range", f.field, map[string]any{"gte": low, "lte": high}), nil
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return nil, err
+		}
+		return esBool("must",
+			esQuery("range", f.field, map[string]any{"gte": box.MinLat, "lte": box.MaxLat}),
+			esQuery("range", box.LngField, map[string]any{"gte": box.MinLng, "lte": box.MaxLng}),
+		), nil
+	case In, Nin, ContainsAny, ContainsAll:
+		return esTerms(f)
+	case Like, ILike, NotLike:
+		pattern, ok := f.value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		params := map[string]any{"value": esWildcard(pattern)}
+		switch f.op {
+		case ILike:
+			params["case_insensitive"] = true
+		case NotLike:
+			return esBool("must_not", esQuery("wildcard", f.field, params)), nil
+		}
+		return esQuery("wildcard", f.field, params), nil
+	case Prefix, Suffix:
+		affix, ok := f.value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		if f.op == Prefix {
+			return esQuery("prefix", f.field, affix), nil
+		}
+		return esQuery("wildcard", f.field, map[string]any{"value": "*" + esWildcardEscaper.Replace(affix)}), nil
+	case Regex:
+		pattern, ok := f.value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		return esQuery("regexp", f.field, map[string]any{"value": pattern}), nil
+	case IsNotEmpty:
+		return map[string]any{"exists": map[string]any{"field": f.field}}, nil
+	case IsEmpty:
+		return esBool("must_not", map[string]any{"exists": map[string]any{"field": f.field}}), nil
+	default:
+		return nil, fmt.Errorf("%w: operator %s not supported by Elasticsearch", ErrInvalidFilter, f.op)
+	}
+}
+
+// esTerms renders the list operators: terms for In and ContainsAny, its
+// negation for Nin, and one term per value for ContainsAll.
+func esTerms(f *Filter) (map[string]any, error) {
+	values, err := sliceValues(f.value)
+	if err != nil {
+		return nil, err
+	}
+	switch f.op {
+	case Nin:
+		return esBool("must_not", esQuery("terms", f.field, values)), nil
+	case ContainsAll:
+		clauses := make([]any, len(values))
+		for i, v := range values {
+			clauses[i] = esQuery("term", f.field, v)
+		}
+		return esBool("must", clauses...), nil
+	default:
+		return esQuery("terms", f.field, values), nil
+	}
+}
+
+// esQuery renders a leaf query of the given type on a single field.
+func esQuery(kind, field string, params any) map[string]any {
+	return map[string]any{kind: map[string]any{field: params}}
+}
+
+// esBool renders a bool query with a single occurrence type.
+func esBool(occur string, clauses ...any) map[string]any {
+	return map[string]any{"bool": map[string]any{occur: clauses}}
+}
+
+// esWildcardEscaper escapes the wildcard query metacharacters.
+var esWildcardEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`)
+
+// esWildcard converts a LIKE pattern into a wildcard query pattern.
+func esWildcard(pattern string) string {
+	var sb strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteByte('*')
+		case '_':
+			sb.WriteByte('?')
+		default:
+			sb.WriteString(esWildcardEscaper.Replace(string(r)))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,136 @@
+package vecna
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFilter_ToElasticsearch(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	tech := builder.Where("category").Eq("tech")
+	techTerm := map[string]any{"term": map[string]any{"category": "tech"}}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   map[string]any
+	}{
+		{"eq", tech, techTerm},
+		{"ne", builder.Where("category").Ne("spam"), map[string]any{"bool": map[string]any{"must_not": []any{
+			map[string]any{"term": map[string]any{"category": "spam"}},
+		}}}},
+		{"gte", builder.Where("score").Gte(0.5), map[string]any{"range": map[string]any{"score": map[string]any{"gte": 0.5}}}},
+		{"between", builder.Where("count").Between(1, 5), map[string]any{"range": map[string]any{"count": map[string]any{"gte": 1, "lte": 5}}}},
+		{"in", builder.Where("category").In("a", "b"), map[string]any{"terms": map[string]any{"category": []any{"a", "b"}}}},
+		{"nin", builder.Where("count").Nin(1, 2), map[string]any{"bool": map[string]any{"must_not": []any{
+			map[string]any{"terms": map[string]any{"count": []any{1, 2}}},
+		}}}},
+		{"like", builder.Where("category").Like("te_h%"), map[string]any{"wildcard": map[string]any{"category": map[string]any{"value": "te?h*"}}}},
+		{"like escaped", builder.Where("category").Like(`a*b?%`), map[string]any{"wildcard": map[string]any{"category": map[string]any{"value": `a\*b\?*`}}}},
+		{"ilike", builder.Where("category").ILike("TE%"), map[string]any{"wildcard": map[string]any{"category": map[string]any{"value": "TE*", "case_insensitive": true}}}},
+		{"not like", builder.Where("category").NotLike("x%"), map[string]any{"bool": map[string]any{"must_not": []any{
+			map[string]any{"wildcard": map[string]any{"category": map[string]any{"value": "x*"}}},
+		}}}},
+		{"prefix", builder.Where("category").StartsWith("te"), map[string]any{"prefix": map[string]any{"category": "te"}}},
+		{"suffix", builder.Where("category").EndsWith("ch"), map[string]any{"wildcard": map[string]any{"category": map[string]any{"value": "*ch"}}}},
+		{"regex", builder.Where("category").Regex("te.*"), map[string]any{"regexp": map[string]any{"category": map[string]any{"value": "te.*"}}}},
+		{"contains", builder.Where("tags").Contains("go"), map[string]any{"term": map[string]any{"tags": "go"}}},
+		{"contains any", builder.Where("tags").ContainsAny("a", "b"), map[string]any{"terms": map[string]any{"tags": []any{"a", "b"}}}},
+		{"contains all", builder.Where("tags").ContainsAll("a", "b"), map[string]any{"bool": map[string]any{"must": []any{
+			map[string]any{"term": map[string]any{"tags": "a"}},
+			map[string]any{"term": map[string]any{"tags": "b"}},
+		}}}},
+		{"is not empty", builder.Where("tags").IsNotEmpty(), map[string]any{"exists": map[string]any{"field": "tags"}}},
+		{"is empty", builder.Where("tags").IsEmpty(), map[string]any{"bool": map[string]any{"must_not": []any{
+			map[string]any{"exists": map[string]any{"field": "tags"}},
+		}}}},
+		{"all", builder.All(), map[string]any{"match_all": map[string]any{}}},
+		{"none", builder.None(), map[string]any{"match_none": map[string]any{}}},
+		{"raw", builder.Raw("elasticsearch", []byte(`{"match": {"title": "vector"}}`)), map[string]any{"match": map[string]any{"title": "vector"}}},
+		{"nested bool", builder.And(
+			tech,
+			builder.Or(builder.Where("score").Gt(0.5), builder.Where("active").Eq(true)),
+			builder.Not(builder.Where("count").Lt(3)),
+		), map[string]any{"bool": map[string]any{"must": []any{
+			techTerm,
+			map[string]any{"bool": map[string]any{
+				"should": []any{
+					map[string]any{"range": map[string]any{"score": map[string]any{"gt": 0.5}}},
+					map[string]any{"term": map[string]any{"active": true}},
+				},
+				"minimum_should_match": 1,
+			}},
+			map[string]any{"bool": map[string]any{"must_not": []any{
+				map[string]any{"range": map[string]any{"count": map[string]any{"lt": 3}}},
+			}}},
+		}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.filter.ToElasticsearch()
+			if err != nil {
+				t.Fatalf("ToElasticsearch() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToElasticsearch() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_ToElasticsearch_GeoBox(t *testing.T) {
+	builder, _ := New[placeMetadata]()
+
+	got, err := builder.GeoBox("lat", "lng", 40, -75, 41, -73).ToElasticsearch()
+	if err != nil {
+		t.Fatalf("ToElasticsearch() error = %v", err)
+	}
+	want := map[string]any{"bool": map[string]any{"must": []any{
+		map[string]any{"range": map[string]any{"lat": map[string]any{"gte": 40.0, "lte": 41.0}}},
+		map[string]any{"range": map[string]any{"lng": map[string]any{"gte": -75.0, "lte": -73.0}}},
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToElasticsearch() = %#v, want %#v", got, want)
+	}
+}
+
+func TestFilter_ToElasticsearch_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	folded, _ := New[testMetadata](WithCaseInsensitive("category"))
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		wantErr error
+	}{
+		{"nil", nil, ErrInvalidFilter},
+		{"construction error", builder.And(builder.Where("missing").Eq(1)), ErrFieldNotFound},
+		{"case-insensitive", folded.Where("category").Eq("Tech"), ErrInvalidFilter},
+		{"len", builder.Where("tags").Len(Gte, 2), ErrInvalidFilter},
+		{"raw for other backend", builder.Raw("mongo", []byte(`{"a": 1}`)), ErrInvalidFilter},
+		{"raw not an object", builder.Raw("elasticsearch", []byte(`"x"`)), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.filter.ToElasticsearch(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ToElasticsearch() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilter_ToElasticsearch_MapFields(t *testing.T) {
+	builder, _ := New[attributedMetadata]()
+
+	got, err := builder.Where("scores.relevance").Gte(0.5).ToElasticsearch()
+	if err != nil {
+		t.Fatalf("ToElasticsearch() error = %v", err)
+	}
+	want := map[string]any{"range": map[string]any{"scores.relevance": map[string]any{"gte": 0.5}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToElasticsearch() = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+package vecna
+
+import (
+	"reflect"
+	"time"
+)
+
+// Equal reports whether f and other are structurally identical: same
+// operator, field, value, and case sensitivity, and pairwise Equal children in the same order.
+// Values are compared deeply; list values compare element by element, so
+// In("a", "b") equals In([]string{"a", "b"}), and times compare by instant.
+// A filter carrying a construction error never equals one without. Two nil
+// filters are equal.
+func (f *Filter) Equal(other *Filter) bool {
+	if f == nil || other == nil {
+		return f == other
+	}
+	if f.op != other.op || f.field != other.field || f.fold != other.fold || (f.err == nil) != (other.err == nil) {
+		return false
+	}
+	if !filterValuesEqual(f.value, other.value) {
+		return false
+	}
+	if len(f.children) != len(other.children) {
+		return false
+	}
+	for i, child := range f.children {
+		if !child.Equal(other.children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterValuesEqual compares two filter values, element-wise for lists.
+func filterValuesEqual(a, b any) bool {
+	if at, ok := a.(time.Time); ok {
+		bt, ok := b.(time.Time)
+		return ok && at.Equal(bt)
+	}
+	if isListValue(a) && isListValue(b) {
+		as, _ := sliceValues(a)
+		bs, _ := sliceValues(b)
+		if len(as) != len(bs) {
+			return false
+		}
+		for i := range as {
+			if !filterValuesEqual(as[i], bs[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// isListValue reports whether v is a slice or array, other than raw bytes.
+func isListValue(v any) bool {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return false
+	}
+	return rv.Type().Elem().Kind() != reflect.Uint8
+}
@@ -0,0 +1,54 @@
+package vecna
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilter_Equal(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	events, _ := New[eventMetadata]()
+	at := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tree := func(score float64) *Filter {
+		return builder.And(
+			builder.Where("category").In("a", "b"),
+			builder.Or(
+				builder.Where("score").Gte(score),
+				builder.Not(builder.Where("active").Eq(true)),
+			),
+		)
+	}
+
+	tests := []struct {
+		name string
+		a, b *Filter
+		want bool
+	}{
+		{"same tree", tree(0.5), tree(0.5), true},
+		{"different nested value", tree(0.5), tree(0.9), false},
+		{"in variadic vs slice", builder.Where("category").In("a", "b"), builder.Where("category").In([]string{"a", "b"}), true},
+		{"in different order", builder.Where("category").In("a", "b"), builder.Where("category").In("b", "a"), false},
+		{"in different length", builder.Where("category").In("a", "b"), builder.Where("category").In("a"), false},
+		{"different op", builder.Where("count").Gt(1), builder.Where("count").Gte(1), false},
+		{"different field", builder.Where("count").Eq(1), builder.Where("score").Eq(1), false},
+		{"different type", builder.Where("score").Eq(1), builder.Where("score").Eq(1.0), false},
+		{"child order", builder.And(builder.Where("count").Eq(1), builder.Where("active").Eq(true)), builder.And(builder.Where("active").Eq(true), builder.Where("count").Eq(1)), false},
+		{"time zones", events.Where("created_at").Eq(at), events.Where("created_at").Eq(at.In(time.FixedZone("X", 3600))), true},
+		{"time from string", events.Where("created_at").Eq(at), events.Where("created_at").Eq("2024-06-01T00:00:00Z"), true},
+		{"error vs valid", builder.Where("count").Eq("x"), builder.Where("count").Eq(1), false},
+		{"both nil", nil, nil, true},
+		{"one nil", builder.Where("count").Eq(1), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equal(tt.b); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+			if got := tt.b.Equal(tt.a); got != tt.want {
+				t.Errorf("Equal() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
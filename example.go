@@ -0,0 +1,76 @@
+package vecna
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FromExample builds an And of Eq conditions from the field values of v,
+// for "find records like this one" queries such as duplicate detection.
+// With fields listed, one condition is built per listed field, skipping
+// those holding their zero value if the builder was created with
+// WithExampleSkipZero. With none listed, every field of the schema that is
+// set in v is used, in schema order, except KindMap fields, whose entries
+// must be listed individually as "field.key". Nil pointer fields and
+// missing map entries are always skipped,
+// since Eq cannot express absence. Values are taken as stored in T, so
+// value parsers and WithOneOf are not applied. An unknown field yields
+// ErrFieldNotFound on its condition. A base filter set with WithBaseFilter
+// is ANDed in, as for FromSpec. When no condition remains, as for a zero
+// example, the result is All.
+func (b *Builder[T]) FromExample(v T, fields ...string) *Filter {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return &Filter{op: And, err: fmt.Errorf("%w: cannot build example from nil %T", ErrInvalidFilter, v)}
+		}
+		rv = rv.Elem()
+	}
+
+	skipZero := b.exampleSkipZero
+	if len(fields) == 0 {
+		skipZero = true
+		for _, field := range b.spec.Fields {
+			if field.Kind == KindMap {
+				continue
+			}
+			if _, _, err := b.fieldValue(rv, field.Name); err == nil {
+				fields = append(fields, field.Name)
+			}
+		}
+	}
+
+	conds := make([]*Filter, 0, len(fields))
+	for _, name := range fields {
+		value, present, err := b.fieldValue(rv, name)
+		if err != nil {
+			conds = append(conds, &Filter{op: Eq, field: name, err: err})
+			continue
+		}
+		if !present || (skipZero && b.isZeroExample(rv, name, value)) {
+			continue
+		}
+		spec, _ := b.lookupField(name)
+		conds = append(conds, &Filter{op: Eq, field: name, key: b.entryKey(name), value: value, kind: spec.Kind})
+	}
+	if len(conds) == 0 {
+		return b.withBase(b.All())
+	}
+	return b.withBase(b.And(conds...))
+}
+
+// isZeroExample reports whether the named field, or map entry holding
+// value, is zero.
+func (b *Builder[T]) isZeroExample(rv reflect.Value, name string, value any) bool {
+	if index, ok := b.index[name]; ok {
+		return isZeroField(rv, index)
+	}
+	return reflect.ValueOf(value).IsZero()
+}
+
+// isZeroField reports whether the struct field at index holds its zero
+// value. A non-nil pointer counts as set, whatever it points to.
+func isZeroField(rv reflect.Value, index []int) bool {
+	fv, err := rv.FieldByIndexErr(index)
+	return err != nil || fv.IsZero()
+}
@@ -0,0 +1,98 @@
+package vecna
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBuilder_FromExample(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	doc := testMetadata{Category: "tech", Count: 0, Active: true, Tags: []string{"go"}, Internal: "x"}
+
+	tests := []struct {
+		name   string
+		fields []string
+		want   string
+	}{
+		{"non-zero fields", nil, `(category == "tech" AND active == true AND tags == [go])`},
+		{"listed fields", []string{"category", "count"}, `(category == "tech" AND count == 0)`},
+		{"single field", []string{"active"}, `(active == true)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := builder.FromExample(doc, tt.fields...)
+			if f.Err() != nil {
+				t.Fatalf("FromExample() error = %v", f.Err())
+			}
+			if got := f.String(); got != tt.want {
+				t.Errorf("FromExample() = %s, want %s", got, tt.want)
+			}
+			ok, err := builder.Match(f, doc)
+			if err != nil || !ok {
+				t.Errorf("Match(FromExample(doc), doc) = %v, %v, want true", ok, err)
+			}
+		})
+	}
+
+	t.Run("skip zero option", func(t *testing.T) {
+		skipping, _ := New[testMetadata](WithExampleSkipZero())
+		f := skipping.FromExample(doc, "category", "count", "score")
+		if got, want := f.String(), `(category == "tech")`; got != want {
+			t.Errorf("FromExample() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		f := builder.FromExample(doc, "category", "missing")
+		if !errors.Is(f.Err(), ErrFieldNotFound) {
+			t.Errorf("FromExample() error = %v, want %v", f.Err(), ErrFieldNotFound)
+		}
+	})
+
+	t.Run("nil pointers", func(t *testing.T) {
+		events, _ := New[eventMetadata]()
+		created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		f := events.FromExample(eventMetadata{Name: "launch", CreatedAt: created}, "name", "created_at", "deleted_at")
+		if f.Err() != nil {
+			t.Fatalf("FromExample() error = %v", f.Err())
+		}
+		if got := len(f.Children()); got != 2 {
+			t.Errorf("len(FromExample().Children()) = %d, want 2: %s", got, f)
+		}
+
+		deleted := created.Add(time.Hour)
+		f = events.FromExample(eventMetadata{DeletedAt: &deleted})
+		if got, want := f.String(), `(deleted_at == "2025-01-02T04:04:05Z")`; got != want {
+			t.Errorf("FromExample() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("zero example", func(t *testing.T) {
+		f := builder.FromExample(testMetadata{})
+		if f.Err() != nil || f.Op() != All {
+			t.Errorf("FromExample(zero) = %s, %v, want all, nil", f, f.Err())
+		}
+	})
+
+	t.Run("nil example", func(t *testing.T) {
+		pointers, _ := New[*testMetadata]()
+		if f := pointers.FromExample(nil); !errors.Is(f.Err(), ErrInvalidFilter) {
+			t.Errorf("FromExample(nil) error = %v, want %v", f.Err(), ErrInvalidFilter)
+		}
+	})
+}
+
+func TestBuilder_FromExample_MapFields(t *testing.T) {
+	builder, _ := New[attributedMetadata]()
+	v := attributedMetadata{Name: "lamp", Attributes: map[string]string{"color": "red"}}
+
+	if got, want := builder.FromExample(v).String(), `(name == "lamp")`; got != want {
+		t.Errorf("FromExample() = %s, want %s", got, want)
+	}
+	got := builder.FromExample(v, "attributes.color", "attributes.size").String()
+	if want := `(attributes.color == "red")`; got != want {
+		t.Errorf("FromExample(entries) = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+package vecna
+
+import "fmt"
+
+// Explanation records how a filter evaluated against a single item.
+// It mirrors the filter tree: each node carries its boolean result, and
+// leaves additionally carry the actual field value that was compared.
+type Explanation struct {
+	Op       Op            // Operator of the filter node
+	Field    string        // Field name (leaves only)
+	Value    any           // Filter value (leaves only)
+	Actual   any           // Actual field value (leaves only; nil when absent)
+	Present  bool          // Whether the field was present (leaves only)
+	Result   bool          // Whether this node matched
+	Err      error         // Evaluation error for this node, if any
+	Children []Explanation // Explanations for child filters
+}
+
+// MatchExplain reports whether item satisfies the filter, together with an
+// Explanation of every node's result. Unlike Match, logical operators do
+// not short-circuit, so every leaf is annotated. If the filter carries a
+// construction error, the result is false and the error is recorded on the
+// root Explanation.
+func (b *Builder[T]) MatchExplain(f *Filter, item T) (bool, Explanation) {
+	if err := checkCompilable(f); err != nil {
+		return false, Explanation{Err: err}
+	}
+
+	lookup, err := b.structLookup(item)
+	if err != nil {
+		return false, Explanation{Op: f.op, Field: f.field, Value: f.value, Err: err}
+	}
+
+	exp := explainTree(f, lookup)
+	return exp.Result, exp
+}
+
+// explainTree evaluates a filter tree, recording the result of every node.
+func explainTree(f *Filter, lookup fieldLookup) Explanation {
+	exp := Explanation{Op: f.op}
+
+	switch f.op {
+	case And, Or:
+		exp.Children = make([]Explanation, len(f.children))
+		exp.Result = f.op == And
+		for i, child := range f.children {
+			exp.Children[i] = explainTree(child, lookup)
+			if f.op == And {
+				exp.Result = exp.Result && exp.Children[i].Result
+			} else {
+				exp.Result = exp.Result || exp.Children[i].Result
+			}
+		}
+		return exp
+	case Not:
+		if len(f.children) != 1 {
+			exp.Err = fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+			return exp
+		}
+		child := explainTree(f.children[0], lookup)
+		exp.Children = []Explanation{child}
+		exp.Result = child.Err == nil && !child.Result
+		return exp
+	case Xor:
+		if len(f.children) != 2 {
+			exp.Err = fmt.Errorf("%w: %s requires exactly two children", ErrInvalidFilter, f.op)
+			return exp
+		}
+		exp.Children = []Explanation{explainTree(f.children[0], lookup), explainTree(f.children[1], lookup)}
+		exp.Result = exp.Children[0].Result != exp.Children[1].Result
+		return exp
+	}
+
+	exp.Field = f.field
+	exp.Value = f.value
+	exp.Actual, exp.Present, exp.Result, exp.Err = evalLeaf(f, lookup)
+	return exp
+}
@@ -0,0 +1,80 @@
+package vecna
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuilder_MatchExplain(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	doc := testMetadata{Category: "tech", Score: 0.4, Count: 10, Active: true}
+
+	filter := builder.And(
+		builder.Where("category").Eq("tech"),
+		builder.Or(
+			builder.Where("active").Eq(false),
+			builder.Where("score").Gte(0.5),
+		),
+		builder.Not(builder.Where("count").Eq(0)),
+	)
+
+	ok, exp := builder.MatchExplain(filter, doc)
+	if ok {
+		t.Fatal("MatchExplain() = true, want false")
+	}
+	if exp.Op != And || exp.Result || len(exp.Children) != 3 {
+		t.Fatalf("root = %v/%v with %d children, want and/false with 3", exp.Op, exp.Result, len(exp.Children))
+	}
+
+	if !exp.Children[0].Result {
+		t.Errorf("category leaf Result = false, want true")
+	}
+	if !exp.Children[2].Result {
+		t.Errorf("not node Result = false, want true")
+	}
+
+	// The failing branch is the Or, where both leaves failed
+	or := exp.Children[1]
+	if or.Result {
+		t.Errorf("or node Result = true, want false")
+	}
+	score := or.Children[1]
+	if score.Field != "score" || score.Result {
+		t.Errorf("score leaf = %s/%v, want score/false", score.Field, score.Result)
+	}
+	if score.Actual != 0.4 || !score.Present {
+		t.Errorf("score leaf Actual = %v (present %v), want 0.4 (present true)", score.Actual, score.Present)
+	}
+	if score.Value != 0.5 {
+		t.Errorf("score leaf Value = %v, want 0.5", score.Value)
+	}
+}
+
+func TestBuilder_MatchExplain_NoShortCircuit(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.And(builder.Where("category").Eq("art"), builder.Where("count").Eq(3))
+
+	_, exp := builder.MatchExplain(filter, testMetadata{Category: "tech", Count: 3})
+	if len(exp.Children) != 2 || !exp.Children[1].Result {
+		t.Errorf("second child not evaluated: %+v", exp.Children)
+	}
+}
+
+func TestBuilder_MatchExplain_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	ok, exp := builder.MatchExplain(builder.Where("missing").Eq("x"), testMetadata{})
+	if ok || !errors.Is(exp.Err, ErrFieldNotFound) {
+		t.Errorf("MatchExplain() = %v, %v, want false, %v", ok, exp.Err, ErrFieldNotFound)
+	}
+
+	unevaluable := &Filter{op: Op(99), field: "category"}
+	ok, exp = builder.MatchExplain(builder.Or(unevaluable, builder.Where("count").Eq(0)), testMetadata{})
+	if !ok {
+		t.Error("MatchExplain() = false, want true")
+	}
+	if !errors.Is(exp.Children[0].Err, ErrInvalidFilter) {
+		t.Errorf("Children[0].Err = %v, want %v", exp.Children[0].Err, ErrInvalidFilter)
+	}
+}
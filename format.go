@@ -0,0 +1,228 @@
+package vecna
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// String renders the filter in a readable infix form for logging and
+// debugging, e.g. (category == "tech" AND (score >= 0.5 OR active == true)).
+// Logical groups are always parenthesized, Not renders as NOT (...), and
+// string values are quoted. The output is not a query language for any backend.
+func (f *Filter) String() string {
+	var sb strings.Builder
+	writeFilter(&sb, f)
+	return sb.String()
+}
+
+// Canonical renders the filter like String but in a deterministic form for
+// cache keys and deduplication: the children of every And and Or are sorted
+// by operator, then field, then rendered value, so And(a, b) and And(b, a)
+// render identically, and times render in UTC. Not and list values keep
+// their order, and groups are not flattened; call Normalize first to make
+// And(a, And(b, c)) and And(a, b, c) agree as well.
+func (f *Filter) Canonical() string {
+	var sb strings.Builder
+	writeFilter(&sb, canonicalize(f))
+	return sb.String()
+}
+
+// canonicalize returns a copy of f with group children sorted and times in UTC.
+func canonicalize(f *Filter) *Filter {
+	if f == nil || f.err != nil {
+		return f
+	}
+
+	clone := *f
+	clone.value = canonicalValue(f.value)
+	if f.children == nil {
+		return &clone
+	}
+	type keyed struct {
+		filter *Filter
+		text   string
+	}
+	children := make([]keyed, len(f.children))
+	for i, child := range f.children {
+		child = canonicalize(child)
+		children[i] = keyed{child, child.String()}
+	}
+	if isGroup(f) {
+		slices.SortStableFunc(children, func(a, b keyed) int {
+			aop, afield := canonicalKey(a.filter)
+			bop, bfield := canonicalKey(b.filter)
+			return cmp.Or(cmp.Compare(aop, bop), strings.Compare(afield, bfield), strings.Compare(a.text, b.text))
+		})
+	}
+	clone.children = make([]*Filter, len(children))
+	for i, child := range children {
+		clone.children[i] = child.filter
+	}
+	return &clone
+}
+
+// canonicalKey returns the operator and field sort keys of a possibly nil
+// child; nil sorts first.
+func canonicalKey(f *Filter) (op int, field string) {
+	if f == nil {
+		return -1, ""
+	}
+	return int(f.op), f.field
+}
+
+// canonicalValue converts times, alone or in a list, to UTC.
+func canonicalValue(value any) any {
+	if t, ok := value.(time.Time); ok {
+		return t.UTC()
+	}
+	if !isListValue(value) {
+		return value
+	}
+	values, err := sliceValues(value)
+	if err != nil || !slices.ContainsFunc(values, func(v any) bool { _, ok := v.(time.Time); return ok }) {
+		return value
+	}
+	converted := make([]any, len(values))
+	for i, v := range values {
+		converted[i] = canonicalValue(v)
+	}
+	return converted
+}
+
+// writeFilter appends the readable form of f to sb.
+func writeFilter(sb *strings.Builder, f *Filter) {
+	if f == nil {
+		sb.WriteString("<nil>")
+		return
+	}
+	if f.err != nil {
+		sb.WriteString("<invalid: " + f.err.Error() + ">")
+		return
+	}
+
+	switch f.op {
+	case And, Or, Xor:
+		sb.WriteString("(")
+		for i, child := range f.children {
+			if i > 0 {
+				sb.WriteString(" " + strings.ToUpper(f.op.String()) + " ")
+			}
+			writeFilter(sb, child)
+		}
+		sb.WriteString(")")
+		return
+	case All, None:
+		sb.WriteString(strings.ToUpper(f.op.String()))
+		return
+	case Not:
+		sb.WriteString("NOT (")
+		for _, child := range f.children {
+			writeFilter(sb, child)
+		}
+		sb.WriteString(")")
+		return
+	}
+
+	if box, ok := f.value.(GeoBoxValue); ok && f.op == GeoBox {
+		fmt.Fprintf(sb, "(%s, %s) IN BOX [%v, %v] x [%v, %v]",
+			f.field, box.LngField, box.MinLat, box.MaxLat, box.MinLng, box.MaxLng)
+		return
+	}
+	if raw, ok := f.value.(RawValue); ok && f.op == Raw {
+		fmt.Fprintf(sb, "RAW %s %s", raw.Backend, raw.Payload)
+		return
+	}
+
+	sb.WriteString(f.field + " ")
+	switch f.op {
+	case Eq:
+		sb.WriteString("== " + formatValue(f.value))
+	case Ne:
+		sb.WriteString("!= " + formatValue(f.value))
+	case Gt:
+		sb.WriteString("> " + formatValue(f.value))
+	case Gte:
+		sb.WriteString(">= " + formatValue(f.value))
+	case Lt:
+		sb.WriteString("< " + formatValue(f.value))
+	case Lte:
+		sb.WriteString("<= " + formatValue(f.value))
+	case In:
+		sb.WriteString("IN " + formatList(f.value))
+	case Nin:
+		sb.WriteString("NOT IN " + formatList(f.value))
+	case Like:
+		sb.WriteString("LIKE " + formatValue(f.value))
+	case ILike:
+		sb.WriteString("ILIKE " + formatValue(f.value))
+	case NotLike:
+		sb.WriteString("NOT LIKE " + formatValue(f.value))
+	case IsEmpty:
+		sb.WriteString("IS EMPTY")
+	case IsNotEmpty:
+		sb.WriteString("IS NOT EMPTY")
+	case Len:
+		if v, err := lenValue(f); err == nil {
+			sb.WriteString("LEN " + sqlLenOps[v.Op] + " " + formatValue(v.N))
+		} else {
+			sb.WriteString("LEN " + formatValue(f.value))
+		}
+	case Regex:
+		sb.WriteString("=~ " + formatValue(f.value))
+	case Prefix:
+		sb.WriteString("STARTS WITH " + formatValue(f.value))
+	case Suffix:
+		sb.WriteString("ENDS WITH " + formatValue(f.value))
+	case Contains:
+		sb.WriteString("CONTAINS " + formatValue(f.value))
+	case ContainsAll:
+		sb.WriteString("CONTAINS ALL " + formatList(f.value))
+	case ContainsAny:
+		sb.WriteString("CONTAINS ANY " + formatList(f.value))
+	case Between:
+		if values, err := sliceValues(f.value); err == nil && len(values) == 2 {
+			sb.WriteString("BETWEEN " + formatValue(values[0]) + " AND " + formatValue(values[1]))
+		} else {
+			sb.WriteString("BETWEEN " + formatValue(f.value))
+		}
+	case Approx:
+		if values, err := sliceValues(f.value); err == nil && len(values) == 2 {
+			sb.WriteString("APPROX " + formatValue(values[0]) + " ± " + formatValue(values[1]))
+		} else {
+			sb.WriteString("APPROX " + formatValue(f.value))
+		}
+	default:
+		sb.WriteString(f.op.String() + " " + formatValue(f.value))
+	}
+	if f.fold {
+		sb.WriteString(" IGNORING CASE")
+	}
+}
+
+// formatValue renders a single value, quoting strings.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case time.Time:
+		return strconv.Quote(v.Format(time.RFC3339Nano))
+	}
+	return fmt.Sprint(value)
+}
+
+// formatList renders a list value as [a, b].
+func formatList(value any) string {
+	values, err := sliceValues(value)
+	if err != nil {
+		return formatValue(value)
+	}
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = formatValue(v)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,122 @@
+package vecna
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilter_String(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"eq", builder.Where("category").Eq("tech"), `category == "tech"`},
+		{"ne", builder.Where("count").Ne(3), `count != 3`},
+		{"gte", builder.Where("score").Gte(0.5), `score >= 0.5`},
+		{"in", builder.Where("category").In("a", "b"), `category IN ["a", "b"]`},
+		{"in typed slice", builder.Where("count").In([]int{1, 2}), `count IN [1, 2]`},
+		{"nin", builder.Where("category").Nin("spam"), `category NOT IN ["spam"]`},
+		{"like", builder.Where("category").Like("te%"), `category LIKE "te%"`},
+		{"ilike", builder.Where("category").ILike("Te%"), `category ILIKE "Te%"`},
+		{"not like", builder.Where("category").NotLike("te%"), `category NOT LIKE "te%"`},
+		{"contains", builder.Where("tags").Contains("go"), `tags CONTAINS "go"`},
+		{"is empty", builder.Where("tags").IsEmpty(), `tags IS EMPTY`},
+		{"between", builder.Where("count").Between(1, 10), `count BETWEEN 1 AND 10`},
+		{"approx", builder.Where("score").Approx(0.5, 0.01), `score APPROX 0.5 ± 0.01`},
+		{"not", builder.Not(builder.Where("active").Eq(true)), `NOT (active == true)`},
+		{
+			"nested",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Or(builder.Where("score").Gte(0.5), builder.Where("active").Eq(true)),
+			),
+			`(category == "tech" AND (score >= 0.5 OR active == true))`,
+		},
+		{"invalid", builder.Where("missing").Eq("x"), `<invalid: vecna: field not found: missing>`},
+		{"nil", nil, `<nil>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.String(); got != tt.want {
+				t.Errorf("Filter.String() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_Canonical(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	tech := builder.Where("category").Eq("tech")
+	score := builder.Where("score").Gte(0.5)
+	active := builder.Where("active").Eq(true)
+
+	tests := []struct {
+		name string
+		a, b *Filter
+		want string
+	}{
+		{"swapped and", builder.And(tech, score), builder.And(score, tech), `(category == "tech" AND score >= 0.5)`},
+		{"swapped or", builder.Or(active, tech), builder.Or(tech, active), `(active == true OR category == "tech")`},
+		{
+			"nested",
+			builder.Or(builder.And(score, tech), active),
+			builder.Or(active, builder.And(tech, score)),
+			`(active == true OR (category == "tech" AND score >= 0.5))`,
+		},
+		{
+			"same op and field",
+			builder.And(builder.Where("category").Eq("b"), builder.Where("category").Eq("a")),
+			builder.And(builder.Where("category").Eq("a"), builder.Where("category").Eq("b")),
+			`(category == "a" AND category == "b")`,
+		},
+		{"not", builder.Not(builder.And(score, tech)), builder.Not(builder.And(tech, score)), `NOT ((category == "tech" AND score >= 0.5))`},
+		{"leaf", tech, builder.Where("category").Eq("tech"), `category == "tech"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.a.Canonical()
+			if got != tt.want {
+				t.Errorf("Canonical() = %s, want %s", got, tt.want)
+			}
+			if other := tt.b.Canonical(); other != got {
+				t.Errorf("Canonical() = %s and %s, want equal", got, other)
+			}
+		})
+	}
+}
+
+func TestFilter_Canonical_Preserves(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.And(builder.Where("score").Gte(0.5), builder.Where("category").In("b", "a"))
+	if got, want := filter.Canonical(), `(score >= 0.5 AND category IN ["b", "a"])`; got != want {
+		t.Errorf("Canonical() = %s, want %s", got, want)
+	}
+	if got, want := filter.String(), `(score >= 0.5 AND category IN ["b", "a"])`; got != want {
+		t.Errorf("String() after Canonical() = %s, want %s", got, want)
+	}
+
+	swapped := builder.And(builder.Where("category").In("b", "a"), builder.Where("score").Gte(0.5))
+	if got, want := swapped.String(), `(category IN ["b", "a"] AND score >= 0.5)`; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+	if swapped.Canonical() != filter.Canonical() {
+		t.Errorf("Canonical() = %s, want %s", swapped.Canonical(), filter.Canonical())
+	}
+}
+
+func TestFilter_Canonical_Time(t *testing.T) {
+	builder, _ := New[eventMetadata]()
+	instant := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	a := builder.Where("created_at").Gte(instant).Canonical()
+	b := builder.Where("created_at").Gte(instant.In(time.FixedZone("CET", 3600))).Canonical()
+	if want := `created_at >= "2024-01-02T03:04:05Z"`; a != want || b != want {
+		t.Errorf("Canonical() = %s and %s, want %s", a, b, want)
+	}
+}
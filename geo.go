@@ -0,0 +1,115 @@
+package vecna
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// GeoBoxValue is the value of a GeoBox filter. The filter's field is the
+// latitude field; LngField names the longitude field.
+type GeoBoxValue struct {
+	LngField string  `json:"lng_field"`
+	MinLat   float64 `json:"min_lat"`
+	MinLng   float64 `json:"min_lng"`
+	MaxLat   float64 `json:"max_lat"`
+	MaxLng   float64 `json:"max_lng"`
+}
+
+// GeoBox creates a rectangular geo filter matching points whose latitude and
+// longitude fields fall inside the box, edges included. Both fields must be
+// KindFloat. Boxes crossing the antimeridian (minLng > maxLng) are rejected;
+// split them into two boxes combined with Or.
+func (b *Builder[T]) GeoBox(latField, lngField string, minLat, minLng, maxLat, maxLng float64) *Filter {
+	box := GeoBoxValue{LngField: lngField, MinLat: minLat, MinLng: minLng, MaxLat: maxLat, MaxLng: maxLng}
+	filter := &Filter{op: GeoBox, field: latField, value: box, kind: KindFloat}
+
+	for _, name := range []string{latField, lngField} {
+		spec, ok := b.fields[name]
+		if !ok {
+			filter.err = fmt.Errorf("%w: %s", ErrFieldNotFound, name)
+			return filter
+		}
+		if !slices.Contains(spec.Kind.ValidOps(), GeoBox) {
+			filter.err = fmt.Errorf("%w: operator %s not valid for %s field %s", ErrInvalidFilter, GeoBox, spec.Kind, name)
+			return filter
+		}
+	}
+
+	filter.err = box.validate()
+	return filter
+}
+
+// validate checks that the box is well-formed.
+func (v GeoBoxValue) validate() error {
+	switch {
+	case v.MinLat < -90 || v.MaxLat > 90:
+		return fmt.Errorf("%w: latitude must be within [-90, 90]", ErrInvalidFilter)
+	case v.MinLng < -180 || v.MaxLng > 180:
+		return fmt.Errorf("%w: longitude must be within [-180, 180]", ErrInvalidFilter)
+	case !(v.MinLat < v.MaxLat):
+		return fmt.Errorf("%w: min latitude %v must be less than max latitude %v", ErrInvalidFilter, v.MinLat, v.MaxLat)
+	case !(v.MinLng < v.MaxLng):
+		return fmt.Errorf("%w: min longitude %v must be less than max longitude %v (antimeridian wraparound is not supported)",
+			ErrInvalidFilter, v.MinLng, v.MaxLng)
+	default:
+		return nil
+	}
+}
+
+// geoBoxValue extracts the box from a GeoBox filter.
+func geoBoxValue(f *Filter) (GeoBoxValue, error) {
+	box, ok := f.value.(GeoBoxValue)
+	if !ok {
+		return GeoBoxValue{}, fmt.Errorf("%w: %s requires a GeoBoxValue, got %T", ErrInvalidFilter, f.op, f.value)
+	}
+	return box, nil
+}
+
+// evalGeoBox evaluates a GeoBox filter, reading both coordinate fields.
+func evalGeoBox(f *Filter, lookup fieldLookup) (lat any, present, ok bool, err error) {
+	box, err := geoBoxValue(f)
+	if err != nil {
+		return nil, false, false, err
+	}
+	lat, latPresent, err := lookup(f.field)
+	if err != nil {
+		return nil, false, false, err
+	}
+	lng, lngPresent, err := lookup(box.LngField)
+	if err != nil {
+		return nil, false, false, err
+	}
+	if !latPresent || !lngPresent {
+		return lat, false, false, nil
+	}
+
+	latF, latOK := toFloat64(lat)
+	lngF, lngOK := toFloat64(lng)
+	if !latOK || !lngOK {
+		return lat, true, false, nil
+	}
+	inside := latF >= box.MinLat && latF <= box.MaxLat && lngF >= box.MinLng && lngF <= box.MaxLng
+	return lat, true, inside, nil
+}
+
+// fromGeoBoxSpec converts a geo_box spec, whose value is a GeoBoxValue or a
+// decoded JSON object with the same keys, to a Filter.
+func (b *Builder[T]) fromGeoBoxSpec(spec *FilterSpec) *Filter {
+	box, ok := spec.Value.(GeoBoxValue)
+	if !ok {
+		data, err := json.Marshal(spec.Value)
+		if err == nil {
+			err = json.Unmarshal(data, &box)
+		}
+		if err != nil {
+			return &Filter{
+				op:    GeoBox,
+				field: spec.Field,
+				value: spec.Value,
+				err:   fmt.Errorf("%w: geo_box requires a box value: %w", ErrInvalidFilter, err),
+			}
+		}
+	}
+	return b.GeoBox(spec.Field, box.LngField, box.MinLat, box.MinLng, box.MaxLat, box.MaxLng)
+}
@@ -0,0 +1,147 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// Test metadata struct with coordinates.
+type placeMetadata struct {
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lng  float64 `json:"lng"`
+	Pop  int     `json:"pop"`
+}
+
+func TestBuilder_GeoBox(t *testing.T) {
+	builder, _ := New[placeMetadata]()
+	box := builder.GeoBox("lat", "lng", 10, 20, 30, 40)
+	if box.Err() != nil {
+		t.Fatalf("GeoBox() error = %v", box.Err())
+	}
+
+	tests := []struct {
+		name     string
+		lat, lng float64
+		want     bool
+	}{
+		{"inside", 15, 25, true},
+		{"outside lat", 35, 25, false},
+		{"outside lng", 15, 45, false},
+		{"min corner", 10, 20, true},
+		{"max corner", 30, 40, true},
+		{"lat edge", 30, 30, true},
+		{"lng edge", 20, 40, true},
+		{"just outside edge", 30.0001, 30, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.Match(box, placeMetadata{Lat: tt.lat, Lng: tt.lng})
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("map missing lng", func(t *testing.T) {
+		got, err := builder.MatchMap(box, map[string]any{"lat": 15.0})
+		if err != nil || got {
+			t.Errorf("MatchMap() = %v, %v, want false, nil", got, err)
+		}
+	})
+}
+
+func TestBuilder_GeoBox_Errors(t *testing.T) {
+	builder, _ := New[placeMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   error
+	}{
+		{"unknown lat", builder.GeoBox("latitude", "lng", 0, 0, 1, 1), ErrFieldNotFound},
+		{"unknown lng", builder.GeoBox("lat", "longitude", 0, 0, 1, 1), ErrFieldNotFound},
+		{"non-float field", builder.GeoBox("lat", "pop", 0, 0, 1, 1), ErrInvalidFilter},
+		{"inverted lat", builder.GeoBox("lat", "lng", 10, 0, 5, 1), ErrInvalidFilter},
+		{"antimeridian", builder.GeoBox("lat", "lng", 0, 170, 10, -170), ErrInvalidFilter},
+		{"lat out of range", builder.GeoBox("lat", "lng", -95, 0, 10, 1), ErrInvalidFilter},
+		{"lng out of range", builder.GeoBox("lat", "lng", 0, 0, 10, 181), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.filter.Err(), tt.want) {
+				t.Errorf("GeoBox().Err() = %v, want %v", tt.filter.Err(), tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_GeoBox_Compile(t *testing.T) {
+	builder, _ := New[placeMetadata]()
+	box := builder.GeoBox("lat", "lng", -10, 20, 30.5, 40)
+
+	sql, args, err := builder.ToSQL(box)
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := `("Lat" BETWEEN $1 AND $2 AND "Lng" BETWEEN $3 AND $4)`; sql != want {
+		t.Errorf("ToSQL() = %s, want %s", sql, want)
+	}
+	if len(args) != 4 || args[0] != -10.0 || args[3] != 40.0 {
+		t.Errorf("ToSQL() args = %v, want [-10 30.5 20 40]", args)
+	}
+
+	tests := []struct {
+		name    string
+		compile func(*Filter) (string, error)
+		want    string
+	}{
+		{"pinot", CompileToPinot, `("lat" BETWEEN -10 AND 30.5 AND "lng" BETWEEN 20 AND 40)`},
+		{"surreal", CompileToSurreal, `(lat >= -10 AND lat <= 30.5 AND lng >= 20 AND lng <= 40)`},
+		{"govaluate", CompileToGovaluate, `(lat >= -10 && lat <= 30.5 && lng >= 20 && lng <= 40)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.compile(box)
+			if err != nil {
+				t.Fatalf("compile error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("compile = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_FromSpec_GeoBox(t *testing.T) {
+	builder, _ := New[placeMetadata]()
+
+	var spec FilterSpec
+	if err := json.Unmarshal([]byte(`{
+		"op": "geo_box",
+		"field": "lat",
+		"value": {"lng_field": "lng", "min_lat": 10, "min_lng": 20, "max_lat": 30, "max_lng": 40}
+	}`), &spec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	filter := builder.FromSpec(&spec)
+	if filter.Err() != nil {
+		t.Fatalf("FromSpec() error = %v", filter.Err())
+	}
+	ok, err := builder.Match(filter, placeMetadata{Lat: 15, Lng: 25})
+	if err != nil || !ok {
+		t.Errorf("Match() = %v, %v, want true, nil", ok, err)
+	}
+
+	bad := builder.FromSpec(&FilterSpec{Op: "geo_box", Field: "lat", Value: "box"})
+	if !errors.Is(bad.Err(), ErrInvalidFilter) {
+		t.Errorf("FromSpec().Err() = %v, want %v", bad.Err(), ErrInvalidFilter)
+	}
+}
@@ -0,0 +1,228 @@
+package vecna
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// govaluateIdentPattern matches variable names govaluate accepts unbracketed.
+var govaluateIdentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// CompileToGovaluate compiles a filter into an expression for the
+// github.com/Knetic/govaluate grammar, e.g.
+// category == "tech" && score >= 0.5 && category =~ "^a|b$".
+// In/Nin use govaluate's IN operator over a parenthesized array, Contains
+// tests the value IN the slice field, Like and Regex map to =~ (ILike with a
+// (?i) flag), Not maps to !(...), and All and None to true and false.
+// Field names that are not plain identifiers are bracketed.
+func CompileToGovaluate(f *Filter) (string, error) {
+	if err := checkCompilable(f); err != nil {
+		return "", err
+	}
+	if err := checkCaseSensitive(f, "govaluate"); err != nil {
+		return "", err
+	}
+	if err := checkNoEntries(f, "govaluate"); err != nil {
+		return "", err
+	}
+	return compileGovaluate(f)
+}
+
+// compileGovaluate renders a single filter node.
+func compileGovaluate(f *Filter) (string, error) {
+	switch f.op {
+	case And, Or:
+		if len(f.children) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(f.children))
+		for i, child := range f.children {
+			part, err := compileGovaluate(child)
+			if err != nil {
+				return "", err
+			}
+			if isGroup(child) || child.op == Xor {
+				part = "(" + part + ")"
+			}
+			parts[i] = part
+		}
+		sep := " && "
+		if f.op == Or {
+			sep = " || "
+		}
+		return strings.Join(parts, sep), nil
+	case Not:
+		if len(f.children) != 1 {
+			return "", fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := compileGovaluate(f.children[0])
+		if err != nil {
+			return "", err
+		}
+		return "!(" + inner + ")", nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return "", err
+		}
+		return compileGovaluate(expanded)
+	case Raw:
+		return rawClause(f, "govaluate")
+	case All:
+		return "true", nil
+	case None:
+		return "false", nil
+	}
+
+	field := govaluateIdent(f.field)
+
+	switch f.op {
+	case Eq:
+		return govaluateComparison(field, "==", f.value)
+	case Ne:
+		return govaluateComparison(field, "!=", f.value)
+	case Gt:
+		return govaluateComparison(field, ">", f.value)
+	case Gte:
+		return govaluateComparison(field, ">=", f.value)
+	case Lt:
+		return govaluateComparison(field, "<", f.value)
+	case Lte:
+		return govaluateComparison(field, "<=", f.value)
+	case In:
+		return govaluateList(field, f.value)
+	case Nin:
+		list, err := govaluateList(field, f.value)
+		if err != nil {
+			return "", err
+		}
+		return "!(" + list + ")", nil
+	case Contains:
+		lit, err := scalarLiteral(f.value, govaluateString)
+		if err != nil {
+			return "", err
+		}
+		return lit + " IN " + field, nil
+	case ContainsAll, ContainsAny:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		parts := make([]string, len(values))
+		for i, v := range values {
+			lit, err := scalarLiteral(v, govaluateString)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = lit + " IN " + field
+		}
+		sep := " && "
+		if f.op == ContainsAny {
+			sep = " || "
+		}
+		return "(" + strings.Join(parts, sep) + ")", nil
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return "", err
+		}
+		lng := govaluateIdent(box.LngField)
+		return fmt.Sprintf("(%s >= %s && %s <= %s && %s >= %s && %s <= %s)",
+			field, formatFloat(box.MinLat), field, formatFloat(box.MaxLat),
+			lng, formatFloat(box.MinLng), lng, formatFloat(box.MaxLng)), nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return "", err
+		}
+		lower, err := govaluateComparison(field, ">=", low)
+		if err != nil {
+			return "", err
+		}
+		upper, err := govaluateComparison(field, "<=", high)
+		if err != nil {
+			return "", err
+		}
+		return "(" + lower + " && " + upper + ")", nil
+	case Like:
+		pattern, ok := f.value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: like requires string pattern, got %T", ErrInvalidFilter, f.value)
+		}
+		return field + " =~ " + govaluateString(likeRegex(pattern)), nil
+	case ILike:
+		pattern, ok := f.value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: ilike requires string pattern, got %T", ErrInvalidFilter, f.value)
+		}
+		return field + " =~ " + govaluateString("(?i)"+likeRegex(pattern)), nil
+	case Regex:
+		return govaluateComparison(field, "=~", f.value)
+	case Prefix, Suffix:
+		pattern, err := affixRegex(f)
+		if err != nil {
+			return "", err
+		}
+		return field + " =~ " + govaluateString(pattern), nil
+	default:
+		return "", fmt.Errorf("%w: operator %s not supported by govaluate", ErrInvalidFilter, f.op)
+	}
+}
+
+// govaluateComparison renders "field op literal".
+func govaluateComparison(field, op string, value any) (string, error) {
+	lit, err := scalarLiteral(value, govaluateString)
+	if err != nil {
+		return "", err
+	}
+	return field + " " + op + " " + lit, nil
+}
+
+// govaluateList renders "field IN (a, b)" membership.
+func govaluateList(field string, value any) (string, error) {
+	values, err := sliceValues(value)
+	if err != nil {
+		return "", err
+	}
+	lits := make([]string, len(values))
+	for i, v := range values {
+		lit, err := scalarLiteral(v, govaluateString)
+		if err != nil {
+			return "", err
+		}
+		lits[i] = lit
+	}
+	return field + " IN (" + strings.Join(lits, ", ") + ")", nil
+}
+
+// govaluateIdent renders a variable name, bracketing it when needed.
+func govaluateIdent(name string) string {
+	if govaluateIdentPattern.MatchString(name) {
+		return name
+	}
+	return "[" + name + "]"
+}
+
+// govaluateString double-quotes a string literal, escaping backslashes and quotes.
+func govaluateString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// likeRegex converts a LIKE pattern into an anchored regular expression.
+func likeRegex(pattern string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
@@ -0,0 +1,104 @@
+package vecna
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompileToGovaluate(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"eq", builder.Where("category").Eq("tech"), `category == "tech"`},
+		{"all", builder.All(), `true`},
+		{"none", builder.None(), `false`},
+		{"ne", builder.Where("category").Ne("tech"), `category != "tech"`},
+		{"gt", builder.Where("score").Gt(0.5), `score > 0.5`},
+		{"gte", builder.Where("score").Gte(0.5), `score >= 0.5`},
+		{"lt", builder.Where("count").Lt(10), `count < 10`},
+		{"lte", builder.Where("count").Lte(10), `count <= 10`},
+		{"bool", builder.Where("active").Eq(true), `active == true`},
+		{"in", builder.Where("category").In("a", "b"), `category IN ("a", "b")`},
+		{"nin", builder.Where("category").Nin("a"), `!(category IN ("a"))`},
+		{"contains", builder.Where("tags").Contains("go"), `"go" IN tags`},
+		{"approx", builder.Where("score").Approx(0.5, 0.25), `(score >= 0.25 && score <= 0.75)`},
+		{"contains all", builder.Where("tags").ContainsAll("go", "db"), `("go" IN tags && "db" IN tags)`},
+		{"contains any", builder.Where("tags").ContainsAny("go", "db"), `("go" IN tags || "db" IN tags)`},
+		{"between", builder.Where("count").Between(1, 10), `(count >= 1 && count <= 10)`},
+		{"like", builder.Where("category").Like("te_h%"), `category =~ "^te.h.*$"`},
+		{"ilike", builder.Where("category").ILike("Te%"), `category =~ "(?i)^Te.*$"`},
+		{"like escaping", builder.Where("category").Like("a.b%"), `category =~ "^a\\.b.*$"`},
+		{"prefix", builder.Where("category").StartsWith("a.b"), `category =~ "^a\\.b"`},
+		{"suffix", builder.Where("category").EndsWith("ch"), `category =~ "ch$"`},
+		{"regex", builder.Where("category").Regex("^a|b$"), `category =~ "^a|b$"`},
+		{"escaping", builder.Where("category").Eq(`say "hi" \o/`), `category == "say \"hi\" \\o/"`},
+		{"not", builder.Not(builder.Where("active").Eq(true)), `!(active == true)`},
+		{
+			"grouping",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Or(
+					builder.Where("score").Gte(0.5),
+					builder.Where("count").Gt(3),
+				),
+			),
+			`category == "tech" && (score >= 0.5 || count > 3)`,
+		},
+		{
+			"xor",
+			builder.Or(
+				builder.Where("count").Eq(0),
+				builder.Xor(builder.Where("active").Eq(true), builder.Where("score").Gt(0.5)),
+			),
+			`count == 0 || ((active == true || score > 0.5) && !(active == true && score > 0.5))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CompileToGovaluate(tt.filter)
+			if err != nil {
+				t.Fatalf("CompileToGovaluate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CompileToGovaluate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGovaluateIdent(t *testing.T) {
+	if got := govaluateIdent("primary-category"); got != "[primary-category]" {
+		t.Errorf("govaluateIdent() = %s, want [primary-category]", got)
+	}
+}
+
+func TestCompileToGovaluate_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	attributed, _ := New[attributedMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   error
+	}{
+		{"filter error", builder.Where("missing").Eq("x"), ErrFieldNotFound},
+		{"nil filter", nil, ErrInvalidFilter},
+		{"empty group", builder.Or(), ErrInvalidFilter},
+		{"unsupported literal", builder.Where("category").Eq(struct{}{}), ErrInvalidFilter},
+		{"map entry", attributed.Where("attributes.color").Eq("red"), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileToGovaluate(tt.filter)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CompileToGovaluate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package vecna
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fromGroupSpec assembles a filter from a spec whose Group expression
+// references its children by ID, e.g. "a AND (b OR c)".
+// NOT binds tighter than AND, which binds tighter than OR.
+// Every referenced ID must exist, and every child must be referenced
+// exactly once.
+func (b *Builder[T]) fromGroupSpec(spec *FilterSpec, path string) *Filter {
+	if spec.Op != "" {
+		return &Filter{err: fmt.Errorf("%w: group spec must not set op %q", ErrInvalidFilter, spec.Op)}
+	}
+
+	named := make(map[string]int, len(spec.Children))
+	for i, child := range spec.Children {
+		if child == nil || child.ID == "" {
+			return &Filter{err: fmt.Errorf("%w: group children require an id", ErrInvalidFilter)}
+		}
+		if _, dup := named[child.ID]; dup {
+			return &Filter{err: fmt.Errorf("%w: duplicate group id %q", ErrInvalidFilter, child.ID)}
+		}
+		named[child.ID] = i
+	}
+
+	tokens, err := tokenizeGroup(spec.Group)
+	if err != nil {
+		return &Filter{err: err}
+	}
+
+	p := &groupParser[T]{
+		builder:  b,
+		tokens:   tokens,
+		children: spec.Children,
+		named:    named,
+		used:     make(map[string]bool),
+		path:     path,
+	}
+	filter, err := p.parseOr()
+	if err != nil {
+		return &Filter{err: err}
+	}
+	if p.pos < len(p.tokens) {
+		return &Filter{err: fmt.Errorf("%w: unexpected %q in group", ErrInvalidFilter, p.tokens[p.pos])}
+	}
+
+	for id := range named {
+		if !p.used[id] {
+			return &Filter{err: fmt.Errorf("%w: group child %q is not referenced", ErrInvalidFilter, id)}
+		}
+	}
+	return filter
+}
+
+// tokenizeGroup splits a group expression into identifiers, keywords, and parentheses.
+func tokenizeGroup(expr string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case isGroupIdentChar(c):
+			start := i
+			for i < len(expr) && isGroupIdentChar(expr[i]) {
+				i++
+			}
+			tokens = append(tokens, expr[start:i])
+		default:
+			return nil, fmt.Errorf("%w: unexpected character %q in group", ErrInvalidFilter, c)
+		}
+	}
+	return tokens, nil
+}
+
+// isGroupIdentChar reports whether c may appear in a group identifier.
+func isGroupIdentChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// groupParser is a recursive-descent parser over group tokens.
+type groupParser[T any] struct {
+	builder  *Builder[T]
+	tokens   []string
+	pos      int
+	children []*FilterSpec
+	named    map[string]int // child ID -> index in children
+	used     map[string]bool
+	path     string // location of the group spec, for error context
+}
+
+// peekKeyword reports whether the next token is the given keyword (case-insensitive).
+func (p *groupParser[T]) peekKeyword(keyword string) bool {
+	return p.pos < len(p.tokens) && strings.EqualFold(p.tokens[p.pos], keyword)
+}
+
+// parseOr parses: and ("OR" and)*.
+func (p *groupParser[T]) parseOr() (*Filter, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	filters := []*Filter{first}
+	for p.peekKeyword("OR") {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, next)
+	}
+	if len(filters) == 1 {
+		return first, nil
+	}
+	return p.builder.Or(filters...), nil
+}
+
+// parseAnd parses: unary ("AND" unary)*.
+func (p *groupParser[T]) parseAnd() (*Filter, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	filters := []*Filter{first}
+	for p.peekKeyword("AND") {
+		p.pos++
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, next)
+	}
+	if len(filters) == 1 {
+		return first, nil
+	}
+	return p.builder.And(filters...), nil
+}
+
+// parseUnary parses: "NOT" unary | "(" or ")" | id.
+func (p *groupParser[T]) parseUnary() (*Filter, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected end of group", ErrInvalidFilter)
+	}
+
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	switch {
+	case strings.EqualFold(tok, "NOT"):
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return p.builder.Not(inner), nil
+	case tok == "(":
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return nil, fmt.Errorf("%w: missing ) in group", ErrInvalidFilter)
+		}
+		p.pos++
+		return inner, nil
+	case tok == ")" || strings.EqualFold(tok, "AND") || strings.EqualFold(tok, "OR"):
+		return nil, fmt.Errorf("%w: unexpected %q in group", ErrInvalidFilter, tok)
+	}
+
+	i, ok := p.named[tok]
+	if !ok {
+		return nil, fmt.Errorf("%w: group references unknown id %q", ErrInvalidFilter, tok)
+	}
+	if p.used[tok] {
+		return nil, fmt.Errorf("%w: group references id %q more than once", ErrInvalidFilter, tok)
+	}
+	p.used[tok] = true
+	return p.builder.fromSpec(p.children[i], childPath(p.path, i)), nil
+}
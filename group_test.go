@@ -0,0 +1,110 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestBuilder_FromSpec_Group(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	children := func() []*FilterSpec {
+		return []*FilterSpec{
+			{ID: "a", Op: "eq", Field: "category", Value: "tech"},
+			{ID: "b", Op: "gte", Field: "score", Value: 0.5},
+			{ID: "c", Op: "eq", Field: "active", Value: true},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		group string
+		want  string
+	}{
+		{"precedence", "a OR b AND c", `("Category" = $1 OR ("Score" >= $2 AND "Active" = $3))`},
+		{"parentheses", "(a OR b) AND c", `(("Category" = $1 OR "Score" >= $2) AND "Active" = $3)`},
+		{"flattened chain", "a and b and c", `("Category" = $1 AND "Score" >= $2 AND "Active" = $3)`},
+		{"not", "NOT a AND (b OR c)", `(NOT ("Category" = $1) AND ("Score" >= $2 OR "Active" = $3))`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := builder.FromSpec(&FilterSpec{Group: tt.group, Children: children()})
+			got, _, err := builder.ToSQL(filter)
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToSQL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_FromSpec_Group_JSON(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	var spec FilterSpec
+	if err := json.Unmarshal([]byte(`{
+		"group": "active AND (tech OR top)",
+		"children": [
+			{"id": "active", "op": "eq", "field": "active", "value": true},
+			{"id": "tech", "op": "eq", "field": "category", "value": "tech"},
+			{"id": "top", "op": "gte", "field": "score", "value": 0.9}
+		]
+	}`), &spec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	filter := builder.FromSpec(&spec)
+	if filter.Err() != nil {
+		t.Fatalf("FromSpec() error = %v", filter.Err())
+	}
+	if filter.Op() != And || len(filter.Children()) != 2 {
+		t.Fatalf("FromSpec() = %v with %d children, want and with 2", filter.Op(), len(filter.Children()))
+	}
+	if filter.Children()[1].Op() != Or {
+		t.Errorf("Children()[1].Op() = %v, want %v", filter.Children()[1].Op(), Or)
+	}
+}
+
+func TestBuilder_FromSpec_Group_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	child := func(id string) *FilterSpec {
+		return &FilterSpec{ID: id, Op: "eq", Field: "category", Value: "tech"}
+	}
+
+	tests := []struct {
+		name string
+		spec *FilterSpec
+		want error
+	}{
+		{"op with group", &FilterSpec{Op: "and", Group: "a", Children: []*FilterSpec{child("a")}}, ErrInvalidFilter},
+		{"unknown id", &FilterSpec{Group: "a OR x", Children: []*FilterSpec{child("a")}}, ErrInvalidFilter},
+		{"duplicate id", &FilterSpec{Group: "a", Children: []*FilterSpec{child("a"), child("a")}}, ErrInvalidFilter},
+		{"missing id", &FilterSpec{Group: "a", Children: []*FilterSpec{child("a"), child("")}}, ErrInvalidFilter},
+		{"unreferenced child", &FilterSpec{Group: "a", Children: []*FilterSpec{child("a"), child("b")}}, ErrInvalidFilter},
+		{"repeated reference", &FilterSpec{Group: "a AND a", Children: []*FilterSpec{child("a")}}, ErrInvalidFilter},
+		{"repeated under not", &FilterSpec{Group: "(a OR b) AND NOT a", Children: []*FilterSpec{child("a"), child("b")}}, ErrInvalidFilter},
+		{"unbalanced", &FilterSpec{Group: "(a", Children: []*FilterSpec{child("a")}}, ErrInvalidFilter},
+		{"dangling operator", &FilterSpec{Group: "a AND", Children: []*FilterSpec{child("a")}}, ErrInvalidFilter},
+		{"trailing token", &FilterSpec{Group: "a)", Children: []*FilterSpec{child("a")}}, ErrInvalidFilter},
+		{"bad character", &FilterSpec{Group: "a & a", Children: []*FilterSpec{child("a")}}, ErrInvalidFilter},
+		{
+			"child error",
+			&FilterSpec{Group: "a", Children: []*FilterSpec{{ID: "a", Op: "eq", Field: "missing", Value: "x"}}},
+			ErrFieldNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := builder.FromSpec(tt.spec)
+			if !errors.Is(filter.Err(), tt.want) {
+				t.Errorf("FromSpec().Err() = %v, want %v", filter.Err(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package vecna
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"time"
+)
+
+// Hash returns a deterministic 64-bit FNV-1a hash of the filter's
+// structure, for use as a cache key. It depends only on operators, fields,
+// values, and case sensitivity, never on pointer identity, and is stable
+// across process runs and platforms. Filters that are Equal hash the same.
+//
+// Children are hashed in order, so And(a, b) and And(b, a) differ; use
+// HashUnordered to ignore the order of And and Or children. The order of
+// list values is always significant: In("a", "b") and In("b", "a") hash
+// differently. Values hash by type as well as content, so Eq(1) and
+// Eq(1.0) differ too; normalize such filters first if they should share a
+// key. Returns the filter's construction error if it has one, and
+// ErrInvalidFilter for values that cannot be encoded, such as NaN.
+func (f *Filter) Hash() (uint64, error) {
+	return hashFilter(f, false)
+}
+
+// HashUnordered is like Hash but insensitive to the order of And and Or
+// children, so And(a, b) and And(b, a) hash the same. Not and list values
+// keep their order.
+func (f *Filter) HashUnordered() (uint64, error) {
+	return hashFilter(f, true)
+}
+
+// hashFilter hashes a node from its own attributes and its children's
+// hashes, sorted first for groups when unordered is set.
+func hashFilter(f *Filter, unordered bool) (uint64, error) {
+	if err := checkCompilable(f); err != nil {
+		return 0, err
+	}
+
+	children := make([]uint64, len(f.children))
+	for i, child := range f.children {
+		h, err := hashFilter(child, unordered)
+		if err != nil {
+			return 0, err
+		}
+		children[i] = h
+	}
+	if unordered && isGroup(f) {
+		slices.Sort(children)
+	}
+
+	buf := []byte{byte(f.op)}
+	if f.fold {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	buf = appendHashString(buf, f.field)
+	buf, err := appendHashValue(buf, f.value)
+	if err != nil {
+		return 0, err
+	}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(children)))
+	for _, h := range children {
+		buf = binary.BigEndian.AppendUint64(buf, h)
+	}
+
+	h := fnv.New64a()
+	h.Write(buf)
+	return h.Sum64(), nil
+}
+
+// appendHashString appends a length-prefixed string, so adjacent strings
+// cannot run together.
+func appendHashString(buf []byte, s string) []byte {
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// appendHashValue appends a type-tagged encoding of a filter value. Lists
+// are encoded element by element, matching Equal, and times by instant.
+func appendHashValue(buf []byte, value any) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return appendHashString(buf, "nil"), nil
+	case time.Time:
+		buf = appendHashString(buf, "time")
+		return binary.BigEndian.AppendUint64(buf, uint64(v.UnixNano())), nil
+	}
+	if isListValue(value) {
+		values, err := sliceValues(value)
+		if err != nil {
+			return nil, err
+		}
+		buf = appendHashString(buf, "list")
+		buf = binary.BigEndian.AppendUint64(buf, uint64(len(values)))
+		for _, v := range values {
+			if buf, err = appendHashValue(buf, v); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot hash value of type %T: %w", ErrInvalidFilter, value, err)
+	}
+	buf = appendHashString(buf, fmt.Sprintf("%T", value))
+	return appendHashString(buf, string(data)), nil
+}
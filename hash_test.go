@@ -0,0 +1,125 @@
+package vecna
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFilter_Hash(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	folded, _ := New[testMetadata](WithCaseInsensitive("category"))
+	tech := builder.Where("category").Eq("tech")
+	score := builder.Where("score").Gte(0.5)
+
+	tests := []struct {
+		name  string
+		a, b  *Filter
+		equal bool
+	}{
+		{"rebuilt", builder.And(tech, score), builder.And(builder.Where("category").Eq("tech"), builder.Where("score").Gte(0.5)), true},
+		{"typed list", builder.Where("category").In("a", "b"), builder.Where("category").In([]string{"a", "b"}), true},
+		{"child order", builder.And(tech, score), builder.And(score, tech), false},
+		{"list order", builder.Where("category").In("a", "b"), builder.Where("category").In("b", "a"), false},
+		{"value", tech, builder.Where("category").Eq("art"), false},
+		{"value type", builder.Where("count").Eq(1), builder.Where("count").Eq(1.0), false},
+		{"field", builder.Where("score").Gt(1.0), builder.Where("count").Gt(1.0), false},
+		{"op", builder.Where("score").Gt(1.0), builder.Where("score").Gte(1.0), false},
+		{"fold", tech, folded.Where("category").Eq("tech"), false},
+		{"grouping", builder.And(tech, builder.And(score)), builder.And(builder.And(tech), score), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := tt.a.Hash()
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			b, err := tt.b.Hash()
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if (a == b) != tt.equal {
+				t.Errorf("Hash(%s) == Hash(%s) is %v, want %v", tt.a, tt.b, a == b, tt.equal)
+			}
+		})
+	}
+}
+
+func TestFilter_Hash_Time(t *testing.T) {
+	builder, _ := New[eventMetadata]()
+	instant := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	a, err := builder.Where("created_at").Gte(instant).Hash()
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	b, _ := builder.Where("created_at").Gte(instant.In(time.FixedZone("CET", 3600))).Hash()
+	if a != b {
+		t.Errorf("Hash() = %d and %d for the same instant, want equal", a, b)
+	}
+}
+
+func TestFilter_Hash_Stable(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	filter := builder.And(
+		builder.Where("category").In("tech", "science"),
+		builder.Not(builder.Where("score").Lt(0.5)),
+	)
+
+	got, err := filter.Hash()
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if want := uint64(17034696060446121231); got != want {
+		t.Errorf("Hash() = %d, want %d", got, want)
+	}
+}
+
+func TestFilter_HashUnordered(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	tech := builder.Where("category").Eq("tech")
+	score := builder.Where("score").Gte(0.5)
+	active := builder.Where("active").Eq(true)
+
+	a, _ := builder.Or(builder.And(tech, score), active).HashUnordered()
+	b, _ := builder.Or(active, builder.And(score, tech)).HashUnordered()
+	if a != b {
+		t.Errorf("HashUnordered() = %d and %d, want equal", a, b)
+	}
+
+	c, _ := builder.And(tech, score).HashUnordered()
+	d, _ := builder.Or(tech, score).HashUnordered()
+	if c == d {
+		t.Errorf("HashUnordered() of And and Or = %d, want different", c)
+	}
+
+	e, _ := builder.Where("category").In("a", "b").HashUnordered()
+	g, _ := builder.Where("category").In("b", "a").HashUnordered()
+	if e == g {
+		t.Errorf("HashUnordered() ignores list order, want it kept")
+	}
+}
+
+func TestFilter_Hash_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		wantErr error
+	}{
+		{"nil", nil, ErrInvalidFilter},
+		{"construction error", builder.And(builder.Where("missing").Eq(1)), ErrFieldNotFound},
+		{"nan", builder.Where("score").Eq(math.NaN()), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.filter.Hash(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Hash() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package vecna
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CompileToJSONB compiles a filter into a parameterized PostgreSQL WHERE-clause
+// body over metadata stored in a single JSONB column. Each field's kind drives
+// the accessor and cast: strings compare as text via column->>'field',
+// numbers are cast with ::numeric, bools with ::boolean, and times with
+// ::timestamptz. Array membership uses column->'field' ? $n for strings and
+// JSONB containment (column @> $n) otherwise; Eq on slice or unknown fields
+// also uses containment of a {"field": value} object. All and None render
+// as TRUE and FALSE. Values are bound as $1, $2, ... placeholders in the
+// returned args slice.
+func CompileToJSONB(f *Filter, column string) (string, []any, error) {
+	if err := checkCompilable(f); err != nil {
+		return "", nil, err
+	}
+	if err := checkCaseSensitive(f, "JSONB"); err != nil {
+		return "", nil, err
+	}
+
+	c := &jsonbCompiler{column: quoteIdent(column)}
+	clause, err := c.compile(f)
+	if err != nil {
+		return "", nil, err
+	}
+	return clause, c.args, nil
+}
+
+// jsonbCompiler accumulates bound arguments while rendering a filter tree.
+type jsonbCompiler struct {
+	argBinder
+	column string
+}
+
+// compile renders a single filter node.
+func (c *jsonbCompiler) compile(f *Filter) (string, error) {
+	switch f.op {
+	case And, Or:
+		if len(f.children) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(f.children))
+		for i, child := range f.children {
+			part, err := c.compile(child)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return "(" + strings.Join(parts, " "+strings.ToUpper(f.op.String())+" ") + ")", nil
+	case Not:
+		if len(f.children) != 1 {
+			return "", fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := c.compile(f.children[0])
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + inner + ")", nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return "", err
+		}
+		return c.compile(expanded)
+	case Raw:
+		return rawClause(f, "jsonb")
+	case All:
+		return "TRUE", nil
+	case None:
+		return "FALSE", nil
+	}
+
+	value := c.valueExpr(f)
+
+	switch f.op {
+	case Eq, Ne:
+		if f.kind == KindSlice || f.kind == KindUnknown {
+			contains, err := c.containment(f, f.value)
+			if err != nil {
+				return "", err
+			}
+			if f.op == Ne {
+				return "NOT (" + contains + ")", nil
+			}
+			return contains, nil
+		}
+		if f.op == Ne {
+			return value + " <> " + c.bind(f.value), nil
+		}
+		return value + " = " + c.bind(f.value), nil
+	case Gt:
+		return value + " > " + c.bind(f.value), nil
+	case Gte:
+		return value + " >= " + c.bind(f.value), nil
+	case Lt:
+		return value + " < " + c.bind(f.value), nil
+	case Lte:
+		return value + " <= " + c.bind(f.value), nil
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return "", err
+		}
+		lng := c.valueExpr(&Filter{field: box.LngField, kind: KindFloat})
+		return "(" + value + " BETWEEN " + c.bind(box.MinLat) + " AND " + c.bind(box.MaxLat) +
+			" AND " + lng + " BETWEEN " + c.bind(box.MinLng) + " AND " + c.bind(box.MaxLng) + ")", nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return "", err
+		}
+		return value + " BETWEEN " + c.bind(low) + " AND " + c.bind(high), nil
+	case In, Nin:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		if f.op == Nin {
+			return value + " <> ALL(" + c.bind(values) + ")", nil
+		}
+		return value + " = ANY(" + c.bind(values) + ")", nil
+	case Like:
+		return value + " LIKE " + c.bind(f.value), nil
+	case ILike:
+		return value + " ILIKE " + c.bind(f.value), nil
+	case NotLike:
+		return value + " NOT LIKE " + c.bind(f.value), nil
+	case IsEmpty:
+		return c.arrayLength(f) + " = 0", nil
+	case IsNotEmpty:
+		return c.arrayLength(f) + " > 0", nil
+	case Len:
+		return lenClause(f, c.arrayLength(f), c.bind)
+	case Regex:
+		return value + " ~ " + c.bind(f.value), nil
+	case Prefix, Suffix:
+		affix, ok := f.value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		if f.op == Prefix {
+			return value + " LIKE " + c.bind(escapeLike(affix)+"%"), nil
+		}
+		return value + " LIKE " + c.bind("%"+escapeLike(affix)), nil
+	case Contains:
+		if s, ok := f.value.(string); ok {
+			object, key := c.target(f)
+			return object + "->" + jsonbKey(key) + " ? " + c.bind(s), nil
+		}
+		return c.containment(f, []any{f.value})
+	case ContainsAll:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		return c.containment(f, values)
+	case ContainsAny:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		object, key := c.target(f)
+		return object + "->" + jsonbKey(key) + " ?| " + c.bind(values), nil
+	default:
+		return "", fmt.Errorf("%w: operator %s not supported by JSONB", ErrInvalidFilter, f.op)
+	}
+}
+
+// valueExpr renders the field accessor, cast according to the field kind.
+func (c *jsonbCompiler) valueExpr(f *Filter) string {
+	object, key := c.target(f)
+	text := object + "->>" + jsonbKey(key)
+	switch f.kind {
+	case KindInt, KindUint, KindFloat:
+		return "(" + text + ")::numeric"
+	case KindBool:
+		return "(" + text + ")::boolean"
+	case KindTime:
+		return "(" + text + ")::timestamptz"
+	default:
+		return text
+	}
+}
+
+// target returns the JSONB object holding the filter's value and the key it
+// is stored under: the column and the field, or for a KindMap entry such as
+// "attributes.color", column->'attributes' and "color".
+func (c *jsonbCompiler) target(f *Filter) (object, key string) {
+	if field, key, ok := f.entry(); ok {
+		return c.column + "->" + jsonbKey(field), key
+	}
+	return c.column, f.field
+}
+
+// arrayLength renders the element count of an array field.
+func (c *jsonbCompiler) arrayLength(f *Filter) string {
+	object, key := c.target(f)
+	return "jsonb_array_length(" + object + "->" + jsonbKey(key) + ")"
+}
+
+// containment renders object @> $n with a JSON-encoded {"key": value}
+// object, where object and key are the filter's target.
+func (c *jsonbCompiler) containment(f *Filter, value any) (string, error) {
+	object, key := c.target(f)
+	doc, err := json.Marshal(map[string]any{key: value})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidFilter, err)
+	}
+	return object + " @> " + c.bind(string(doc)), nil
+}
+
+// jsonbKey renders a JSONB object key as a single-quoted literal.
+func jsonbKey(field string) string {
+	return "'" + strings.ReplaceAll(field, "'", "''") + "'"
+}
@@ -0,0 +1,119 @@
+package vecna
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCompileToJSONB(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name     string
+		filter   *Filter
+		wantSQL  string
+		wantArgs []any
+	}{
+		{"string eq", builder.Where("category").Eq("tech"), `"metadata"->>'category' = $1`, []any{"tech"}},
+		{"all", builder.All(), `TRUE`, nil},
+		{"none", builder.None(), `FALSE`, nil},
+		{"string ne", builder.Where("category").Ne("tech"), `"metadata"->>'category' <> $1`, []any{"tech"}},
+		{"numeric gte", builder.Where("score").Gte(0.5), `("metadata"->>'score')::numeric >= $1`, []any{0.5}},
+		{"numeric lt", builder.Where("count").Lt(10), `("metadata"->>'count')::numeric < $1`, []any{10}},
+		{"bool eq", builder.Where("active").Eq(true), `("metadata"->>'active')::boolean = $1`, []any{true}},
+		{"between", builder.Where("count").Between(1, 5), `("metadata"->>'count')::numeric BETWEEN $1 AND $2`, []any{1, 5}},
+		{"in", builder.Where("category").In("a", "b"), `"metadata"->>'category' = ANY($1)`, []any{[]any{"a", "b"}}},
+		{"nin", builder.Where("count").Nin(1, 2), `("metadata"->>'count')::numeric <> ALL($1)`, []any{[]any{1, 2}}},
+		{"like", builder.Where("category").Like("te%"), `"metadata"->>'category' LIKE $1`, []any{"te%"}},
+		{"ilike", builder.Where("category").ILike("Te%"), `"metadata"->>'category' ILIKE $1`, []any{"Te%"}},
+		{"not like", builder.Where("category").NotLike("te%"), `"metadata"->>'category' NOT LIKE $1`, []any{"te%"}},
+		{"prefix", builder.Where("category").StartsWith("a_"), `"metadata"->>'category' LIKE $1`, []any{`a\_%`}},
+		{"contains string", builder.Where("tags").Contains("go"), `"metadata"->'tags' ? $1`, []any{"go"}},
+		{"is empty", builder.Where("tags").IsEmpty(), `jsonb_array_length("metadata"->'tags') = 0`, nil},
+		{"is not empty", builder.Where("tags").IsNotEmpty(), `jsonb_array_length("metadata"->'tags') > 0`, nil},
+		{"contains all", builder.Where("tags").ContainsAll("go", "db"), `"metadata" @> $1`, []any{`{"tags":["go","db"]}`}},
+		{"contains any", builder.Where("tags").ContainsAny("go", "db"), `"metadata"->'tags' ?| $1`, []any{[]any{"go", "db"}}},
+		{"slice eq", builder.Where("tags").Eq([]string{"go"}), `"metadata" @> $1`, []any{`{"tags":["go"]}`}},
+		{
+			"grouping",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Or(builder.Where("score").Gte(0.5), builder.Not(builder.Where("tags").Contains("old"))),
+			),
+			`("metadata"->>'category' = $1 AND (("metadata"->>'score')::numeric >= $2 OR NOT ("metadata"->'tags' ? $3)))`,
+			[]any{"tech", 0.5, "old"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := CompileToJSONB(tt.filter, "metadata")
+			if err != nil {
+				t.Fatalf("CompileToJSONB() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("CompileToJSONB() = %s, want %s", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("CompileToJSONB() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCompileToJSONB_MapEntries(t *testing.T) {
+	builder, _ := New[attributedMetadata]()
+
+	tests := []struct {
+		name     string
+		filter   *Filter
+		wantSQL  string
+		wantArgs []any
+	}{
+		{"string eq", builder.Where("attributes.color").Eq("red"), `"meta"->'attributes'->>'color' = $1`, []any{"red"}},
+		{"numeric gte", builder.Where("scores.rank").Gte(0.5), `("meta"->'scores'->>'rank')::numeric >= $1`, []any{0.5}},
+		{"dotted key", builder.Where("attributes.a.b").Eq("x"), `"meta"->'attributes'->>'a.b' = $1`, []any{"x"}},
+		{"contains", builder.Where("labels.env").Contains("prod"), `"meta"->'labels'->'env' ? $1`, []any{"prod"}},
+		{"contains all", builder.Where("labels.env").ContainsAll("a", "b"), `"meta"->'labels' @> $1`, []any{`{"env":["a","b"]}`}},
+		{"is empty", builder.Where("labels.env").IsEmpty(), `jsonb_array_length("meta"->'labels'->'env') = 0`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := CompileToJSONB(tt.filter, "meta")
+			if err != nil {
+				t.Fatalf("CompileToJSONB() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("CompileToJSONB() = %s, want %s", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("CompileToJSONB() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCompileToJSONB_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   error
+	}{
+		{"filter error", builder.Where("missing").Eq("x"), ErrFieldNotFound},
+		{"nil filter", nil, ErrInvalidFilter},
+		{"empty group", builder.And(), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := CompileToJSONB(tt.filter, "metadata")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CompileToJSONB() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package vecna
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// jsonSchema is the subset of JSON Schema used to derive fields.
+type jsonSchema struct {
+	Title      string                        `json:"title"`
+	Type       any                           `json:"type"`
+	Properties map[string]jsonSchemaProperty `json:"properties"`
+}
+
+// jsonSchemaProperty describes a single property of an object schema.
+type jsonSchemaProperty struct {
+	Type                 any             `json:"type"` // A type name, or an array of names such as ["string", "null"]
+	Format               string          `json:"format"`
+	AdditionalProperties json.RawMessage `json:"additionalProperties"` // Value schema of an object used as a map, or a boolean
+}
+
+// NewFromJSONSchema creates a Builder from a JSON Schema describing the
+// metadata, for callers without a Go struct. Each entry in the schema's
+// top-level properties becomes a field, with its kind derived from type and
+// format: string, number, integer, boolean, and array map to KindString,
+// KindFloat, KindInt, KindBool, and KindSlice, and a string with format
+// date-time maps to KindTime. An object whose additionalProperties is a
+// schema for non-object values maps to KindMap, with the value kind derived
+// the same way. Nullable types such as ["string", "null"] use the non-null
+// type. Fields are ordered by name.
+//
+// The builder supports Where, FromSpec, MatchMap, and the backend compilers.
+// Match has no struct to read fields from and reports ErrFieldNotFound.
+// Options apply as for New, including a WithBaseFilter function written
+// for Builder[any]; an invalid base filter is returned as an error.
+func NewFromJSONSchema(schema []byte, opts ...Option) (*Builder[any], error) {
+	cfg := newConfig(opts)
+
+	var doc jsonSchema
+	if err := json.Unmarshal(schema, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+	if t := schemaType(doc.Type); t != "" && t != "object" {
+		return nil, fmt.Errorf("%w: root type must be object, got %s", ErrInvalidSchema, t)
+	}
+	if len(doc.Properties) == 0 {
+		return nil, fmt.Errorf("%w: no properties", ErrInvalidSchema)
+	}
+
+	names := make([]string, 0, len(doc.Properties))
+	for name := range doc.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	spec := Spec{
+		TypeName: doc.Title,
+		Fields:   make([]FieldSpec, 0, len(names)),
+	}
+	fields := make(map[string]*FieldSpec)
+	var unknown []string
+
+	for _, name := range names {
+		kind := schemaKind(doc.Properties[name])
+		var valueKind FieldKind
+		if values, ok := mapValueSchema(doc.Properties[name]); ok {
+			valueKind = schemaKind(values)
+		}
+		if cfg.excludeKinds[kind] {
+			continue // Skip fields of excluded kinds
+		}
+		if kind == KindUnknown {
+			unknown = append(unknown, fmt.Sprintf("%s (%v)", name, doc.Properties[name].Type))
+		}
+		spec.Fields = append(spec.Fields, FieldSpec{
+			Name:      name,
+			GoName:    name,
+			Kind:      kind,
+			ValueKind: valueKind,
+		})
+		fields[name] = &spec.Fields[len(spec.Fields)-1]
+	}
+
+	if cfg.failOnUnknown && len(unknown) > 0 {
+		return nil, unknownKindError(unknown)
+	}
+	assignFieldIDs(fields)
+
+	b := &Builder[any]{
+		spec:    spec,
+		fields:  fields,
+		index:   make(map[string][]int),
+		columns: cfg.columns,
+		parsers: cfg.parsers,
+		oneOf:   cfg.oneOf,
+		fold:    cfg.foldFields,
+
+		allowEmptyIn:    cfg.allowEmptyIn,
+		maxDepth:        cfg.maxDepth,
+		exampleSkipZero: cfg.exampleSkipZero,
+	}
+	if err := b.setBase(cfg.baseFilter); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// schemaKind maps a JSON Schema property to a FieldKind.
+func schemaKind(prop jsonSchemaProperty) FieldKind {
+	switch schemaType(prop.Type) {
+	case "string":
+		if prop.Format == "date-time" {
+			return KindTime
+		}
+		return KindString
+	case "number":
+		return KindFloat
+	case "integer":
+		return KindInt
+	case "boolean":
+		return KindBool
+	case "array":
+		return KindSlice
+	case "object":
+		if _, ok := mapValueSchema(prop); ok {
+			return KindMap
+		}
+		return KindUnknown
+	default:
+		return KindUnknown
+	}
+}
+
+// mapValueSchema returns the value schema of an object property used as a
+// map: one whose additionalProperties is a schema for non-object values.
+func mapValueSchema(prop jsonSchemaProperty) (jsonSchemaProperty, bool) {
+	var values jsonSchemaProperty
+	if len(prop.AdditionalProperties) == 0 || json.Unmarshal(prop.AdditionalProperties, &values) != nil {
+		return values, false
+	}
+	return values, schemaType(values.Type) != "object"
+}
+
+// schemaType returns the first non-null type name from a JSON Schema type,
+// which may be a single name or an array of names.
+func schemaType(t any) string {
+	switch v := t.(type) {
+	case string:
+		return v
+	case []any:
+		for _, elem := range v {
+			if name, ok := elem.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// ToJSONSchema describes the schema as a JSON Schema object, for clients
+// such as filter UIs that render inputs per field. Each property carries the
+// field's JSON type (string, integer, number, boolean, or array; KindTime is
+// a date-time string, KindUint an integer with minimum 0, and KindMap an
+// object whose additionalProperties describe its values; KindUnknown has no
+// type) and, under the "x-operators" keyword, the spec names of the
+// operators valid for it. The output is accepted by NewFromJSONSchema.
+func (s *Spec) ToJSONSchema() ([]byte, error) {
+	type property struct {
+		Type       string    `json:"type,omitempty"`
+		Format     string    `json:"format,omitempty"`
+		Minimum    *int      `json:"minimum,omitempty"`
+		Additional *property `json:"additionalProperties,omitempty"`
+		Operators  []string  `json:"x-operators"`
+	}
+	type document struct {
+		Schema     string              `json:"$schema"`
+		Title      string              `json:"title,omitempty"`
+		Type       string              `json:"type"`
+		Properties map[string]property `json:"properties"`
+	}
+
+	doc := document{
+		Schema:     "https://json-schema.org/draft/2020-12/schema",
+		Title:      s.TypeName,
+		Type:       "object",
+		Properties: make(map[string]property, len(s.Fields)),
+	}
+	for _, field := range s.Fields {
+		prop := property{Type: kindSchemaType(field.Kind), Operators: []string{}}
+		switch field.Kind {
+		case KindTime:
+			prop.Format = "date-time"
+		case KindUint:
+			prop.Minimum = new(int)
+		case KindMap:
+			prop.Additional = &property{Type: kindSchemaType(field.ValueKind)}
+			if field.ValueKind == KindTime {
+				prop.Additional.Format = "date-time"
+			}
+			for _, op := range field.ValueKind.ValidOps() {
+				prop.Additional.Operators = append(prop.Additional.Operators, op.String())
+			}
+		}
+		for _, op := range field.Kind.ValidOps() {
+			prop.Operators = append(prop.Operators, op.String())
+		}
+		doc.Properties[field.Name] = prop
+	}
+	return json.Marshal(doc)
+}
+
+// kindSchemaType maps a FieldKind to a JSON Schema type name, the inverse of
+// schemaKind. KindUnknown maps to the empty string.
+func kindSchemaType(kind FieldKind) string {
+	switch kind {
+	case KindString, KindTime:
+		return "string"
+	case KindInt, KindUint:
+		return "integer"
+	case KindFloat:
+		return "number"
+	case KindBool:
+		return "boolean"
+	case KindSlice:
+		return "array"
+	case KindMap:
+		return "object"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,268 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testJSONSchema = `{
+	"title": "Document",
+	"type": "object",
+	"properties": {
+		"category": {"type": "string"},
+		"score": {"type": "number"},
+		"count": {"type": "integer"},
+		"active": {"type": "boolean"},
+		"tags": {"type": "array", "items": {"type": "string"}},
+		"created_at": {"type": "string", "format": "date-time"},
+		"owner": {"type": ["string", "null"]},
+		"extra": {"type": "object"}
+	}
+}`
+
+func TestNewFromJSONSchema(t *testing.T) {
+	builder, err := NewFromJSONSchema([]byte(testJSONSchema))
+	if err != nil {
+		t.Fatalf("NewFromJSONSchema() error = %v", err)
+	}
+
+	spec := builder.Spec()
+	if spec.TypeName != "Document" {
+		t.Errorf("Spec.TypeName = %s, want Document", spec.TypeName)
+	}
+
+	kinds := map[string]FieldKind{
+		"category":   KindString,
+		"score":      KindFloat,
+		"count":      KindInt,
+		"active":     KindBool,
+		"tags":       KindSlice,
+		"created_at": KindTime,
+		"owner":      KindString,
+		"extra":      KindUnknown,
+	}
+	if len(spec.Fields) != len(kinds) {
+		t.Fatalf("len(Spec.Fields) = %d, want %d", len(spec.Fields), len(kinds))
+	}
+	for name, want := range kinds {
+		field := spec.Field(name)
+		if field == nil {
+			t.Errorf("Spec.Field(%s) = nil", name)
+			continue
+		}
+		if field.Kind != want {
+			t.Errorf("Spec.Field(%s).Kind = %v, want %v", name, field.Kind, want)
+		}
+	}
+	if spec.Fields[0].Name != "active" {
+		t.Errorf("Spec.Fields[0].Name = %s, want active (sorted)", spec.Fields[0].Name)
+	}
+	for i, field := range spec.Fields {
+		if field.ID != i {
+			t.Errorf("Spec.Fields[%d].ID = %d, want %d", i, field.ID, i)
+		}
+	}
+}
+
+func TestNewFromJSONSchema_Filters(t *testing.T) {
+	builder, err := NewFromJSONSchema([]byte(testJSONSchema))
+	if err != nil {
+		t.Fatalf("NewFromJSONSchema() error = %v", err)
+	}
+
+	filter := builder.And(
+		builder.Where("category").Eq("tech"),
+		builder.Where("score").Gte(0.5),
+		builder.Where("tags").Contains("go"),
+	)
+	if filter.Err() != nil {
+		t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+	}
+
+	sql, args, err := builder.ToSQL(filter)
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := `("category" = $1 AND "score" >= $2 AND $3 = ANY("tags"))`; sql != want {
+		t.Errorf("ToSQL() = %s, want %s", sql, want)
+	}
+	if len(args) != 3 {
+		t.Errorf("len(args) = %d, want 3", len(args))
+	}
+
+	ok, err := builder.MatchMap(filter, map[string]any{"category": "tech", "score": 0.9, "tags": []any{"go"}})
+	if err != nil || !ok {
+		t.Errorf("MatchMap() = %v, %v, want true, nil", ok, err)
+	}
+
+	if f := builder.Where("category").Gt(1); !errors.Is(f.Err(), ErrInvalidFilter) {
+		t.Errorf("Gt on string Err() = %v, want %v", f.Err(), ErrInvalidFilter)
+	}
+	if f := builder.FromSpec(&FilterSpec{Op: "eq", Field: "missing", Value: 1}); !errors.Is(f.Err(), ErrFieldNotFound) {
+		t.Errorf("FromSpec() Err() = %v, want %v", f.Err(), ErrFieldNotFound)
+	}
+}
+
+func TestNewFromJSONSchema_Options(t *testing.T) {
+	t.Run("base filter", func(t *testing.T) {
+		builder, err := NewFromJSONSchema([]byte(testJSONSchema), WithBaseFilter(func(b *Builder[any]) *Filter {
+			return b.Where("active").Eq(true)
+		}))
+		if err != nil {
+			t.Fatalf("NewFromJSONSchema() error = %v", err)
+		}
+		filter := builder.FromSpec(&FilterSpec{Op: "eq", Field: "category", Value: "tech"})
+		if want := `(active == true AND category == "tech")`; filter.String() != want {
+			t.Errorf("FromSpec() = %s, want %s", filter, want)
+		}
+	})
+
+	t.Run("base filter for other type", func(t *testing.T) {
+		_, err := NewFromJSONSchema([]byte(testJSONSchema), WithBaseFilter(func(b *Builder[testMetadata]) *Filter {
+			return b.Where("active").Eq(true)
+		}))
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("NewFromJSONSchema() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+
+	t.Run("example skip zero", func(t *testing.T) {
+		builder, err := NewFromJSONSchema([]byte(testJSONSchema), WithExampleSkipZero())
+		if err != nil {
+			t.Fatalf("NewFromJSONSchema() error = %v", err)
+		}
+		if !builder.exampleSkipZero {
+			t.Error("NewFromJSONSchema() dropped WithExampleSkipZero")
+		}
+	})
+}
+
+func TestNewFromJSONSchema_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"malformed", `{"properties":`},
+		{"not object", `{"type": "array", "properties": {"a": {"type": "string"}}}`},
+		{"no properties", `{"type": "object"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFromJSONSchema([]byte(tt.schema))
+			if !errors.Is(err, ErrInvalidSchema) {
+				t.Errorf("NewFromJSONSchema() error = %v, want %v", err, ErrInvalidSchema)
+			}
+		})
+	}
+}
+
+func TestSpec_ToJSONSchema(t *testing.T) {
+	builder, _ := New[counterMetadata]()
+	spec := builder.Spec()
+
+	data, err := spec.ToJSONSchema()
+	if err != nil {
+		t.Fatalf("ToJSONSchema() error = %v", err)
+	}
+	want := `{"$schema":"https://json-schema.org/draft/2020-12/schema","title":"counterMetadata","type":"object","properties":{` +
+		`"delta":{"type":"integer","x-operators":["eq","ne","gt","gte","lt","lte","in","nin","between","approx"]},` +
+		`"hits":{"type":"integer","minimum":0,"x-operators":["eq","ne","gt","gte","lt","lte","in","nin","between","approx"]}}}`
+	if string(data) != want {
+		t.Errorf("ToJSONSchema() = %s, want %s", data, want)
+	}
+
+	t.Run("kinds", func(t *testing.T) {
+		tests := []struct {
+			kind FieldKind
+			want map[string]any
+		}{
+			{KindString, map[string]any{"type": "string", "x-operators": []any{"eq", "ne", "in", "nin", "like", "regex", "prefix", "suffix", "ilike", "not_like"}}},
+			{KindFloat, map[string]any{"type": "number", "x-operators": []any{"eq", "ne", "gt", "gte", "lt", "lte", "in", "nin", "between", "approx", "geo_box"}}},
+			{KindBool, map[string]any{"type": "boolean", "x-operators": []any{"eq", "ne", "in", "nin"}}},
+			{KindSlice, map[string]any{"type": "array", "x-operators": []any{"eq", "ne", "in", "nin", "contains", "contains_all", "contains_any", "is_empty", "is_not_empty", "len"}}},
+			{KindTime, map[string]any{"type": "string", "format": "date-time", "x-operators": []any{"eq", "ne", "gt", "gte", "lt", "lte", "in", "nin", "between"}}},
+			{KindUnknown, map[string]any{"x-operators": []any{"eq", "ne", "in", "nin"}}},
+			{KindMap, map[string]any{"type": "object", "x-operators": []any{}, "additionalProperties": map[string]any{
+				"type": "string", "x-operators": []any{"eq", "ne", "in", "nin", "like", "regex", "prefix", "suffix", "ilike", "not_like"},
+			}}},
+		}
+		for _, tt := range tests {
+			spec := &Spec{Fields: []FieldSpec{{Name: "f", Kind: tt.kind}}}
+			data, err := spec.ToJSONSchema()
+			if err != nil {
+				t.Fatalf("ToJSONSchema() error = %v", err)
+			}
+			var doc struct {
+				Properties map[string]map[string]any `json:"properties"`
+			}
+			if err := json.Unmarshal(data, &doc); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if got := doc.Properties["f"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToJSONSchema() %s property = %v, want %v", tt.kind, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		original, _ := NewFromJSONSchema([]byte(testJSONSchema))
+		spec := original.Spec()
+		data, err := spec.ToJSONSchema()
+		if err != nil {
+			t.Fatalf("ToJSONSchema() error = %v", err)
+		}
+		rebuilt, err := NewFromJSONSchema(data)
+		if err != nil {
+			t.Fatalf("NewFromJSONSchema(ToJSONSchema()) error = %v", err)
+		}
+		if got := rebuilt.Spec(); !reflect.DeepEqual(got, spec) {
+			t.Errorf("NewFromJSONSchema(ToJSONSchema()).Spec() = %+v, want %+v", got, spec)
+		}
+	})
+}
+
+func TestNewFromJSONSchema_MapFields(t *testing.T) {
+	builder, err := NewFromJSONSchema([]byte(`{
+		"type": "object",
+		"properties": {
+			"attributes": {"type": "object", "additionalProperties": {"type": "string"}},
+			"scores": {"type": "object", "additionalProperties": {"type": ["number", "null"]}},
+			"open": {"type": "object", "additionalProperties": true},
+			"nested": {"type": "object", "additionalProperties": {"type": "object"}}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("NewFromJSONSchema() error = %v", err)
+	}
+
+	spec := builder.Spec()
+	kinds := map[string][2]FieldKind{
+		"attributes": {KindMap, KindString},
+		"scores":     {KindMap, KindFloat},
+		"open":       {KindUnknown, 0},
+		"nested":     {KindUnknown, 0},
+	}
+	for name, want := range kinds {
+		if field := spec.Field(name); field == nil || field.Kind != want[0] || field.ValueKind != want[1] {
+			t.Errorf("Spec.Field(%s) = %+v, want Kind %s, ValueKind %s", name, field, want[0], want[1])
+		}
+	}
+
+	filter := builder.Where("scores.relevance").Gte(0.5)
+	ok, err := builder.MatchMap(filter, map[string]any{"scores": map[string]any{"relevance": 0.75}})
+	if err != nil || !ok {
+		t.Errorf("MatchMap() = %v, %v, want true, nil", ok, err)
+	}
+
+	data, _ := spec.ToJSONSchema()
+	rebuilt, err := NewFromJSONSchema(data)
+	if err != nil {
+		t.Fatalf("NewFromJSONSchema(ToJSONSchema()) error = %v", err)
+	}
+	if got := rebuilt.Spec(); !reflect.DeepEqual(got, spec) {
+		t.Errorf("NewFromJSONSchema(ToJSONSchema()).Spec() = %+v, want %+v", got, spec)
+	}
+}
@@ -0,0 +1,218 @@
+package vecna
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToLanceDB compiles a filter into a LanceDB SQL filter string, such as
+// `category` = 'tech' AND `score` >= 0.5, for the where clause of a LanceDB
+// query. Column names default to the field name and may be overridden with
+// WithColumn; they are always backtick-quoted, since LanceDB folds unquoted
+// names to lower case, and an entry of a KindMap field is addressed as the
+// nested path `field`.`key`. String literals are single-quoted with
+// embedded quotes doubled, and times are rendered as RFC3339 strings.
+//
+// In and Nin render as `category` IN ('a','b') and NOT IN. Contains maps to
+// array_contains, ContainsAny to array_has_any, and ContainsAll to
+// array_has_all, each with an array literal. Like, ILike, and NotLike map
+// to LIKE, ILIKE, and NOT LIKE; Regex, StartsWith, and EndsWith to
+// regexp_match. Between, Approx, and GeoBox render as inclusive BETWEEN
+// ranges, All and None as TRUE and FALSE. IsEmpty, IsNotEmpty, and Len
+// return ErrInvalidFilter, as do case-insensitive comparisons and any node
+// with a construction error. Raw filters for backend "lancedb" are emitted
+// verbatim.
+func (b *Builder[T]) ToLanceDB(f *Filter) (string, error) {
+	if err := checkCompilable(f); err != nil {
+		return "", err
+	}
+	if err := checkCaseSensitive(f, "LanceDB"); err != nil {
+		return "", err
+	}
+	c := &lanceCompiler{column: b.lanceColumn}
+	return c.compile(f)
+}
+
+// lanceColumn resolves the quoted LanceDB column for a field.
+func (b *Builder[T]) lanceColumn(field string) string {
+	if column, ok := b.columns[field]; ok {
+		return lanceIdent(column)
+	}
+	if name, key, ok := b.mapPath(field); ok {
+		return b.lanceColumn(name) + "." + lanceIdent(key)
+	}
+	return lanceIdent(field)
+}
+
+// lanceCompiler renders a filter tree with the builder's column names.
+type lanceCompiler struct {
+	column func(string) string
+}
+
+// compile renders a single filter node.
+func (c *lanceCompiler) compile(f *Filter) (string, error) {
+	switch f.op {
+	case And, Or:
+		if len(f.children) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(f.children))
+		for i, child := range f.children {
+			part, err := c.compile(child)
+			if err != nil {
+				return "", err
+			}
+			if isGroup(child) || child.op == Xor {
+				part = "(" + part + ")"
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, " "+strings.ToUpper(f.op.String())+" "), nil
+	case Not:
+		if len(f.children) != 1 {
+			return "", fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := c.compile(f.children[0])
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + inner + ")", nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return "", err
+		}
+		return c.compile(expanded)
+	case All:
+		return "TRUE", nil
+	case None:
+		return "FALSE", nil
+	case Raw:
+		return rawClause(f, "lancedb")
+	}
+
+	col := c.column(f.field)
+
+	switch f.op {
+	case Eq:
+		return lanceComparison(col, "=", f.value)
+	case Ne:
+		return lanceComparison(col, "!=", f.value)
+	case Gt:
+		return lanceComparison(col, ">", f.value)
+	case Gte:
+		return lanceComparison(col, ">=", f.value)
+	case Lt:
+		return lanceComparison(col, "<", f.value)
+	case Lte:
+		return lanceComparison(col, "<=", f.value)
+	case In, Nin:
+		list, err := lanceList(f.value, "(", ")")
+		if err != nil {
+			return "", err
+		}
+		if f.op == Nin {
+			return col + " NOT IN " + list, nil
+		}
+		return col + " IN " + list, nil
+	case Like:
+		return lanceComparison(col, "LIKE", f.value)
+	case ILike:
+		return lanceComparison(col, "ILIKE", f.value)
+	case NotLike:
+		return lanceComparison(col, "NOT LIKE", f.value)
+	case Contains:
+		if err := checkScalarElements(f, "LanceDB"); err != nil {
+			return "", err
+		}
+		lit, err := scalarLiteral(f.value, lanceString)
+		if err != nil {
+			return "", err
+		}
+		return "array_contains(" + col + ", " + lit + ")", nil
+	case ContainsAny, ContainsAll:
+		if err := checkScalarElements(f, "LanceDB"); err != nil {
+			return "", err
+		}
+		list, err := lanceList(f.value, "[", "]")
+		if err != nil {
+			return "", err
+		}
+		if f.op == ContainsAll {
+			return "array_has_all(" + col + ", " + list + ")", nil
+		}
+		return "array_has_any(" + col + ", " + list + ")", nil
+	case Regex:
+		lit, err := scalarLiteral(f.value, lanceString)
+		if err != nil {
+			return "", err
+		}
+		return "regexp_match(" + col + ", " + lit + ")", nil
+	case Prefix, Suffix:
+		pattern, err := affixRegex(f)
+		if err != nil {
+			return "", err
+		}
+		return "regexp_match(" + col + ", " + lanceString(pattern) + ")", nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return "", err
+		}
+		lower, err := scalarLiteral(low, lanceString)
+		if err != nil {
+			return "", err
+		}
+		upper, err := scalarLiteral(high, lanceString)
+		if err != nil {
+			return "", err
+		}
+		return col + " BETWEEN " + lower + " AND " + upper, nil
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return "", err
+		}
+		lng := c.column(box.LngField)
+		return fmt.Sprintf("(%s BETWEEN %s AND %s AND %s BETWEEN %s AND %s)",
+			col, formatFloat(box.MinLat), formatFloat(box.MaxLat),
+			lng, formatFloat(box.MinLng), formatFloat(box.MaxLng)), nil
+	default:
+		return "", fmt.Errorf("%w: operator %s not supported by LanceDB", ErrInvalidFilter, f.op)
+	}
+}
+
+// lanceComparison renders "col op literal".
+func lanceComparison(col, op string, value any) (string, error) {
+	lit, err := scalarLiteral(value, lanceString)
+	if err != nil {
+		return "", err
+	}
+	return col + " " + op + " " + lit, nil
+}
+
+// lanceList renders a list of literals between open and close, as ('a','b')
+// for IN or ['a','b'] for an array literal.
+func lanceList(value any, open, closing string) (string, error) {
+	values, err := sliceValues(value)
+	if err != nil {
+		return "", err
+	}
+	lits := make([]string, len(values))
+	for i, v := range values {
+		if lits[i], err = scalarLiteral(v, lanceString); err != nil {
+			return "", err
+		}
+	}
+	return open + strings.Join(lits, ",") + closing, nil
+}
+
+// lanceString single-quotes a string literal, doubling embedded quotes.
+func lanceString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// lanceIdent backtick-quotes a column name, doubling embedded backticks.
+func lanceIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
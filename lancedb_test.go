@@ -0,0 +1,155 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBuilder_ToLanceDB(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"eq string", builder.Where("category").Eq("tech"), "`category` = 'tech'"},
+		{"ne", builder.Where("category").Ne("tech"), "`category` != 'tech'"},
+		{"gte float", builder.Where("score").Gte(0.5), "`score` >= 0.5"},
+		{"lt int", builder.Where("count").Lt(10), "`count` < 10"},
+		{"eq bool", builder.Where("active").Eq(true), "`active` = true"},
+		{"in", builder.Where("category").In("a", "b"), "`category` IN ('a','b')"},
+		{"nin", builder.Where("category").Nin("a", "b"), "`category` NOT IN ('a','b')"},
+		{"in typed", builder.FromSpec(&FilterSpec{Op: "in", Field: "count", Value: []int{1, 2}}), "`count` IN (1,2)"},
+		{"contains", builder.Where("tags").Contains("go"), "array_contains(`tags`, 'go')"},
+		{"contains any", builder.Where("tags").ContainsAny("go", "db"), "array_has_any(`tags`, ['go','db'])"},
+		{"contains all", builder.Where("tags").ContainsAll("go", "db"), "array_has_all(`tags`, ['go','db'])"},
+		{"like", builder.Where("category").Like("te%"), "`category` LIKE 'te%'"},
+		{"ilike", builder.Where("category").ILike("TE%"), "`category` ILIKE 'TE%'"},
+		{"not like", builder.Where("category").NotLike("te%"), "`category` NOT LIKE 'te%'"},
+		{"regex", builder.Where("category").Regex("^te"), "regexp_match(`category`, '^te')"},
+		{"prefix", builder.Where("category").StartsWith("a.b"), "regexp_match(`category`, '^a\\.b')"},
+		{"suffix", builder.Where("category").EndsWith("ch"), "regexp_match(`category`, 'ch$')"},
+		{"between", builder.Where("count").Between(1, 10), "`count` BETWEEN 1 AND 10"},
+		{"approx", builder.Where("score").Approx(0.5, 0.25), "`score` BETWEEN 0.25 AND 0.75"},
+		{"escaping", builder.Where("category").Eq("o'reilly"), "`category` = 'o''reilly'"},
+		{"in escaping", builder.Where("category").In("it's", "b"), "`category` IN ('it''s','b')"},
+		{"untagged", builder.Where("NoTag").Eq("x"), "`NoTag` = 'x'"},
+		{"all", builder.All(), "TRUE"},
+		{"none", builder.None(), "FALSE"},
+		{"raw", builder.Raw("lancedb", json.RawMessage(`"array_length(tags) > 2"`)), "(array_length(tags) > 2)"},
+		{
+			"and",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Where("score").Gte(0.5),
+			),
+			"`category` = 'tech' AND `score` >= 0.5",
+		},
+		{
+			"nested",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Or(
+					builder.Where("score").Gt(0.5),
+					builder.Not(builder.Where("active").Eq(false)),
+				),
+			),
+			"`category` = 'tech' AND (`score` > 0.5 OR NOT (`active` = false))",
+		},
+		{
+			"xor",
+			builder.Xor(builder.Where("active").Eq(true), builder.Where("score").Gt(0.5)),
+			"(`active` = true OR `score` > 0.5) AND NOT (`active` = true AND `score` > 0.5)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.ToLanceDB(tt.filter)
+			if err != nil {
+				t.Fatalf("ToLanceDB() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToLanceDB() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_ToLanceDB_Columns(t *testing.T) {
+	t.Run("column mapping", func(t *testing.T) {
+		builder, _ := New[testMetadata](WithColumn("category", "doc category"))
+		got, err := builder.ToLanceDB(builder.Where("category").Eq("tech"))
+		if err != nil {
+			t.Fatalf("ToLanceDB() error = %v", err)
+		}
+		if want := "`doc category` = 'tech'"; got != want {
+			t.Errorf("ToLanceDB() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("map entry", func(t *testing.T) {
+		builder, _ := New[attributedMetadata]()
+		got, err := builder.ToLanceDB(builder.Where("attributes.color").Eq("red"))
+		if err != nil {
+			t.Fatalf("ToLanceDB() error = %v", err)
+		}
+		if want := "`attributes`.`color` = 'red'"; got != want {
+			t.Errorf("ToLanceDB() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("geo box", func(t *testing.T) {
+		builder, _ := New[placeMetadata]()
+		got, err := builder.ToLanceDB(builder.GeoBox("lat", "lng", 1, 2, 3, 4))
+		if err != nil {
+			t.Fatalf("ToLanceDB() error = %v", err)
+		}
+		if want := "(`lat` BETWEEN 1 AND 3 AND `lng` BETWEEN 2 AND 4)"; got != want {
+			t.Errorf("ToLanceDB() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("time", func(t *testing.T) {
+		builder, _ := New[eventMetadata]()
+		created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		got, err := builder.ToLanceDB(builder.Where("created_at").Gte(created))
+		if err != nil {
+			t.Fatalf("ToLanceDB() error = %v", err)
+		}
+		if want := "`created_at` >= '2025-01-02T03:04:05Z'"; got != want {
+			t.Errorf("ToLanceDB() = %s, want %s", got, want)
+		}
+	})
+}
+
+func TestBuilder_ToLanceDB_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	folded, _ := New[testMetadata](WithCaseInsensitive("category"))
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		wantErr error
+	}{
+		{"filter error", builder.Where("missing").Eq("x"), ErrFieldNotFound},
+		{"nil filter", nil, ErrInvalidFilter},
+		{"unsupported literal", builder.Where("category").Eq(struct{}{}), ErrInvalidFilter},
+		{"is empty", builder.Where("tags").IsEmpty(), ErrInvalidFilter},
+		{"len", builder.Where("tags").Len(Gte, 2), ErrInvalidFilter},
+		{"case insensitive", folded.Where("category").Eq("Tech"), ErrInvalidFilter},
+		{"other raw backend", builder.Raw("sql", json.RawMessage(`"1 = 1"`)), ErrInvalidFilter},
+		{"empty and", builder.And(), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := builder.ToLanceDB(tt.filter); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ToLanceDB() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// LenValue is the value of a Len filter: the array field's element count
+// is compared with N using Op, one of Eq, Ne, Gt, Gte, Lt, or Lte. In a
+// FilterSpec it is a JSON object such as {"op": "gte", "n": 3}.
+type LenValue struct {
+	Op Op
+	N  int
+}
+
+// lenJSON is the JSON form of a LenValue.
+type lenJSON struct {
+	Op string `json:"op"`
+	N  *int   `json:"n"`
+}
+
+// MarshalJSON encodes the value as {"op": "<op>", "n": <n>}.
+func (v LenValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(lenJSON{Op: v.Op.String(), N: &v.N})
+}
+
+// UnmarshalJSON decodes the value from {"op": "<op>", "n": <n>}.
+func (v *LenValue) UnmarshalJSON(data []byte) error {
+	var raw lenJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.N == nil {
+		return errors.New("missing n")
+	}
+	op, err := parseOp(raw.Op)
+	if err != nil {
+		return err
+	}
+	*v = LenValue{Op: op, N: *raw.N}
+	return nil
+}
+
+// validate checks that the comparison is one Len supports.
+func (v LenValue) validate() error {
+	switch v.Op {
+	case Eq, Ne, Gt, Gte, Lt, Lte:
+	default:
+		return fmt.Errorf("%w: len does not support operator %s", ErrInvalidFilter, v.Op)
+	}
+	if v.N < 0 {
+		return fmt.Errorf("%w: len requires a non-negative count, got %d", ErrInvalidFilter, v.N)
+	}
+	return nil
+}
+
+// matches reports whether an element count satisfies the comparison.
+func (v LenValue) matches(n int) bool {
+	switch v.Op {
+	case Eq:
+		return n == v.N
+	case Ne:
+		return n != v.N
+	case Gt:
+		return n > v.N
+	case Gte:
+		return n >= v.N
+	case Lt:
+		return n < v.N
+	default:
+		return n <= v.N
+	}
+}
+
+// sqlLenOps maps Len comparisons to SQL operators.
+var sqlLenOps = map[Op]string{Eq: "=", Ne: "<>", Gt: ">", Gte: ">=", Lt: "<", Lte: "<="}
+
+// Len creates a filter comparing the number of elements of an array field
+// with n, e.g. Len(Gte, 3) for "at least three tags". op must be Eq, Ne,
+// Gt, Gte, Lt, or Lte, and n non-negative. Not every backend can count
+// array elements: ToSQL, CompileToJSONB, CompileToSQLite,
+// CompileToDynamoDB, and ToMongo support it, and the other compilers
+// return ErrInvalidFilter.
+func (fb *FieldBuilder[T]) Len(op Op, n int) *Filter {
+	value := LenValue{Op: op, N: n}
+	filter := fb.makeFilter(Len, value)
+	if filter.err == nil && filter.op == Len {
+		if err := value.validate(); err != nil {
+			filter.err = fmt.Errorf("%w: field %s: %w", ErrInvalidFilter, fb.field, err)
+		}
+	}
+	return filter
+}
+
+// lenValue extracts the LenValue of a Len filter.
+func lenValue(f *Filter) (LenValue, error) {
+	v, ok := f.value.(LenValue)
+	if !ok {
+		return LenValue{}, fmt.Errorf("%w: %s requires a LenValue, got %T", ErrInvalidFilter, f.op, f.value)
+	}
+	return v, nil
+}
+
+// lenClause renders "size op placeholder" for the SQL-like compilers,
+// binding the count with bind.
+func lenClause(f *Filter, size string, bind func(any) string) (string, error) {
+	v, err := lenValue(f)
+	if err != nil {
+		return "", err
+	}
+	return size + " " + sqlLenOps[v.Op] + " " + bind(v.N), nil
+}
+
+// fromLenSpec converts a len spec, whose value is a LenValue or a decoded
+// JSON object with op and n keys, to a Filter.
+func (b *Builder[T]) fromLenSpec(spec *FilterSpec) *Filter {
+	value, ok := spec.Value.(LenValue)
+	if !ok {
+		data, err := json.Marshal(spec.Value)
+		if err == nil {
+			err = json.Unmarshal(data, &value)
+		}
+		if err != nil {
+			return &Filter{
+				op:    Len,
+				field: spec.Field,
+				value: spec.Value,
+				err:   fmt.Errorf("%w: len requires an {\"op\", \"n\"} value: %w", ErrInvalidFilter, err),
+			}
+		}
+	}
+	return b.Where(spec.Field).Len(value.Op, value.N)
+}
@@ -0,0 +1,146 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestFieldBuilder_Len(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		tags   []string
+		want   bool
+	}{
+		{"eq", builder.Where("tags").Len(Eq, 2), []string{"a", "b"}, true},
+		{"eq miss", builder.Where("tags").Len(Eq, 2), []string{"a"}, false},
+		{"ne", builder.Where("tags").Len(Ne, 0), []string{"a"}, true},
+		{"gt", builder.Where("tags").Len(Gt, 1), []string{"a"}, false},
+		{"gte", builder.Where("tags").Len(Gte, 3), []string{"a", "b", "c"}, true},
+		{"gte miss", builder.Where("tags").Len(Gte, 3), []string{"a", "b"}, false},
+		{"lt", builder.Where("tags").Len(Lt, 1), nil, true},
+		{"lte", builder.Where("tags").Len(Lte, 1), []string{"a", "b"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.filter.Err() != nil {
+				t.Fatalf("Filter.Err() = %v, want nil", tt.filter.Err())
+			}
+			got, err := builder.Match(tt.filter, testMetadata{Tags: tt.tags})
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match(%v) = %v, want %v", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldBuilder_Len_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		wantErr error
+	}{
+		{"not a slice", builder.Where("category").Len(Gte, 1), ErrInvalidFilter},
+		{"unsupported op", builder.Where("tags").Len(In, 1), ErrInvalidFilter},
+		{"negative", builder.Where("tags").Len(Gte, -1), ErrInvalidFilter},
+		{"unknown field", builder.Where("missing").Len(Gte, 1), ErrFieldNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Err(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Filter.Err() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFieldBuilder_Len_Compile(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	filter := builder.Where("tags").Len(Gte, 3)
+
+	sql, args, err := builder.ToSQL(filter)
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if want := `cardinality("Tags") >= $1`; sql != want {
+		t.Errorf("ToSQL() = %s, want %s", sql, want)
+	}
+	if len(args) != 1 || args[0] != 3 {
+		t.Errorf("ToSQL() args = %v, want [3]", args)
+	}
+
+	jsonb, _, err := CompileToJSONB(filter, "metadata")
+	if err != nil {
+		t.Fatalf("CompileToJSONB() error = %v", err)
+	}
+	if want := `jsonb_array_length("metadata"->'tags') >= $1`; jsonb != want {
+		t.Errorf("CompileToJSONB() = %s, want %s", jsonb, want)
+	}
+
+	sqlite, _, err := CompileToSQLite(builder.Where("tags").Len(Ne, 0), "meta")
+	if err != nil {
+		t.Fatalf("CompileToSQLite() error = %v", err)
+	}
+	if want := `json_array_length("meta", '$.tags') <> ?`; sqlite != want {
+		t.Errorf("CompileToSQLite() = %s, want %s", sqlite, want)
+	}
+
+	expr, _, values, err := CompileToDynamoDB(filter)
+	if err != nil {
+		t.Fatalf("CompileToDynamoDB() error = %v", err)
+	}
+	if want := `size(#n0) >= :v0`; expr != want || values[":v0"] != 3 {
+		t.Errorf("CompileToDynamoDB() = %s, %v, want %s, map[:v0:3]", expr, values, want)
+	}
+
+	if _, err := CompileToMeili(filter); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("CompileToMeili() error = %v, want %v", err, ErrInvalidFilter)
+	}
+	if got, want := filter.String(), `tags LEN >= 3`; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+}
+
+func TestBuilder_FromSpec_Len(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	var spec FilterSpec
+	if err := json.Unmarshal([]byte(`{"op": "len", "field": "tags", "value": {"op": "gte", "n": 2}}`), &spec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	filter := builder.FromSpec(&spec)
+	if filter.Err() != nil {
+		t.Fatalf("FromSpec() error = %v", filter.Err())
+	}
+	if v, ok := filter.Value().(LenValue); !ok || v != (LenValue{Op: Gte, N: 2}) {
+		t.Errorf("Filter.Value() = %#v, want LenValue{Op: Gte, N: 2}", filter.Value())
+	}
+
+	data, err := filter.ToCompact()
+	if err != nil {
+		t.Fatalf("ToCompact() error = %v", err)
+	}
+	if want := `["len","tags",{"op":"gte","n":2}]`; string(data) != want {
+		t.Errorf("ToCompact() = %s, want %s", data, want)
+	}
+	if rebuilt := builder.FromCompact(data); !rebuilt.Equal(filter) {
+		t.Errorf("FromCompact(ToCompact()) = %s, want %s", rebuilt, filter)
+	}
+
+	for _, value := range []any{"3", map[string]any{"op": "gte"}, map[string]any{"op": "like", "n": 1}, map[string]any{"op": "gte", "n": 1.5}} {
+		bad := builder.FromSpec(&FilterSpec{Op: "len", Field: "tags", Value: value})
+		if !errors.Is(bad.Err(), ErrInvalidFilter) {
+			t.Errorf("FromSpec(%v).Err() = %v, want %v", value, bad.Err(), ErrInvalidFilter)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package vecna
+
+import (
+	"fmt"
+	"strings"
+)
+
+// logQuerySpecial lists the characters with meaning in log search syntax.
+const logQuerySpecial = `+-=&|><!(){}[]^"~*?:\/ `
+
+// CompileToLogQuery compiles a filter into a Datadog-style log search string,
+// e.g. @category:tech @score:>=0.5 (@tags:a OR @tags:b) -@status:deleted.
+// Fields are prefixed with @, conjunction is whitespace, disjunction is OR
+// inside parentheses, and negation is a leading -. Gt, Gte, Lt, and Lte
+// render as @field:>value etc.; Between and Approx render as
+// @field:[low TO high]. String values containing spaces or special
+// characters are double-quoted. Like, StartsWith, and EndsWith render as
+// wildcard terms (* and ?) with special characters backslash-escaped, and
+// All as the match-everything query *. Regex, GeoBox, None, and Eq or Ne on
+// slice or unknown fields cannot be expressed and return ErrInvalidFilter.
+func CompileToLogQuery(f *Filter) (string, error) {
+	if err := checkCompilable(f); err != nil {
+		return "", err
+	}
+	if err := checkCaseSensitive(f, "log query"); err != nil {
+		return "", err
+	}
+	if err := checkNoEntries(f, "log query"); err != nil {
+		return "", err
+	}
+	return compileLogQuery(f)
+}
+
+// compileLogQuery renders a single filter node.
+func compileLogQuery(f *Filter) (string, error) {
+	switch f.op {
+	case And, Or:
+		if len(f.children) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(f.children))
+		for i, child := range f.children {
+			part, err := compileLogQuery(child)
+			if err != nil {
+				return "", err
+			}
+			if isGroup(child) || child.op == Xor {
+				part = "(" + part + ")"
+			}
+			parts[i] = part
+		}
+		if f.op == Or {
+			return strings.Join(parts, " OR "), nil
+		}
+		return strings.Join(parts, " "), nil
+	case Not:
+		if len(f.children) != 1 {
+			return "", fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := compileLogQuery(f.children[0])
+		if err != nil {
+			return "", err
+		}
+		return "-(" + inner + ")", nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return "", err
+		}
+		return compileLogQuery(expanded)
+	case Raw:
+		return rawClause(f, "logquery")
+	case All:
+		return "*", nil
+	}
+
+	field := "@" + logQueryEscape(f.field)
+
+	switch f.op {
+	case Eq, Ne:
+		if f.kind == KindSlice || f.kind == KindUnknown {
+			return "", fmt.Errorf("%w: operator %s on %s field %s not supported by log query", ErrInvalidFilter, f.op, f.kind, f.field)
+		}
+		term, err := logQueryTerm(field, "", f.value)
+		if err != nil {
+			return "", err
+		}
+		if f.op == Ne {
+			return "-" + term, nil
+		}
+		return term, nil
+	case Gt:
+		return logQueryTerm(field, ">", f.value)
+	case Gte:
+		return logQueryTerm(field, ">=", f.value)
+	case Lt:
+		return logQueryTerm(field, "<", f.value)
+	case Lte:
+		return logQueryTerm(field, "<=", f.value)
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return "", err
+		}
+		lower, err := scalarLiteral(low, logQueryString)
+		if err != nil {
+			return "", err
+		}
+		upper, err := scalarLiteral(high, logQueryString)
+		if err != nil {
+			return "", err
+		}
+		return field + ":[" + lower + " TO " + upper + "]", nil
+	case In, ContainsAny:
+		return logQueryList(field, f.value, " OR ")
+	case Nin:
+		list, err := logQueryList(field, f.value, " OR ")
+		if err != nil {
+			return "", err
+		}
+		return "-" + list, nil
+	case Contains:
+		return logQueryTerm(field, "", f.value)
+	case ContainsAll:
+		return logQueryList(field, f.value, " ")
+	case Like:
+		pattern, ok := f.value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: like requires string pattern, got %T", ErrInvalidFilter, f.value)
+		}
+		return field + ":" + logQueryWildcard(pattern), nil
+	case Prefix, Suffix:
+		affix, ok := f.value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		if f.op == Prefix {
+			return field + ":" + logQueryEscape(affix) + "*", nil
+		}
+		return field + ":*" + logQueryEscape(affix), nil
+	default:
+		return "", fmt.Errorf("%w: operator %s not supported by log query", ErrInvalidFilter, f.op)
+	}
+}
+
+// logQueryTerm renders "@field:<op>literal".
+func logQueryTerm(field, op string, value any) (string, error) {
+	lit, err := scalarLiteral(value, logQueryString)
+	if err != nil {
+		return "", err
+	}
+	return field + ":" + op + lit, nil
+}
+
+// logQueryList renders one term per value joined by sep, in parentheses
+// when there is more than one.
+func logQueryList(field string, value any, sep string) (string, error) {
+	values, err := sliceValues(value)
+	if err != nil {
+		return "", err
+	}
+	if len(values) == 0 {
+		return "", fmt.Errorf("%w: log query requires at least one value for field %s", ErrInvalidFilter, strings.TrimPrefix(field, "@"))
+	}
+	terms := make([]string, len(values))
+	for i, v := range values {
+		if terms[i], err = logQueryTerm(field, "", v); err != nil {
+			return "", err
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return "(" + strings.Join(terms, sep) + ")", nil
+}
+
+// logQueryString renders a string value, double-quoting it when it is empty
+// or contains whitespace or special characters.
+func logQueryString(s string) string {
+	if s != "" && !strings.ContainsAny(s, logQuerySpecial+"\t\n") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// logQueryEscape backslash-escapes special characters, for field names and
+// wildcard terms, which cannot be quoted.
+func logQueryEscape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(logQuerySpecial, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// logQueryWildcard converts a LIKE pattern into a wildcard term.
+func logQueryWildcard(pattern string) string {
+	var sb strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteByte('*')
+		case '_':
+			sb.WriteByte('?')
+		default:
+			sb.WriteString(logQueryEscape(string(r)))
+		}
+	}
+	return sb.String()
+}
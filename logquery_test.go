@@ -0,0 +1,108 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestCompileToLogQuery(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"eq", builder.Where("category").Eq("tech"), `@category:tech`},
+		{"all", builder.All(), `*`},
+		{"ne", builder.Where("category").Ne("deleted"), `-@category:deleted`},
+		{"gt", builder.Where("score").Gt(0.5), `@score:>0.5`},
+		{"gte", builder.Where("score").Gte(0.5), `@score:>=0.5`},
+		{"lt", builder.Where("count").Lt(10), `@count:<10`},
+		{"lte", builder.Where("count").Lte(10), `@count:<=10`},
+		{"bool", builder.Where("active").Eq(true), `@active:true`},
+		{"between", builder.Where("count").Between(1, 10), `@count:[1 TO 10]`},
+		{"approx", builder.Where("score").Approx(0.5, 0.25), `@score:[0.25 TO 0.75]`},
+		{"in", builder.Where("category").In("a", "b"), `(@category:a OR @category:b)`},
+		{"in single", builder.Where("category").In("a"), `@category:a`},
+		{"nin", builder.Where("category").Nin("a", "b"), `-(@category:a OR @category:b)`},
+		{"contains", builder.Where("tags").Contains("go"), `@tags:go`},
+		{"contains all", builder.Where("tags").ContainsAll("go", "db"), `(@tags:go @tags:db)`},
+		{"contains any", builder.Where("tags").ContainsAny("go", "db"), `(@tags:go OR @tags:db)`},
+		{"like", builder.Where("category").Like("te_h%"), `@category:te?h*`},
+		{"prefix", builder.Where("category").StartsWith("a b"), `@category:a\ b*`},
+		{"suffix", builder.Where("category").EndsWith("ch"), `@category:*ch`},
+		{"quoted", builder.Where("category").Eq("machine learning"), `@category:"machine learning"`},
+		{"escaping", builder.Where("category").Eq(`say "hi"`), `@category:"say \"hi\""`},
+		{"empty string", builder.Where("category").Eq(""), `@category:""`},
+		{"not", builder.Not(builder.Where("active").Eq(true)), `-(@active:true)`},
+		{
+			"disjunction",
+			builder.Or(
+				builder.Where("category").Eq("tech"),
+				builder.Where("score").Gte(0.5),
+			),
+			`@category:tech OR @score:>=0.5`,
+		},
+		{
+			"grouping",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Where("score").Gte(0.5),
+				builder.Where("tags").ContainsAny("a", "b"),
+				builder.Where("category").Ne("deleted"),
+				builder.Or(
+					builder.Where("count").Gt(3),
+					builder.Where("active").Eq(false),
+				),
+			),
+			`@category:tech @score:>=0.5 (@tags:a OR @tags:b) -@category:deleted (@count:>3 OR @active:false)`,
+		},
+		{"raw", builder.Raw("logquery", json.RawMessage(`"service:api"`)), `(service:api)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CompileToLogQuery(tt.filter)
+			if err != nil {
+				t.Fatalf("CompileToLogQuery() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CompileToLogQuery() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileToLogQuery_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	places, _ := New[placeMetadata]()
+	attributed, _ := New[attributedMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   error
+	}{
+		{"filter error", builder.Where("missing").Eq("x"), ErrFieldNotFound},
+		{"nil filter", nil, ErrInvalidFilter},
+		{"none", builder.None(), ErrInvalidFilter},
+		{"empty group", builder.Or(), ErrInvalidFilter},
+		{"regex", builder.Where("category").Regex("^a"), ErrInvalidFilter},
+		{"slice eq", builder.Where("tags").Eq([]string{"a"}), ErrInvalidFilter},
+		{"geo box", places.GeoBox("lat", "lng", 0, 0, 1, 1), ErrInvalidFilter},
+		{"raw other backend", builder.Raw("sql", json.RawMessage(`"x"`)), ErrInvalidFilter},
+		{"unsupported literal", builder.Where("category").Eq(struct{}{}), ErrInvalidFilter},
+		{"map entry", attributed.Where("attributes.color").Eq("red"), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileToLogQuery(tt.filter)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CompileToLogQuery() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,558 @@
+package vecna
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Match reports whether v satisfies the filter.
+// Fields are read by reflection using their resolved names. Logical
+// operators short-circuit. Returns an error if the filter carries a
+// construction error, so callers never silently get wrong results.
+func (b *Builder[T]) Match(f *Filter, v T) (bool, error) {
+	if err := checkCompilable(f); err != nil {
+		return false, err
+	}
+	return b.matchValue(f, v)
+}
+
+// matchValue evaluates an already-validated filter against v.
+func (b *Builder[T]) matchValue(f *Filter, v T) (bool, error) {
+	lookup, err := b.structLookup(v)
+	if err != nil {
+		return false, err
+	}
+	return matchTree(f, lookup)
+}
+
+// structLookup returns a fieldLookup reading fields of v by reflection.
+func (b *Builder[T]) structLookup(v T) (fieldLookup, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("%w: cannot match nil %T", ErrInvalidFilter, v)
+		}
+		rv = rv.Elem()
+	}
+
+	return func(name string) (any, bool, error) {
+		return b.fieldValue(rv, name)
+	}, nil
+}
+
+// MatchMap reports whether a decoded record satisfies the filter.
+// Each field condition is resolved by map key and compared according to the
+// field's FieldKind, so JSON numbers (float64) compare correctly against int
+// fields. A missing key, or a value whose type does not fit the field's kind,
+// is treated as absent: absent fields match only Ne, Nin, and NotLike,
+// mirroring MongoDB semantics where "not equal" includes documents lacking
+// the field.
+func (b *Builder[T]) MatchMap(f *Filter, m map[string]any) (bool, error) {
+	if err := checkCompilable(f); err != nil {
+		return false, err
+	}
+
+	return matchTree(f, func(name string) (any, bool, error) {
+		spec, ok := b.lookupField(name)
+		if !ok {
+			return nil, false, fmt.Errorf("%w: %s", ErrFieldNotFound, name)
+		}
+		value, ok := m[name]
+		if field, key, isEntry := b.mapPath(name); !ok && isEntry {
+			entries, _ := m[field].(map[string]any)
+			value, ok = entries[key]
+		}
+		if !ok || !valueFitsKind(value, spec.Kind) {
+			return nil, false, nil
+		}
+		return value, true, nil
+	})
+}
+
+// fieldLookup resolves the actual value of a field.
+// present is false when the field is absent from the record.
+type fieldLookup func(name string) (value any, present bool, err error)
+
+// matchTree evaluates a filter tree, short-circuiting logical operators.
+func matchTree(f *Filter, lookup fieldLookup) (bool, error) {
+	switch f.op {
+	case And:
+		for _, child := range f.children {
+			ok, err := matchTree(child, lookup)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case Or:
+		for _, child := range f.children {
+			ok, err := matchTree(child, lookup)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case Not:
+		if len(f.children) != 1 {
+			return false, fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		ok, err := matchTree(f.children[0], lookup)
+		return !ok, err
+	case Xor:
+		if len(f.children) != 2 {
+			return false, fmt.Errorf("%w: %s requires exactly two children", ErrInvalidFilter, f.op)
+		}
+		a, err := matchTree(f.children[0], lookup)
+		if err != nil {
+			return false, err
+		}
+		b, err := matchTree(f.children[1], lookup)
+		return a != b, err
+	}
+
+	_, _, ok, err := evalLeaf(f, lookup)
+	return ok, err
+}
+
+// evalLeaf resolves a field condition's actual value and evaluates it.
+func evalLeaf(f *Filter, lookup fieldLookup) (actual any, present, ok bool, err error) {
+	switch f.op {
+	case GeoBox:
+		return evalGeoBox(f, lookup)
+	case Raw:
+		return nil, false, false, fmt.Errorf("%w: %s predicates cannot be evaluated in memory", ErrInvalidFilter, f.op)
+	case All, None:
+		return nil, false, f.op == All, nil
+	}
+	actual, present, err = lookup(f.field)
+	if err != nil {
+		return nil, false, false, err
+	}
+	if !present {
+		// Absent fields only satisfy negative conditions
+		return nil, false, f.op == Ne || f.op == Nin || f.op == NotLike, nil
+	}
+	ok, err = evalCondition(f, actual)
+	return actual, true, ok, err
+}
+
+// fieldValue reads the named field from a struct value. Pointers and
+// interfaces are unwrapped to the concrete value they hold, at any depth; a
+// nil anywhere in the chain means the field is absent. A map entry named
+// "field.key" is read from the map by key; a missing key is absent.
+func (b *Builder[T]) fieldValue(rv reflect.Value, name string) (any, bool, error) {
+	index, ok := b.index[name]
+	if !ok {
+		field, key, isEntry := b.mapPath(name)
+		if !isEntry {
+			return nil, false, fmt.Errorf("%w: %s", ErrFieldNotFound, name)
+		}
+		m, present, err := b.fieldValue(rv, field)
+		if err != nil || !present {
+			return nil, false, err
+		}
+		entry, present := mapEntry(reflect.ValueOf(m), key)
+		if !present {
+			return nil, false, nil
+		}
+		return entry.Interface(), true, nil
+	}
+	fv, err := rv.FieldByIndexErr(index)
+	if err != nil {
+		return nil, false, nil // Nil embedded pointer: treat as absent
+	}
+	if !fv.CanInterface() {
+		return nil, false, fmt.Errorf("%w: field %s is unexported and cannot be evaluated", ErrInvalidFilter, name)
+	}
+	for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return nil, false, nil
+		}
+		fv = fv.Elem()
+	}
+	return fv.Interface(), true, nil
+}
+
+// mapEntry reads key from a map with string keys, unwrapping pointers and
+// interfaces as fieldValue does. ok is false when the key is missing or nil.
+func mapEntry(m reflect.Value, key string) (reflect.Value, bool) {
+	entry := m.MapIndex(reflect.ValueOf(key).Convert(m.Type().Key()))
+	if !entry.IsValid() {
+		return entry, false
+	}
+	for entry.Kind() == reflect.Ptr || entry.Kind() == reflect.Interface {
+		if entry.IsNil() {
+			return entry, false
+		}
+		entry = entry.Elem()
+	}
+	return entry, true
+}
+
+// valueFitsKind reports whether a decoded value has a type compatible with kind.
+func valueFitsKind(value any, kind FieldKind) bool {
+	switch kind {
+	case KindString:
+		_, ok := value.(string)
+		return ok
+	case KindInt, KindUint, KindFloat:
+		_, ok := toFloat64(value)
+		return ok
+	case KindBool:
+		_, ok := value.(bool)
+		return ok
+	case KindSlice:
+		k := reflect.ValueOf(value).Kind()
+		return k == reflect.Slice || k == reflect.Array
+	case KindTime:
+		_, err := toTime(value)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+// evalCondition evaluates a field condition against an actual field value.
+func evalCondition(f *Filter, actual any) (bool, error) {
+	if c, ok := actual.(Comparable); ok {
+		if ok, handled, err := evalComparable(f, c); handled {
+			return ok, err
+		}
+	}
+
+	switch f.op {
+	case Eq:
+		return valuesMatch(f, actual, f.value), nil
+	case Ne:
+		return !valuesMatch(f, actual, f.value), nil
+	case Gt, Gte, Lt, Lte:
+		cmp, ok := compareValues(actual, f.value)
+		if !ok {
+			return false, nil
+		}
+		switch f.op {
+		case Gt:
+			return cmp > 0, nil
+		case Gte:
+			return cmp >= 0, nil
+		case Lt:
+			return cmp < 0, nil
+		default:
+			return cmp <= 0, nil
+		}
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return false, err
+		}
+		lower, ok := compareValues(actual, low)
+		if !ok {
+			return false, nil
+		}
+		upper, ok := compareValues(actual, high)
+		if !ok {
+			return false, nil
+		}
+		return lower >= 0 && upper <= 0, nil
+	case In, Nin:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return false, err
+		}
+		found := false
+		for _, v := range values {
+			if valuesMatch(f, actual, v) {
+				found = true
+				break
+			}
+		}
+		return found == (f.op == In), nil
+	case Like:
+		s, ok := actual.(string)
+		pattern, pok := f.value.(string)
+		if !ok || !pok {
+			return false, nil
+		}
+		return likeMatch(s, pattern), nil
+	case ILike:
+		s, ok := actual.(string)
+		pattern, pok := f.value.(string)
+		if !ok || !pok {
+			return false, nil
+		}
+		return likeMatch(strings.ToLower(s), strings.ToLower(pattern)), nil
+	case NotLike:
+		s, ok := actual.(string)
+		pattern, pok := f.value.(string)
+		if !ok || !pok {
+			return true, nil
+		}
+		return !likeMatch(s, pattern), nil
+	case Prefix, Suffix:
+		s, ok := actual.(string)
+		affix, aok := f.value.(string)
+		if !ok || !aok {
+			return false, nil
+		}
+		if f.op == Prefix {
+			return strings.HasPrefix(s, affix), nil
+		}
+		return strings.HasSuffix(s, affix), nil
+	case Regex:
+		re, err := filterRegex(f)
+		if err != nil {
+			return false, err
+		}
+		s, ok := actual.(string)
+		if !ok {
+			return false, nil
+		}
+		return re.MatchString(s), nil
+	case IsEmpty, IsNotEmpty:
+		elems, err := sliceValues(actual)
+		if err != nil {
+			return false, nil
+		}
+		return (len(elems) == 0) == (f.op == IsEmpty), nil
+	case Len:
+		v, err := lenValue(f)
+		if err != nil {
+			return false, err
+		}
+		elems, err := sliceValues(actual)
+		if err != nil {
+			return false, nil
+		}
+		return v.matches(len(elems)), nil
+	case Contains:
+		elems, err := sliceValues(actual)
+		if err != nil {
+			return false, nil
+		}
+		return containsValue(elems, f.value), nil
+	case ContainsAll, ContainsAny:
+		elems, err := sliceValues(actual)
+		if err != nil {
+			return false, nil
+		}
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return false, err
+		}
+		for _, v := range values {
+			if containsValue(elems, v) == (f.op == ContainsAny) {
+				return f.op == ContainsAny, nil
+			}
+		}
+		return f.op == ContainsAll, nil
+	default:
+		return false, fmt.Errorf("%w: operator %s cannot be evaluated", ErrInvalidFilter, f.op)
+	}
+}
+
+// containsValue reports whether elems contains a value equal to v.
+func containsValue(elems []any, v any) bool {
+	for _, elem := range elems {
+		if valuesEqual(elem, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterRegex returns the compiled pattern of a Regex filter, compiling it
+// if the filter was not built through a FieldBuilder.
+func filterRegex(f *Filter) (*regexp.Regexp, error) {
+	if f.regex != nil {
+		return f.regex, nil
+	}
+	pattern, ok := f.value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: regex requires string pattern, got %T", ErrInvalidFilter, f.value)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFilter, err)
+	}
+	return re, nil
+}
+
+// valuesMatch compares an actual value with a filter value, ignoring case
+// between strings if the filter is case-insensitive.
+func valuesMatch(f *Filter, actual, v any) bool {
+	if f.fold {
+		as, aok := actual.(string)
+		vs, vok := v.(string)
+		if aok && vok {
+			return strings.EqualFold(as, vs)
+		}
+	}
+	return valuesEqual(actual, v)
+}
+
+// valuesEqual compares two values, treating all numeric types as comparable.
+func valuesEqual(a, b any) bool {
+	if at, bt, ok := timePair(a, b); ok {
+		return at.Equal(bt)
+	}
+	if c, ok := compareIntegers(reflect.ValueOf(a), reflect.ValueOf(b)); ok {
+		return c == 0
+	}
+	if af, ok := toFloat64(a); ok {
+		if bf, ok := toFloat64(b); ok {
+			return af == bf
+		}
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compareValues orders two numeric or string values.
+// Returns false if the values are not comparable.
+func compareValues(a, b any) (int, bool) {
+	if at, bt, ok := timePair(a, b); ok {
+		return at.Compare(bt), true
+	}
+	if c, ok := compareIntegers(reflect.ValueOf(a), reflect.ValueOf(b)); ok {
+		return c, true
+	}
+	if af, ok := toFloat64(a); ok {
+		bf, ok := toFloat64(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case af < bf:
+			return -1, true
+		case af > bf:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if !aok || !bok {
+		return 0, false
+	}
+	switch {
+	case as < bs:
+		return -1, true
+	case as > bs:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// compareIntegers orders two integer values exactly, including across
+// signed and unsigned kinds, where a float64 conversion would round values
+// beyond 2^53. Returns false unless both values are integers.
+func compareIntegers(a, b reflect.Value) (int, bool) {
+	aSigned, aOK := integerKind(a)
+	bSigned, bOK := integerKind(b)
+	switch {
+	case !aOK || !bOK:
+		return 0, false
+	case aSigned && bSigned:
+		return cmp.Compare(a.Int(), b.Int()), true
+	case !aSigned && !bSigned:
+		return cmp.Compare(a.Uint(), b.Uint()), true
+	case aSigned:
+		if a.Int() < 0 {
+			return -1, true
+		}
+		return cmp.Compare(uint64(a.Int()), b.Uint()), true
+	default:
+		if b.Int() < 0 {
+			return 1, true
+		}
+		return cmp.Compare(a.Uint(), uint64(b.Int())), true
+	}
+}
+
+// integerKind reports whether v is an integer, and if so whether it is signed.
+func integerKind(v reflect.Value) (signed, ok bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// timePair converts a and b to times when either is a time.Time.
+// Returns false if neither is a time or the other cannot be converted.
+func timePair(a, b any) (at, bt time.Time, ok bool) {
+	_, aok := a.(time.Time)
+	_, bok := b.(time.Time)
+	if !aok && !bok {
+		return at, bt, false
+	}
+	at, aerr := toTime(a)
+	bt, berr := toTime(b)
+	return at, bt, aerr == nil && berr == nil
+}
+
+// toFloat64 converts any numeric value to float64.
+func toFloat64(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// likeMatch reports whether s matches a SQL LIKE pattern,
+// where % matches any sequence and _ matches a single character.
+func likeMatch(s, pattern string) bool {
+	// Iterative wildcard matching with single-star backtracking
+	si, pi := 0, 0
+	starP, starS := -1, 0
+	for si < len(s) {
+		if pi < len(pattern) {
+			switch pattern[pi] {
+			case '%':
+				starP, starS = pi, si
+				pi++
+				continue
+			case '_':
+				_, size := utf8.DecodeRuneInString(s[si:])
+				si += size
+				pi++
+				continue
+			default:
+				if pattern[pi] == s[si] {
+					si++
+					pi++
+					continue
+				}
+			}
+		}
+		if starP < 0 {
+			return false
+		}
+		// Backtrack: let the last % absorb one more character
+		_, size := utf8.DecodeRuneInString(s[starS:])
+		starS += size
+		si = starS
+		pi = starP + 1
+	}
+	for pi < len(pattern) && pattern[pi] == '%' {
+		pi++
+	}
+	return pi == len(pattern)
+}
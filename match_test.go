@@ -0,0 +1,588 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBuilder_Match(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	doc := testMetadata{
+		Category: "tech",
+		Score:    0.75,
+		Count:    10,
+		Active:   true,
+		Tags:     []string{"go", "vector"},
+		NoTag:    "plain",
+	}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   bool
+	}{
+		{"eq match", builder.Where("category").Eq("tech"), true},
+		{"eq miss", builder.Where("category").Eq("science"), false},
+		{"eq numeric types", builder.Where("count").Eq(10.0), true},
+		{"eq bool", builder.Where("active").Eq(true), true},
+		{"eq untagged", builder.Where("NoTag").Eq("plain"), true},
+		{"ne", builder.Where("category").Ne("science"), true},
+		{"gt", builder.Where("score").Gt(0.5), true},
+		{"gt boundary", builder.Where("score").Gt(0.75), false},
+		{"gte boundary", builder.Where("score").Gte(0.75), true},
+		{"lt", builder.Where("count").Lt(5), false},
+		{"lte boundary", builder.Where("count").Lte(10), true},
+		{"in", builder.Where("category").In("science", "tech"), true},
+		{"in miss", builder.Where("category").In("science", "art"), false},
+		{"nin", builder.Where("category").Nin("science", "art"), true},
+		{"nin miss", builder.Where("category").Nin("tech"), false},
+		{"like prefix", builder.Where("category").Like("te%"), true},
+		{"like single", builder.Where("category").Like("t_ch"), true},
+		{"like miss", builder.Where("category").Like("sci%"), false},
+		{"like case", builder.Where("category").Like("TE%"), false},
+		{"ilike", builder.Where("category").ILike("TE%"), true},
+		{"ilike single", builder.Where("category").ILike("T_cH"), true},
+		{"ilike miss", builder.Where("category").ILike("SCI%"), false},
+		{"not like prefix", builder.Where("category").NotLike("te%"), false},
+		{"not like single", builder.Where("category").NotLike("t_ch"), false},
+		{"not like miss", builder.Where("category").NotLike("sci%"), true},
+		{"contains", builder.Where("tags").Contains("go"), true},
+		{"contains miss", builder.Where("tags").Contains("rust"), false},
+		{"is empty", builder.Where("tags").IsEmpty(), false},
+		{"is not empty", builder.Where("tags").IsNotEmpty(), true},
+		{"prefix", builder.Where("category").StartsWith("te"), true},
+		{"prefix miss", builder.Where("category").StartsWith("ch"), false},
+		{"suffix", builder.Where("category").EndsWith("ch"), true},
+		{"suffix miss", builder.Where("category").EndsWith("te"), false},
+		{"regex", builder.Where("category").Regex("^t[a-z]+h$"), true},
+		{"regex miss", builder.Where("category").Regex("^sci"), false},
+		{"approx", builder.Where("score").Approx(0.7, 0.125), true},
+		{"approx boundary", builder.Where("score").Approx(0.5, 0.25), true},
+		{"approx beyond boundary", builder.Where("score").Approx(0.5, 0.125), false},
+		{"approx below", builder.Where("score").Approx(1, 0.25), true},
+		{"approx beyond below", builder.Where("score").Approx(1, 0.125), false},
+		{"contains all", builder.Where("tags").ContainsAll("vector", "go"), true},
+		{"contains all miss", builder.Where("tags").ContainsAll("go", "rust"), false},
+		{"contains any", builder.Where("tags").ContainsAny("rust", "go"), true},
+		{"contains any miss", builder.Where("tags").ContainsAny("rust", "zig"), false},
+		{"between", builder.Where("score").Between(0.5, 1), true},
+		{"between boundary", builder.Where("count").Between(1, 10), true},
+		{"between miss", builder.Where("count").Between(11, 20), false},
+		{
+			"and",
+			builder.And(builder.Where("category").Eq("tech"), builder.Where("score").Gte(0.5)),
+			true,
+		},
+		{
+			"and miss",
+			builder.And(builder.Where("category").Eq("tech"), builder.Where("score").Gte(0.9)),
+			false,
+		},
+		{
+			"or",
+			builder.Or(builder.Where("category").Eq("art"), builder.Where("active").Eq(true)),
+			true,
+		},
+		{"not", builder.Not(builder.Where("category").Eq("spam")), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.Match(tt.filter, doc)
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_Match_Xor(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	// Featured or on sale, but not both
+	filter := builder.Xor(
+		builder.Where("active").Eq(true),
+		builder.Where("tags").Contains("sale"),
+	)
+	expanded, err := expandXor(filter)
+	if err != nil {
+		t.Fatalf("expandXor() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		doc  testMetadata
+		want bool
+	}{
+		{"neither", testMetadata{}, false},
+		{"featured", testMetadata{Active: true}, true},
+		{"on sale", testMetadata{Tags: []string{"sale"}}, true},
+		{"both", testMetadata{Active: true, Tags: []string{"sale"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.Match(filter, tt.doc)
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+			if got, _ := builder.Match(expanded, tt.doc); got != tt.want {
+				t.Errorf("Match(expandXor()) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_Match_ShortCircuit(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	doc := testMetadata{Category: "tech"}
+
+	// The second child cannot be evaluated; short-circuiting must skip it
+	unevaluable := &Filter{op: Op(99), field: "category"}
+
+	ok, err := builder.Match(&Filter{op: Or, children: []*Filter{builder.Where("category").Eq("tech"), unevaluable}}, doc)
+	if err != nil || !ok {
+		t.Errorf("Or Match() = %v, %v, want true, nil", ok, err)
+	}
+
+	ok, err = builder.Match(&Filter{op: And, children: []*Filter{builder.Where("category").Eq("art"), unevaluable}}, doc)
+	if err != nil || ok {
+		t.Errorf("And Match() = %v, %v, want false, nil", ok, err)
+	}
+}
+
+func TestBuilder_Match_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	t.Run("filter error", func(t *testing.T) {
+		_, err := builder.Match(builder.Where("missing").Eq("x"), testMetadata{})
+		if !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("Match() error = %v, want %v", err, ErrFieldNotFound)
+		}
+	})
+
+	t.Run("nil filter", func(t *testing.T) {
+		_, err := builder.Match(nil, testMetadata{})
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("Match() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+
+	t.Run("unexported field", func(t *testing.T) {
+		b, _ := New[unexportedMetadata](WithIncludeUnexported())
+		_, err := b.Match(b.Where("tenant").Eq("acme"), unexportedMetadata{tenant: "acme"})
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("Match() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+}
+
+func TestBuilder_Match_IsEmpty(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name string
+		tags []string
+		want bool
+	}{
+		{"nil", nil, true},
+		{"empty", []string{}, true},
+		{"one", []string{"go"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := testMetadata{Tags: tt.tags}
+			if got, err := builder.Match(builder.Where("tags").IsEmpty(), doc); err != nil || got != tt.want {
+				t.Errorf("Match(IsEmpty) = %v, %v, want %v, nil", got, err, tt.want)
+			}
+			if got, err := builder.Match(builder.Where("tags").IsNotEmpty(), doc); err != nil || got == tt.want {
+				t.Errorf("Match(IsNotEmpty) = %v, %v, want %v, nil", got, err, !tt.want)
+			}
+		})
+	}
+
+	// A missing key is absent, which satisfies neither
+	for _, f := range []*Filter{builder.Where("tags").IsEmpty(), builder.Where("tags").IsNotEmpty()} {
+		if got, _ := builder.MatchMap(f, map[string]any{}); got {
+			t.Errorf("MatchMap(%s, {}) = true, want false", f)
+		}
+	}
+}
+
+func TestLikeMatch(t *testing.T) {
+	tests := []struct {
+		s, pattern string
+		want       bool
+	}{
+		{"tech", "tech", true},
+		{"tech", "%", true},
+		{"", "%", true},
+		{"tech", "%ch", true},
+		{"technology", "tech%", true},
+		{"technology", "%nolo%", true},
+		{"tech", "t__h", true},
+		{"tech", "t_h", false},
+		{"tech", "%x%", false},
+		{"aXbXc", "a%b%c", true},
+		{"héllo", "h_llo", true},
+		{"a.b", "a_b", true},
+		{"axb", "a.b", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s+"/"+tt.pattern, func(t *testing.T) {
+			if got := likeMatch(tt.s, tt.pattern); got != tt.want {
+				t.Errorf("likeMatch(%q, %q) = %v, want %v", tt.s, tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_MatchMap(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(`{
+		"category": "tech",
+		"score": 0.75,
+		"count": 10,
+		"active": true,
+		"tags": ["go", "vector"]
+	}`), &record); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   bool
+	}{
+		{"eq string", builder.Where("category").Eq("tech"), true},
+		{"eq int from json float", builder.Where("count").Eq(10), true},
+		{"lt int from json float", builder.Where("count").Lt(11), true},
+		{"gte float", builder.Where("score").Gte(0.75), true},
+		{"in", builder.Where("category").In("art", "tech"), true},
+		{"contains", builder.Where("tags").Contains("vector"), true},
+		{"like", builder.Where("category").Like("%ech"), true},
+		{
+			"nested",
+			builder.And(
+				builder.Where("active").Eq(true),
+				builder.Or(builder.Where("count").Gt(100), builder.Where("score").Lt(1)),
+			),
+			true,
+		},
+		{"missing key eq", builder.Where("NoTag").Eq("x"), false},
+		{"missing key ne", builder.Where("NoTag").Ne("x"), true},
+		{"missing key nin", builder.Where("NoTag").Nin("x"), true},
+		{"missing key in", builder.Where("NoTag").In("x"), false},
+		{"missing key like", builder.Where("NoTag").Like("x%"), false},
+		{"missing key not like", builder.Where("NoTag").NotLike("x%"), true},
+		{"not like", builder.Where("category").NotLike("%ech"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.MatchMap(tt.filter, record)
+			if err != nil {
+				t.Fatalf("MatchMap() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MatchMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_MatchMap_KindMismatch(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	// A string where an int field is expected is treated as absent
+	record := map[string]any{"count": "10"}
+
+	ok, err := builder.MatchMap(builder.Where("count").Eq(10), record)
+	if err != nil || ok {
+		t.Errorf("MatchMap(eq) = %v, %v, want false, nil", ok, err)
+	}
+	ok, err = builder.MatchMap(builder.Where("count").Ne(10), record)
+	if err != nil || !ok {
+		t.Errorf("MatchMap(ne) = %v, %v, want true, nil", ok, err)
+	}
+}
+
+func TestBuilder_MatchMap_FilterError(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	_, err := builder.MatchMap(builder.Where("missing").Eq("x"), map[string]any{})
+	if !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("MatchMap() error = %v, want %v", err, ErrFieldNotFound)
+	}
+}
+
+func TestBuilder_Match_Time(t *testing.T) {
+	builder, _ := New[eventMetadata]()
+	created := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	deleted := created.Add(24 * time.Hour)
+	event := eventMetadata{Name: "launch", CreatedAt: created, DeletedAt: &deleted}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   bool
+	}{
+		{"gte", builder.Where("created_at").Gte("2024-01-01T00:00:00Z"), true},
+		{"lt", builder.Where("created_at").Lt("2024-01-01T00:00:00Z"), false},
+		{"eq other zone", builder.Where("created_at").Eq(created.In(time.FixedZone("X", 3600))), true},
+		{"pointer field", builder.Where("deleted_at").Between("2024-06-01T00:00:00Z", "2024-06-30T00:00:00Z"), true},
+		{"in", builder.Where("created_at").In("2024-06-01T00:00:00Z"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.Match(tt.filter, event)
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("map with rfc3339 string", func(t *testing.T) {
+		got, err := builder.MatchMap(builder.Where("created_at").Gt("2024-01-01T00:00:00Z"),
+			map[string]any{"created_at": "2024-06-01T00:00:00Z"})
+		if err != nil || !got {
+			t.Errorf("MatchMap() = %v, %v, want true", got, err)
+		}
+	})
+}
+
+func TestBuilder_Match_LargeIntegers(t *testing.T) {
+	builder, _ := New[widthMetadata]()
+	doc := widthMetadata{Big: 1<<53 + 1, Huge: math.MaxUint64}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   bool
+	}{
+		{"eq exact", builder.Where("big").Eq(int64(1<<53 + 1)), true},
+		{"eq neighbour", builder.Where("big").Eq(int64(1 << 53)), false},
+		{"ne neighbour", builder.Where("big").Ne(int64(1 << 53)), true},
+		{"gt neighbour", builder.Where("big").Gt(int64(1 << 53)), true},
+		{"lt neighbour", builder.Where("big").Lt(int64(1<<53 + 2)), true},
+		{"in neighbour", builder.Where("big").In(int64(1<<53), int64(1<<53+2)), false},
+		{"between exact", builder.Where("big").Between(int64(1<<53+1), int64(1<<53+1)), true},
+		{"unsigned eq", builder.Where("huge").Eq(uint64(math.MaxUint64)), true},
+		{"unsigned neighbour", builder.Where("huge").Eq(uint64(math.MaxUint64 - 1)), false},
+		{"signed against unsigned", builder.Where("huge").Gt(int64(math.MaxInt64)), true},
+		{"mixed float", builder.Where("big").Gte(1e15), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.Match(tt.filter, doc)
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+			pred, err := builder.Compile(tt.filter)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if got := pred(doc); got != tt.want {
+				t.Errorf("Compile()(doc) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// indirectMetadata holds values behind interfaces and pointer chains.
+type indirectMetadata struct {
+	Label any      `json:"label"`
+	Owner **string `json:"owner"`
+	Rank  *int     `json:"rank"`
+}
+
+func TestBuilder_Match_Indirect(t *testing.T) {
+	builder, _ := New[indirectMetadata]()
+	owner := "ana"
+	ownerPtr := &owner
+	rank := 3
+	var nilOwner *string
+
+	full := indirectMetadata{Label: &owner, Owner: &ownerPtr, Rank: &rank}
+	empty := indirectMetadata{}
+	broken := indirectMetadata{Label: nilOwner, Owner: &nilOwner}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		item   indirectMetadata
+		want   bool
+	}{
+		{"interface holding pointer", builder.Where("label").Eq("ana"), full, true},
+		{"pointer chain", builder.Where("owner").Eq("ana"), full, true},
+		{"pointer chain in", builder.Where("owner").In("bo", "ana"), full, true},
+		{"pointer", builder.Where("rank").Eq(3), full, true},
+		{"pointer ne", builder.Where("rank").Ne(3), full, false},
+		{"nil interface", builder.Where("label").Eq("ana"), empty, false},
+		{"nil interface ne", builder.Where("label").Ne("ana"), empty, true},
+		{"nil pointer", builder.Where("rank").Eq(3), empty, false},
+		{"interface holding nil pointer", builder.Where("label").Eq("ana"), broken, false},
+		{"nil inside chain", builder.Where("owner").Eq("ana"), broken, false},
+		{"nil inside chain nin", builder.Where("owner").Nin("ana"), broken, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.Match(tt.filter, tt.item)
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_Match_MapFields(t *testing.T) {
+	builder, _ := New[attributedMetadata]()
+
+	docs := []attributedMetadata{
+		{
+			Attributes: map[string]string{"color": "red", "a.b": "x"},
+			Scores:     map[string]float64{"relevance": 0.9},
+			Labels:     map[string][]string{"tags": {"go"}},
+			Extra:      map[string]any{"note": 1, "missing": nil},
+		},
+		{Attributes: map[string]string{"color": "blue"}},
+		{},
+	}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   []bool
+	}{
+		{"eq", builder.Where("attributes.color").Eq("red"), []bool{true, false, false}},
+		{"ne", builder.Where("attributes.color").Ne("red"), []bool{false, true, true}},
+		{"dotted key", builder.Where("attributes.a.b").Eq("x"), []bool{true, false, false}},
+		{"numeric values", builder.Where("scores.relevance").Gte(0.5), []bool{true, false, false}},
+		{"nil value", builder.Where("extra.missing").Eq(nil), []bool{false, false, false}},
+		{"slice values", builder.Where("labels.tags").Contains("go"), []bool{true, false, false}},
+		{"interface values", builder.Where("extra.note").Eq(1.0), []bool{true, false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pred, err := builder.Compile(tt.filter)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			for i, doc := range docs {
+				got, err := builder.Match(tt.filter, doc)
+				if err != nil {
+					t.Fatalf("Match() error = %v", err)
+				}
+				if got != tt.want[i] {
+					t.Errorf("Match(docs[%d]) = %v, want %v", i, got, tt.want[i])
+				}
+				if compiled := pred(doc); compiled != tt.want[i] {
+					t.Errorf("Compile()(docs[%d]) = %v, want %v", i, compiled, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBuilder_MatchMap_MapFields(t *testing.T) {
+	builder, _ := New[attributedMetadata]()
+	filter := builder.Where("attributes.color").Eq("red")
+
+	tests := []struct {
+		name   string
+		record map[string]any
+		want   bool
+	}{
+		{"nested", map[string]any{"attributes": map[string]any{"color": "red"}}, true},
+		{"flattened", map[string]any{"attributes.color": "red"}, true},
+		{"other key", map[string]any{"attributes": map[string]any{"size": "red"}}, false},
+		{"not a map", map[string]any{"attributes": "red"}, false},
+		{"missing", map[string]any{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.MatchMap(filter, tt.record)
+			if err != nil {
+				t.Fatalf("MatchMap() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MatchMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_Match_PointerFields(t *testing.T) {
+	builder, _ := New[optionalMetadata]()
+	score := 0.75
+	title := "intro to vectors"
+
+	set := optionalMetadata{Score: &score, Title: &title, Tags: &[]string{"go"}}
+	unset := optionalMetadata{}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		set    bool
+		unset  bool
+	}{
+		{"eq", builder.Where("score").Eq(0.75), true, false},
+		{"ne", builder.Where("score").Ne(0.75), false, true},
+		{"gte", builder.Where("score").Gte(0.5), true, false},
+		{"like", builder.Where("title").Like("intro%"), true, false},
+		{"nin", builder.Where("title").Nin("outro"), true, true},
+		{"contains", builder.Where("tags").Contains("go"), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pred, err := builder.Compile(tt.filter)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			for _, c := range []struct {
+				doc  optionalMetadata
+				want bool
+			}{{set, tt.set}, {unset, tt.unset}} {
+				got, err := builder.Match(tt.filter, c.doc)
+				if err != nil {
+					t.Fatalf("Match() error = %v", err)
+				}
+				if got != c.want {
+					t.Errorf("Match(%+v) = %v, want %v", c.doc, got, c.want)
+				}
+				if compiled := pred(c.doc); compiled != c.want {
+					t.Errorf("Compile()(%+v) = %v, want %v", c.doc, compiled, c.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package vecna
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CompileToMeili compiles a filter into a Meilisearch filter expression,
+// e.g. category = "tech" AND score >= 0.5 AND tags IN ["a", "b"]. Strings
+// are double-quoted with quotes and backslashes escaped. Meilisearch only
+// orders numbers, so times are rendered as Unix seconds and should be
+// indexed that way.
+//
+// Comparisons map to =, !=, >, >=, <, <=; Between and Approx to
+// field low TO high; In and Nin to IN [...] and NOT IN [...]. On array
+// attributes = matches any element, so Contains maps to =, ContainsAny to
+// IN, and ContainsAll to an AND of =. GeoBox maps to _geoBoundingBox, which
+// always reads the _geo attribute whatever the filter's field names. As in
+// Match, != and NOT IN also select documents lacking the attribute. Like,
+// Regex, StartsWith, EndsWith, and Eq or Ne on slice or unknown fields
+// cannot be expressed and return ErrInvalidFilter. Meilisearch filters have
+// no boolean literal either, so All and None also return ErrInvalidFilter;
+// omit the filter for All.
+func CompileToMeili(f *Filter) (string, error) {
+	if err := checkCompilable(f); err != nil {
+		return "", err
+	}
+	if err := checkCaseSensitive(f, "Meilisearch"); err != nil {
+		return "", err
+	}
+	return compileMeili(f)
+}
+
+// compileMeili renders a single filter node.
+func compileMeili(f *Filter) (string, error) {
+	switch f.op {
+	case And, Or:
+		if len(f.children) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(f.children))
+		for i, child := range f.children {
+			part, err := compileMeili(child)
+			if err != nil {
+				return "", err
+			}
+			if isGroup(child) || child.op == Xor {
+				part = "(" + part + ")"
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, " "+strings.ToUpper(f.op.String())+" "), nil
+	case Not:
+		if len(f.children) != 1 {
+			return "", fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := compileMeili(f.children[0])
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + inner + ")", nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return "", err
+		}
+		return compileMeili(expanded)
+	case Raw:
+		return rawClause(f, "meili")
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("_geoBoundingBox([%s, %s], [%s, %s])",
+			formatFloat(box.MaxLat), formatFloat(box.MaxLng), formatFloat(box.MinLat), formatFloat(box.MinLng)), nil
+	}
+
+	switch f.op {
+	case Eq, Ne, Gt, Gte, Lt, Lte:
+		if (f.op == Eq || f.op == Ne) && (f.kind == KindSlice || f.kind == KindUnknown) {
+			return "", fmt.Errorf("%w: operator %s on %s field %s not supported by Meilisearch", ErrInvalidFilter, f.op, f.kind, f.field)
+		}
+		lit, err := meiliLiteral(f.value)
+		if err != nil {
+			return "", err
+		}
+		return f.field + " " + meiliOps[f.op] + " " + lit, nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return "", err
+		}
+		lower, err := meiliLiteral(low)
+		if err != nil {
+			return "", err
+		}
+		upper, err := meiliLiteral(high)
+		if err != nil {
+			return "", err
+		}
+		return f.field + " " + lower + " TO " + upper, nil
+	case In, Nin, ContainsAny:
+		list, err := meiliList(f)
+		if err != nil {
+			return "", err
+		}
+		if f.op == Nin {
+			return f.field + " NOT IN " + list, nil
+		}
+		return f.field + " IN " + list, nil
+	case IsEmpty:
+		return f.field + " IS EMPTY", nil
+	case IsNotEmpty:
+		return f.field + " IS NOT EMPTY", nil
+	case Contains:
+		lit, err := meiliLiteral(f.value)
+		if err != nil {
+			return "", err
+		}
+		return f.field + " = " + lit, nil
+	case ContainsAll:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		if len(values) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one value", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(values))
+		for i, v := range values {
+			lit, err := meiliLiteral(v)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = f.field + " = " + lit
+		}
+		if len(parts) == 1 {
+			return parts[0], nil
+		}
+		return "(" + strings.Join(parts, " AND ") + ")", nil
+	default:
+		return "", fmt.Errorf("%w: operator %s not supported by Meilisearch", ErrInvalidFilter, f.op)
+	}
+}
+
+// meiliOps maps comparison operators to Meilisearch syntax.
+var meiliOps = map[Op]string{
+	Eq:  "=",
+	Ne:  "!=",
+	Gt:  ">",
+	Gte: ">=",
+	Lt:  "<",
+	Lte: "<=",
+}
+
+// meiliList renders the values of a list filter as [a, b, ...].
+func meiliList(f *Filter) (string, error) {
+	values, err := sliceValues(f.value)
+	if err != nil {
+		return "", err
+	}
+	if len(values) == 0 {
+		return "", fmt.Errorf("%w: %s requires at least one value", ErrInvalidFilter, f.op)
+	}
+	lits := make([]string, len(values))
+	for i, v := range values {
+		if lits[i], err = meiliLiteral(v); err != nil {
+			return "", err
+		}
+	}
+	return "[" + strings.Join(lits, ", ") + "]", nil
+}
+
+// meiliLiteral renders a value, with times as Unix seconds.
+func meiliLiteral(value any) (string, error) {
+	if t, ok := value.(time.Time); ok {
+		value = t.Unix()
+	}
+	return scalarLiteral(value, meiliString)
+}
+
+// meiliString double-quotes a string, escaping quotes and backslashes.
+func meiliString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
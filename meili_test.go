@@ -0,0 +1,109 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCompileToMeili(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	places, _ := New[placeMetadata]()
+	events, _ := New[eventMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"eq", builder.Where("category").Eq("tech"), `category = "tech"`},
+		{"ne", builder.Where("category").Ne("deleted"), `category != "deleted"`},
+		{"gt", builder.Where("score").Gt(0.5), `score > 0.5`},
+		{"gte", builder.Where("score").Gte(0.5), `score >= 0.5`},
+		{"lt", builder.Where("count").Lt(10), `count < 10`},
+		{"lte", builder.Where("count").Lte(10), `count <= 10`},
+		{"bool", builder.Where("active").Eq(true), `active = true`},
+		{"between", builder.Where("count").Between(1, 5), `count 1 TO 5`},
+		{"approx", builder.Where("score").Approx(0.5, 0.25), `score 0.25 TO 0.75`},
+		{"in", builder.Where("category").In("a", "b"), `category IN ["a", "b"]`},
+		{"nin", builder.Where("category").Nin("a", "b"), `category NOT IN ["a", "b"]`},
+		{"contains", builder.Where("tags").Contains("go"), `tags = "go"`},
+		{"is empty", builder.Where("tags").IsEmpty(), `tags IS EMPTY`},
+		{"is not empty", builder.Where("tags").IsNotEmpty(), `tags IS NOT EMPTY`},
+		{"contains any", builder.Where("tags").ContainsAny("go", "db"), `tags IN ["go", "db"]`},
+		{"contains all", builder.Where("tags").ContainsAll("go", "db"), `(tags = "go" AND tags = "db")`},
+		{"escaping", builder.Where("category").Eq(`say "hi" \o/`), `category = "say \"hi\" \\o/"`},
+		{"time", events.Where("created_at").Gte(time.Unix(1700000000, 0)), `created_at >= 1700000000`},
+		{"geo box", places.GeoBox("lat", "lng", 10, 20, 11, 21), `_geoBoundingBox([11, 21], [10, 20])`},
+		{"not", builder.Not(builder.Where("active").Eq(true)), `NOT (active = true)`},
+		{
+			"grouping",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Where("score").Gte(0.5),
+				builder.Or(
+					builder.Where("tags").In("a", "b"),
+					builder.Not(builder.Where("count").Between(1, 5)),
+				),
+			),
+			`category = "tech" AND score >= 0.5 AND (tags IN ["a", "b"] OR NOT (count 1 TO 5))`,
+		},
+		{"raw", builder.Raw("meili", json.RawMessage(`"genres EXISTS"`)), `(genres EXISTS)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CompileToMeili(tt.filter)
+			if err != nil {
+				t.Fatalf("CompileToMeili() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CompileToMeili() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileToMeili_MapEntry(t *testing.T) {
+	builder, _ := New[attributedMetadata]()
+
+	got, err := CompileToMeili(builder.Where("attributes.color").Eq("red"))
+	if err != nil {
+		t.Fatalf("CompileToMeili() error = %v", err)
+	}
+	if want := `attributes.color = "red"`; got != want {
+		t.Errorf("CompileToMeili() = %s, want %s", got, want)
+	}
+}
+
+func TestCompileToMeili_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   error
+	}{
+		{"filter error", builder.Where("missing").Eq("x"), ErrFieldNotFound},
+		{"nil filter", nil, ErrInvalidFilter},
+		{"all", builder.All(), ErrInvalidFilter},
+		{"none", builder.None(), ErrInvalidFilter},
+		{"empty group", builder.Or(), ErrInvalidFilter},
+		{"like", builder.Where("category").Like("te%"), ErrInvalidFilter},
+		{"regex", builder.Where("category").Regex("^a"), ErrInvalidFilter},
+		{"prefix", builder.Where("category").StartsWith("a"), ErrInvalidFilter},
+		{"slice eq", builder.Where("tags").Eq([]string{"a"}), ErrInvalidFilter},
+		{"raw other backend", builder.Raw("sql", json.RawMessage(`"x"`)), ErrInvalidFilter},
+		{"unsupported literal", builder.Where("category").Eq(struct{}{}), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileToMeili(tt.filter)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CompileToMeili() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
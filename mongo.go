@@ -0,0 +1,436 @@
+package vecna
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FromMongo parses a MongoDB query document, such as one decoded from
+// JSON, into a filter:
+//
+//	{"category": "tech", "score": {"$gte": 0.8}, "$or": [{"active": true}, {"count": {"$gt": 10}}]}
+//
+// {field: value} means Eq, and {field: {"$op": value, ...}} one condition
+// per operator. $eq, $ne, $gt, $gte, $lt, $lte, $in, $nin, $all, $size,
+// $regex (with $options i, m, and s), and the field-level $not are
+// supported, as are $and, $or, $nor, and a top-level $not taking a single
+// document. As in MongoDB, a scalar matched against an array field tests
+// its elements: $eq becomes Contains, $in ContainsAny, and $ne and $nin
+// their negations. The conditions of a document are ANDed in sorted key
+// order; an empty document yields All.
+//
+// Unknown operators and malformed documents yield ErrInvalidFilter,
+// prefixed with the offending node's location. The result is then
+// validated as by FromSpec.
+func (b *Builder[T]) FromMongo(doc map[string]any) *Filter {
+	spec, err := b.mongoToSpec(doc, "", 1)
+	if err != nil {
+		return &Filter{err: err}
+	}
+	return b.FromSpec(spec)
+}
+
+// mongoToSpec converts the query document at path, depth levels deep, into
+// a FilterSpec.
+func (b *Builder[T]) mongoToSpec(doc map[string]any, path string, depth int) (*FilterSpec, error) {
+	if b.maxDepth > 0 && depth > b.maxDepth {
+		return nil, fmt.Errorf("%w: spec exceeds maximum depth of %d", ErrInvalidFilter, b.maxDepth)
+	}
+
+	var children []*FilterSpec
+	for _, key := range slices.Sorted(maps.Keys(doc)) {
+		keyPath := joinPath(path, key)
+		var (
+			spec *FilterSpec
+			err  error
+		)
+		switch {
+		case key == "$and" || key == "$or" || key == "$nor":
+			spec, err = b.mongoLogicalToSpec(key, doc[key], keyPath, depth)
+		case key == "$not":
+			sub, ok := doc[key].(map[string]any)
+			if !ok {
+				return nil, withPath(keyPath, fmt.Errorf("%w: $not requires a document", ErrInvalidFilter))
+			}
+			if spec, err = b.mongoToSpec(sub, keyPath, depth+1); err == nil {
+				spec = mongoNot(spec)
+			}
+		case strings.HasPrefix(key, "$"):
+			return nil, withPath(path, fmt.Errorf("%w: unsupported operator %s", ErrInvalidFilter, key))
+		default:
+			spec, err = b.mongoFieldToSpec(key, doc[key], keyPath)
+		}
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, spec)
+	}
+	return mongoAnd(children), nil
+}
+
+// mongoLogicalToSpec converts the array of documents under $and, $or, or
+// $nor into a FilterSpec.
+func (b *Builder[T]) mongoLogicalToSpec(key string, value any, path string, depth int) (*FilterSpec, error) {
+	docs, ok := value.([]any)
+	if !ok {
+		return nil, withPath(path, fmt.Errorf("%w: %s requires an array of documents", ErrInvalidFilter, key))
+	}
+	spec := &FilterSpec{Op: "and", Children: make([]*FilterSpec, len(docs))}
+	if key != "$and" {
+		spec.Op = "or"
+	}
+	for i, d := range docs {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		sub, ok := d.(map[string]any)
+		if !ok {
+			return nil, withPath(elemPath, fmt.Errorf("%w: %s requires an array of documents", ErrInvalidFilter, key))
+		}
+		child, err := b.mongoToSpec(sub, elemPath, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		spec.Children[i] = child
+	}
+	if key == "$nor" {
+		return mongoNot(spec), nil
+	}
+	return spec, nil
+}
+
+// mongoFieldToSpec converts the condition on field, either a value to
+// match or a document of operators, into a FilterSpec.
+func (b *Builder[T]) mongoFieldToSpec(field string, value any, path string) (*FilterSpec, error) {
+	ops, ok := value.(map[string]any)
+	if !ok || !isOperatorDoc(ops) {
+		return b.mongoEqSpec(field, value), nil
+	}
+
+	var children []*FilterSpec
+	for _, key := range slices.Sorted(maps.Keys(ops)) {
+		if key == "$options" {
+			if _, ok := ops["$regex"]; !ok {
+				return nil, withPath(path, fmt.Errorf("%w: $options requires $regex", ErrInvalidFilter))
+			}
+			continue
+		}
+		spec, err := b.mongoOpToSpec(field, key, ops, path)
+		if err != nil {
+			return nil, withPath(path, err)
+		}
+		children = append(children, spec)
+	}
+	return mongoAnd(children), nil
+}
+
+// mongoOpToSpec converts the operator key of the operator document ops on
+// field into a FilterSpec.
+func (b *Builder[T]) mongoOpToSpec(field, key string, ops map[string]any, path string) (*FilterSpec, error) {
+	value := ops[key]
+	isSlice := b.isSliceField(field)
+
+	switch key {
+	case "$eq":
+		return b.mongoEqSpec(field, value), nil
+	case "$ne":
+		if isSlice && !isArray(value) {
+			return mongoNot(&FilterSpec{Op: "contains", Field: field, Value: value}), nil
+		}
+		return &FilterSpec{Op: "ne", Field: field, Value: value}, nil
+	case "$gt", "$gte", "$lt", "$lte":
+		return &FilterSpec{Op: key[1:], Field: field, Value: value}, nil
+	case "$in", "$nin", "$all":
+		values, err := sliceValues(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s requires an array", ErrInvalidFilter, key)
+		}
+		switch {
+		case key == "$all":
+			return &FilterSpec{Op: "contains_all", Field: field, Value: values}, nil
+		case isSlice && key == "$in":
+			return &FilterSpec{Op: "contains_any", Field: field, Value: values}, nil
+		case isSlice:
+			return mongoNot(&FilterSpec{Op: "contains_any", Field: field, Value: values}), nil
+		}
+		return &FilterSpec{Op: key[1:], Field: field, Value: values}, nil
+	case "$size":
+		n, ok := toFloat64(value)
+		if !ok || n < 0 || n != math.Trunc(n) {
+			return nil, fmt.Errorf("%w: $size requires a non-negative integer", ErrInvalidFilter)
+		}
+		if n == 0 {
+			return &FilterSpec{Op: "is_empty", Field: field}, nil
+		}
+		return &FilterSpec{Op: "len", Field: field, Value: LenValue{Op: Eq, N: int(n)}}, nil
+	case "$regex":
+		return mongoRegexSpec(field, value, ops["$options"])
+	case "$not":
+		switch v := value.(type) {
+		case string:
+			spec, err := mongoRegexSpec(field, v, nil)
+			if err != nil {
+				return nil, err
+			}
+			return mongoNot(spec), nil
+		case map[string]any:
+			spec, err := b.mongoFieldToSpec(field, v, joinPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			return mongoNot(spec), nil
+		}
+		return nil, fmt.Errorf("%w: $not requires an operator document or pattern", ErrInvalidFilter)
+	default:
+		return nil, fmt.Errorf("%w: unsupported operator %s", ErrInvalidFilter, key)
+	}
+}
+
+// mongoEqSpec returns the equality spec for field, which for a scalar
+// matched against an array field is Contains.
+func (b *Builder[T]) mongoEqSpec(field string, value any) *FilterSpec {
+	if b.isSliceField(field) && !isArray(value) {
+		return &FilterSpec{Op: "contains", Field: field, Value: value}
+	}
+	return &FilterSpec{Op: "eq", Field: field, Value: value}
+}
+
+// isOperatorDoc reports whether a field's condition is a document of
+// operators rather than a value to match.
+func isOperatorDoc(doc map[string]any) bool {
+	for key := range doc {
+		if strings.HasPrefix(key, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+// isArray reports whether value is a slice or array.
+func isArray(value any) bool {
+	k := reflect.ValueOf(value).Kind()
+	return k == reflect.Slice || k == reflect.Array
+}
+
+// isSliceField reports whether field is a known slice field.
+func (b *Builder[T]) isSliceField(field string) bool {
+	spec, ok := b.lookupField(field)
+	return ok && spec.Kind == KindSlice
+}
+
+// mongoRegexSpec converts a $regex pattern and its $options into a regex
+// spec, translating the i, m, and s options to RE2 flags.
+func mongoRegexSpec(field string, pattern, options any) (*FilterSpec, error) {
+	str, ok := pattern.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: $regex requires a string pattern", ErrInvalidFilter)
+	}
+	if options != nil {
+		flags, ok := options.(string)
+		if !ok || strings.Trim(flags, "ims") != "" {
+			return nil, fmt.Errorf("%w: $options must combine i, m, and s, got %v", ErrInvalidFilter, options)
+		}
+		if flags != "" {
+			str = "(?" + flags + ")" + str
+		}
+	}
+	return &FilterSpec{Op: "regex", Field: field, Value: str}, nil
+}
+
+// mongoAnd combines the conditions of one document: none yields all, one
+// stands alone, and several are ANDed.
+func mongoAnd(children []*FilterSpec) *FilterSpec {
+	switch len(children) {
+	case 0:
+		return &FilterSpec{Op: "all"}
+	case 1:
+		return children[0]
+	default:
+		return &FilterSpec{Op: "and", Children: children}
+	}
+}
+
+// mongoNot negates spec.
+func mongoNot(spec *FilterSpec) *FilterSpec {
+	return &FilterSpec{Op: "not", Children: []*FilterSpec{spec}}
+}
+
+// ToMongo compiles a filter into a MongoDB query document, e.g.
+// {"$and": [{"category": {"$eq": "tech"}}, {"score": {"$gte": 0.5}}]}.
+// Values are passed through unconverted for the driver to encode.
+//
+// Comparisons map to $eq, $ne, $gt, $gte, $lt, and $lte; In and Nin to $in
+// and $nin; Between, Approx, and GeoBox to $gte/$lte ranges. Like and
+// ILike map to an anchored $regex with % as .* and _ as . (ILike with the
+// i option), NotLike to its $not, and StartsWith and EndsWith to anchored
+// patterns. On array fields a scalar $eq matches any element, so Contains
+// maps to $eq, ContainsAny to $in, and ContainsAll to $all; IsEmpty maps to
+// $size 0, IsNotEmpty to the existence of element 0, and Len to $size or a
+// $expr comparison. Not maps to a field-level $not where it wraps a single
+// field's operators and to $nor otherwise; All and None map to {} and
+// {"$nor": [{}]}. FromMongo parses the result back into an equivalent
+// filter, though not always with the same operators, except for
+// IsNotEmpty, Len comparisons other than Eq, and Raw.
+//
+// Raw filters for backend "mongo" carry a JSON object that is emitted as
+// is. Case-insensitive comparisons return ErrInvalidFilter, as does any
+// node with a construction error.
+func (f *Filter) ToMongo() (map[string]any, error) {
+	if err := checkCompilable(f); err != nil {
+		return nil, err
+	}
+	if err := checkCaseSensitive(f, "MongoDB"); err != nil {
+		return nil, err
+	}
+	return compileMongo(f)
+}
+
+// compileMongo renders a single filter node.
+func compileMongo(f *Filter) (map[string]any, error) {
+	switch f.op {
+	case And, Or:
+		if len(f.children) == 0 {
+			return nil, fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		docs := make([]any, len(f.children))
+		for i, child := range f.children {
+			doc, err := compileMongo(child)
+			if err != nil {
+				return nil, err
+			}
+			docs[i] = doc
+		}
+		return map[string]any{"$" + f.op.String(): docs}, nil
+	case Not:
+		if len(f.children) != 1 {
+			return nil, fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := compileMongo(f.children[0])
+		if err != nil {
+			return nil, err
+		}
+		return mongoNegate(inner), nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return nil, err
+		}
+		return compileMongo(expanded)
+	case All:
+		return map[string]any{}, nil
+	case None:
+		return map[string]any{"$nor": []any{map[string]any{}}}, nil
+	case Raw:
+		return rawObject(f, "mongo")
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{
+			f.field:      map[string]any{"$gte": box.MinLat, "$lte": box.MaxLat},
+			box.LngField: map[string]any{"$gte": box.MinLng, "$lte": box.MaxLng},
+		}, nil
+	case Len:
+		return mongoLen(f)
+	case IsNotEmpty:
+		return map[string]any{f.field + ".0": map[string]any{"$exists": true}}, nil
+	}
+
+	ops, err := mongoOps(f)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{f.field: ops}, nil
+}
+
+// mongoOps renders the operator document of a field condition.
+func mongoOps(f *Filter) (map[string]any, error) {
+	switch f.op {
+	case Eq, Ne, Gt, Gte, Lt, Lte:
+		return map[string]any{"$" + f.op.String(): f.value}, nil
+	case Contains:
+		return map[string]any{"$eq": f.value}, nil
+	case In, Nin, ContainsAll, ContainsAny:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{mongoListOps[f.op]: values}, nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"$gte": low, "$lte": high}, nil
+	case Like, ILike, NotLike:
+		pattern, ok := f.value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		ops := map[string]any{"$regex": likeRegex(pattern)}
+		switch f.op {
+		case ILike:
+			ops["$options"] = "i"
+		case NotLike:
+			ops = map[string]any{"$not": ops}
+		}
+		return ops, nil
+	case Regex:
+		pattern, ok := f.value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		return map[string]any{"$regex": pattern}, nil
+	case Prefix, Suffix:
+		pattern, err := affixRegex(f)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"$regex": pattern}, nil
+	case IsEmpty:
+		return map[string]any{"$size": 0}, nil
+	default:
+		return nil, fmt.Errorf("%w: operator %s not supported by MongoDB", ErrInvalidFilter, f.op)
+	}
+}
+
+// mongoListOps maps list operators to MongoDB syntax.
+var mongoListOps = map[Op]string{
+	In:          "$in",
+	Nin:         "$nin",
+	ContainsAll: "$all",
+	ContainsAny: "$in",
+}
+
+// mongoLen renders a Len filter: $size for Eq, otherwise a $expr comparison
+// guarded so that documents without the array do not match.
+func mongoLen(f *Filter) (map[string]any, error) {
+	v, err := lenValue(f)
+	if err != nil {
+		return nil, err
+	}
+	if v.Op == Eq {
+		return map[string]any{f.field: map[string]any{"$size": v.N}}, nil
+	}
+	ref := "$" + f.field
+	return map[string]any{"$expr": map[string]any{"$and": []any{
+		map[string]any{"$isArray": ref},
+		map[string]any{"$" + v.Op.String(): []any{map[string]any{"$size": ref}, v.N}},
+	}}}, nil
+}
+
+// mongoNegate negates a compiled document: a single field's operators are
+// wrapped in $not, anything else in $nor.
+func mongoNegate(doc map[string]any) map[string]any {
+	if len(doc) == 1 {
+		for field, value := range doc {
+			if ops, ok := value.(map[string]any); ok && !strings.HasPrefix(field, "$") && isOperatorDoc(ops) {
+				return map[string]any{field: map[string]any{"$not": ops}}
+			}
+		}
+	}
+	return map[string]any{"$nor": []any{doc}}
+}
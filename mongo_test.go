@@ -0,0 +1,234 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuilder_FromMongo(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"implicit eq", `{"category": "tech"}`, `category == "tech"`},
+		{"eq", `{"category": {"$eq": "tech"}}`, `category == "tech"`},
+		{"ne", `{"category": {"$ne": "spam"}}`, `category != "spam"`},
+		{"comparison", `{"score": {"$gte": 0.5}}`, `score >= 0.5`},
+		{"range", `{"score": {"$gte": 0.2, "$lt": 0.8}}`, `(score >= 0.2 AND score < 0.8)`},
+		{"in", `{"category": {"$in": ["tech", "science"]}}`, `category IN ["tech", "science"]`},
+		{"nin", `{"category": {"$nin": ["spam"]}}`, `category NOT IN ["spam"]`},
+		{"array eq", `{"tags": "go"}`, `tags CONTAINS "go"`},
+		{"array ne", `{"tags": {"$ne": "go"}}`, `NOT (tags CONTAINS "go")`},
+		{"array in", `{"tags": {"$in": ["go", "rust"]}}`, `tags CONTAINS ANY ["go", "rust"]`},
+		{"array nin", `{"tags": {"$nin": ["spam"]}}`, `NOT (tags CONTAINS ANY ["spam"])`},
+		{"all", `{"tags": {"$all": ["go", "rust"]}}`, `tags CONTAINS ALL ["go", "rust"]`},
+		{"size zero", `{"tags": {"$size": 0}}`, `tags IS EMPTY`},
+		{"size", `{"tags": {"$size": 2}}`, `tags LEN = 2`},
+		{"regex", `{"category": {"$regex": "^te"}}`, `category =~ "^te"`},
+		{"regex options", `{"category": {"$regex": "^te", "$options": "i"}}`, `category =~ "(?i)^te"`},
+		{"field not", `{"score": {"$not": {"$gt": 0.5}}}`, `NOT (score > 0.5)`},
+		{"and", `{"$and": [{"category": "tech"}, {"active": true}]}`, `(category == "tech" AND active == true)`},
+		{"or", `{"$or": [{"category": "tech"}, {"count": {"$gt": 10}}]}`, `(category == "tech" OR count > 10)`},
+		{"nor", `{"$nor": [{"category": "spam"}, {"active": false}]}`, `NOT ((category == "spam" OR active == false))`},
+		{"not", `{"$not": {"category": "spam"}}`, `NOT (category == "spam")`},
+		{"sorted keys", `{"score": {"$gte": 0.5}, "category": "tech"}`, `(category == "tech" AND score >= 0.5)`},
+		{"empty", `{}`, `ALL`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc map[string]any
+			if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			filter := builder.FromMongo(doc)
+			if filter.Err() != nil {
+				t.Fatalf("FromMongo() error = %v", filter.Err())
+			}
+			if got := filter.String(); got != tt.want {
+				t.Errorf("FromMongo() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_FromMongo_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr error
+		wantMsg string
+	}{
+		{"unknown field op", `{"score": {"$near": 1}}`, ErrInvalidFilter, "score: vecna: invalid filter: unsupported operator $near"},
+		{"unknown top-level op", `{"$where": "true"}`, ErrInvalidFilter, "unsupported operator $where"},
+		{"and not array", `{"$and": {"category": "tech"}}`, ErrInvalidFilter, "$and requires an array of documents"},
+		{"or element", `{"$or": [{"category": "tech"}, 1]}`, ErrInvalidFilter, "$or[1]"},
+		{"nested op", `{"$or": [{"score": {"$exists": true}}]}`, ErrInvalidFilter, "$or[0].score"},
+		{"in not array", `{"category": {"$in": "tech"}}`, ErrInvalidFilter, "$in requires an array"},
+		{"size fraction", `{"tags": {"$size": 1.5}}`, ErrInvalidFilter, "$size requires a non-negative integer"},
+		{"options alone", `{"category": {"$options": "i"}}`, ErrInvalidFilter, "$options requires $regex"},
+		{"bad options", `{"category": {"$regex": "a", "$options": "x"}}`, ErrInvalidFilter, "$options"},
+		{"not scalar", `{"$not": 1}`, ErrInvalidFilter, "$not requires a document"},
+		{"unknown field", `{"missing": 1}`, ErrFieldNotFound, "missing"},
+		{"invalid op for kind", `{"category": {"$gt": 1}}`, ErrInvalidFilter, "category"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc map[string]any
+			if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			err := builder.FromMongo(doc).Err()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FromMongo() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("FromMongo() error = %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestBuilder_FromMongo_MaxDepth(t *testing.T) {
+	builder, _ := New[testMetadata](WithMaxDepth(2))
+
+	doc := map[string]any{"$or": []any{map[string]any{"$and": []any{map[string]any{"active": true}}}}}
+	if err := builder.FromMongo(doc).Err(); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("FromMongo() error = %v, want %v", err, ErrInvalidFilter)
+	}
+}
+
+func TestFilter_ToMongo(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	tech := builder.Where("category").Eq("tech")
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   map[string]any
+	}{
+		{"eq", tech, map[string]any{"category": map[string]any{"$eq": "tech"}}},
+		{"gte", builder.Where("score").Gte(0.5), map[string]any{"score": map[string]any{"$gte": 0.5}}},
+		{"in", builder.Where("category").In("a", "b"), map[string]any{"category": map[string]any{"$in": []any{"a", "b"}}}},
+		{"nin", builder.Where("count").Nin(1, 2), map[string]any{"count": map[string]any{"$nin": []any{1, 2}}}},
+		{"between", builder.Where("count").Between(1, 5), map[string]any{"count": map[string]any{"$gte": 1, "$lte": 5}}},
+		{"like", builder.Where("category").Like("te_h%"), map[string]any{"category": map[string]any{"$regex": "^te.h.*$"}}},
+		{"ilike", builder.Where("category").ILike("a.b%"), map[string]any{"category": map[string]any{"$regex": `^a\.b.*$`, "$options": "i"}}},
+		{"not like", builder.Where("category").NotLike("x%"), map[string]any{"category": map[string]any{"$not": map[string]any{"$regex": "^x.*$"}}}},
+		{"prefix", builder.Where("category").StartsWith("te"), map[string]any{"category": map[string]any{"$regex": "^te"}}},
+		{"contains", builder.Where("tags").Contains("go"), map[string]any{"tags": map[string]any{"$eq": "go"}}},
+		{"contains any", builder.Where("tags").ContainsAny("a", "b"), map[string]any{"tags": map[string]any{"$in": []any{"a", "b"}}}},
+		{"contains all", builder.Where("tags").ContainsAll("a", "b"), map[string]any{"tags": map[string]any{"$all": []any{"a", "b"}}}},
+		{"is empty", builder.Where("tags").IsEmpty(), map[string]any{"tags": map[string]any{"$size": 0}}},
+		{"is not empty", builder.Where("tags").IsNotEmpty(), map[string]any{"tags.0": map[string]any{"$exists": true}}},
+		{"len eq", builder.Where("tags").Len(Eq, 2), map[string]any{"tags": map[string]any{"$size": 2}}},
+		{"len gte", builder.Where("tags").Len(Gte, 3), map[string]any{"$expr": map[string]any{"$and": []any{
+			map[string]any{"$isArray": "$tags"},
+			map[string]any{"$gte": []any{map[string]any{"$size": "$tags"}, 3}},
+		}}}},
+		{"and", builder.And(tech, builder.Where("active").Eq(true)), map[string]any{"$and": []any{
+			map[string]any{"category": map[string]any{"$eq": "tech"}},
+			map[string]any{"active": map[string]any{"$eq": true}},
+		}}},
+		{"or", builder.Or(tech, builder.Where("count").Gt(10)), map[string]any{"$or": []any{
+			map[string]any{"category": map[string]any{"$eq": "tech"}},
+			map[string]any{"count": map[string]any{"$gt": 10}},
+		}}},
+		{"not field", builder.Not(tech), map[string]any{"category": map[string]any{"$not": map[string]any{"$eq": "tech"}}}},
+		{"not group", builder.Not(builder.Or(tech, tech)), map[string]any{"$nor": []any{map[string]any{"$or": []any{
+			map[string]any{"category": map[string]any{"$eq": "tech"}},
+			map[string]any{"category": map[string]any{"$eq": "tech"}},
+		}}}}},
+		{"all", builder.All(), map[string]any{}},
+		{"none", builder.None(), map[string]any{"$nor": []any{map[string]any{}}}},
+		{"raw", builder.Raw("mongo", []byte(`{"loc": {"$near": [1, 2]}}`)), map[string]any{"loc": map[string]any{"$near": []any{1.0, 2.0}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.filter.ToMongo()
+			if err != nil {
+				t.Fatalf("ToMongo() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToMongo() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_ToMongo_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	folded, _ := New[testMetadata](WithCaseInsensitive("category"))
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		wantErr error
+	}{
+		{"construction error", builder.And(builder.Where("missing").Eq(1)), ErrFieldNotFound},
+		{"case-insensitive", folded.Where("category").Eq("Tech"), ErrInvalidFilter},
+		{"raw for other backend", builder.Raw("sql", []byte(`"1 = 1"`)), ErrInvalidFilter},
+		{"raw not an object", builder.Raw("mongo", []byte(`"x"`)), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.filter.ToMongo(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ToMongo() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilter_ToMongo_RoundTrip(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	filter := builder.And(
+		builder.Where("category").In("tech", "science"),
+		builder.Or(builder.Where("score").Gte(0.5), builder.Not(builder.Where("active").Eq(false))),
+		builder.Where("tags").ContainsAny("go", "rust"),
+	)
+
+	doc, err := filter.ToMongo()
+	if err != nil {
+		t.Fatalf("ToMongo() error = %v", err)
+	}
+	data, _ := json.Marshal(doc)
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	rebuilt := builder.FromMongo(decoded)
+	if rebuilt.Err() != nil {
+		t.Fatalf("FromMongo() error = %v", rebuilt.Err())
+	}
+	if got, want := rebuilt.String(), filter.String(); got != want {
+		t.Errorf("FromMongo(ToMongo()) = %s, want %s", got, want)
+	}
+}
+
+func TestFilter_ToMongo_MapFields(t *testing.T) {
+	builder, _ := New[attributedMetadata]()
+	filter := builder.Where("attributes.color").Eq("red")
+
+	got, err := filter.ToMongo()
+	if err != nil {
+		t.Fatalf("ToMongo() error = %v", err)
+	}
+	want := map[string]any{"attributes.color": map[string]any{"$eq": "red"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToMongo() = %#v, want %#v", got, want)
+	}
+	if rebuilt := builder.FromMongo(got); !rebuilt.Equal(filter) {
+		t.Errorf("FromMongo(ToMongo()) = %s, want %s", rebuilt, filter)
+	}
+}
@@ -0,0 +1,271 @@
+package vecna
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ToODataFilter compiles a filter into an OData $filter expression for
+// Azure AI Search, such as category eq 'tech' and score ge 0.5. Eq, Ne, Gt,
+// Gte, Lt, and Lte map to eq, ne, gt, ge, lt, and le, and And, Or, and Not
+// to and, or, and not, nested groups parenthesized. String literals are
+// single-quoted with embedded quotes doubled, and times are rendered as
+// DateTimeOffset literals. An entry of a KindMap field is addressed as the
+// complex field path field/key.
+//
+// In on strings maps to search.in(category, 'a,b'); when a value contains a
+// space or comma, which search.in splits on by default, a delimiter the
+// values lack is passed as the third argument. In on other values maps to
+// an or of eq conditions, and Nin to the negation of In. On collection
+// fields Contains maps to tags/any(t: t eq 'x'), ContainsAny to
+// tags/any(t: search.in(t, 'a,b')), ContainsAll to one any per value, and
+// IsEmpty and IsNotEmpty to not tags/any() and tags/any(). Between, Approx,
+// and GeoBox render as inclusive ge/le ranges, All and None as true and
+// false, and Xor as (a or b) and not (a and b).
+//
+// Like, ILike, NotLike, Regex, StartsWith, EndsWith, and Len return
+// ErrInvalidFilter, as do case-insensitive comparisons and any node with a
+// construction error. Raw filters for backend "odata" are emitted verbatim.
+func (b *Builder[T]) ToODataFilter(f *Filter) (string, error) {
+	if err := checkCompilable(f); err != nil {
+		return "", err
+	}
+	if err := checkCaseSensitive(f, "OData"); err != nil {
+		return "", err
+	}
+	c := &odataCompiler{path: b.odataPath}
+	return c.compile(f)
+}
+
+// odataPath resolves the OData field path for a field, joining the parts
+// of a KindMap entry with a slash.
+func (b *Builder[T]) odataPath(field string) string {
+	if name, key, ok := b.mapPath(field); ok {
+		return name + "/" + key
+	}
+	return field
+}
+
+// odataCompiler renders a filter tree with the builder's field paths.
+type odataCompiler struct {
+	path func(string) string
+}
+
+// compile renders a single filter node.
+func (c *odataCompiler) compile(f *Filter) (string, error) {
+	switch f.op {
+	case And, Or:
+		if len(f.children) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(f.children))
+		for i, child := range f.children {
+			part, err := c.compile(child)
+			if err != nil {
+				return "", err
+			}
+			if isGroup(child) || child.op == Xor {
+				part = "(" + part + ")"
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, " "+f.op.String()+" "), nil
+	case Not:
+		if len(f.children) != 1 {
+			return "", fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := c.compile(f.children[0])
+		if err != nil {
+			return "", err
+		}
+		return "not (" + inner + ")", nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return "", err
+		}
+		return c.compile(expanded)
+	case All:
+		return "true", nil
+	case None:
+		return "false", nil
+	case Raw:
+		return rawClause(f, "odata")
+	}
+
+	field := c.path(f.field)
+
+	switch f.op {
+	case Eq:
+		return odataComparison(field, "eq", f.value)
+	case Ne:
+		return odataComparison(field, "ne", f.value)
+	case Gt:
+		return odataComparison(field, "gt", f.value)
+	case Gte:
+		return odataComparison(field, "ge", f.value)
+	case Lt:
+		return odataComparison(field, "lt", f.value)
+	case Lte:
+		return odataComparison(field, "le", f.value)
+	case In, Nin:
+		clause, err := odataIn(field, f.value)
+		if err != nil {
+			return "", err
+		}
+		if f.op == Nin {
+			return "not (" + clause + ")", nil
+		}
+		return clause, nil
+	case Contains:
+		if err := checkScalarElements(f, "OData"); err != nil {
+			return "", err
+		}
+		cond, err := odataComparison("t", "eq", f.value)
+		if err != nil {
+			return "", err
+		}
+		return field + "/any(t: " + cond + ")", nil
+	case ContainsAny:
+		if err := checkScalarElements(f, "OData"); err != nil {
+			return "", err
+		}
+		cond, err := odataIn("t", f.value)
+		if err != nil {
+			return "", err
+		}
+		return field + "/any(t: " + cond + ")", nil
+	case ContainsAll:
+		if err := checkScalarElements(f, "OData"); err != nil {
+			return "", err
+		}
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		parts := make([]string, len(values))
+		for i, v := range values {
+			cond, err := odataComparison("t", "eq", v)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = field + "/any(t: " + cond + ")"
+		}
+		return "(" + strings.Join(parts, " and ") + ")", nil
+	case IsEmpty:
+		return "not " + field + "/any()", nil
+	case IsNotEmpty:
+		return field + "/any()", nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return "", err
+		}
+		return odataRange(field, low, high)
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return "", err
+		}
+		lat, err := odataRange(field, box.MinLat, box.MaxLat)
+		if err != nil {
+			return "", err
+		}
+		lng, err := odataRange(c.path(box.LngField), box.MinLng, box.MaxLng)
+		if err != nil {
+			return "", err
+		}
+		return "(" + lat + " and " + lng + ")", nil
+	default:
+		return "", fmt.Errorf("%w: operator %s not supported by OData", ErrInvalidFilter, f.op)
+	}
+}
+
+// odataComparison renders "field op literal".
+func odataComparison(field, op string, value any) (string, error) {
+	lit, err := odataLiteral(value)
+	if err != nil {
+		return "", err
+	}
+	return field + " " + op + " " + lit, nil
+}
+
+// odataRange renders the inclusive range "(field ge low and field le high)".
+func odataRange(field string, low, high any) (string, error) {
+	lower, err := odataComparison(field, "ge", low)
+	if err != nil {
+		return "", err
+	}
+	upper, err := odataComparison(field, "le", high)
+	if err != nil {
+		return "", err
+	}
+	return "(" + lower + " and " + upper + ")", nil
+}
+
+// odataIn renders set membership: search.in for string values, and an or
+// of eq conditions otherwise.
+func odataIn(field string, value any) (string, error) {
+	values, err := sliceValues(value)
+	if err != nil {
+		return "", err
+	}
+	if len(values) == 0 {
+		return "", fmt.Errorf("%w: in requires at least one value", ErrInvalidFilter)
+	}
+
+	strs := make([]string, len(values))
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return odataEqAny(field, values)
+		}
+		strs[i] = s
+	}
+
+	all := strings.Join(strs, "")
+	if !strings.ContainsAny(all, " ,") {
+		return "search.in(" + field + ", " + odataString(strings.Join(strs, ",")) + ")", nil
+	}
+	for _, delim := range odataDelimiters {
+		if !strings.Contains(all, delim) {
+			return "search.in(" + field + ", " + odataString(strings.Join(strs, delim)) + ", " + odataString(delim) + ")", nil
+		}
+	}
+	return odataEqAny(field, values)
+}
+
+// odataDelimiters are the search.in delimiters tried, in order, when the
+// values contain the default space or comma.
+var odataDelimiters = []string{"|", ";", "~", "^"}
+
+// odataEqAny renders membership as "(field eq a or field eq b)".
+func odataEqAny(field string, values []any) (string, error) {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		part, err := odataComparison(field, "eq", v)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = part
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	return "(" + strings.Join(parts, " or ") + ")", nil
+}
+
+// odataLiteral renders a value as an OData literal: strings single-quoted,
+// times as unquoted DateTimeOffset values, and numbers and booleans bare.
+func odataLiteral(value any) (string, error) {
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339Nano), nil
+	}
+	return scalarLiteral(value, odataString)
+}
+
+// odataString single-quotes a string literal, doubling embedded quotes.
+func odataString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,136 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBuilder_ToODataFilter(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"eq", builder.Where("category").Eq("tech"), "category eq 'tech'"},
+		{"ne", builder.Where("category").Ne("tech"), "category ne 'tech'"},
+		{"gt", builder.Where("score").Gt(0.5), "score gt 0.5"},
+		{"gte", builder.Where("score").Gte(0.5), "score ge 0.5"},
+		{"lt", builder.Where("count").Lt(10), "count lt 10"},
+		{"lte", builder.Where("count").Lte(10), "count le 10"},
+		{"bool", builder.Where("active").Eq(true), "active eq true"},
+		{"escaping", builder.Where("category").Eq("o'reilly"), "category eq 'o''reilly'"},
+		{"in", builder.Where("category").In("a", "b"), "search.in(category, 'a,b')"},
+		{"in with spaces", builder.Where("category").In("data science", "ml"), "search.in(category, 'data science|ml', '|')"},
+		{"in escaping", builder.Where("category").In("it's", "b"), "search.in(category, 'it''s,b')"},
+		{"in numeric", builder.Where("count").In(1, 2), "(count eq 1 or count eq 2)"},
+		{"nin", builder.Where("category").Nin("a", "b"), "not (search.in(category, 'a,b'))"},
+		{"contains", builder.Where("tags").Contains("go"), "tags/any(t: t eq 'go')"},
+		{"contains any", builder.Where("tags").ContainsAny("go", "db"), "tags/any(t: search.in(t, 'go,db'))"},
+		{"contains all", builder.Where("tags").ContainsAll("go", "db"), "(tags/any(t: t eq 'go') and tags/any(t: t eq 'db'))"},
+		{"is empty", builder.Where("tags").IsEmpty(), "not tags/any()"},
+		{"is not empty", builder.Where("tags").IsNotEmpty(), "tags/any()"},
+		{"between", builder.Where("count").Between(1, 10), "(count ge 1 and count le 10)"},
+		{"approx", builder.Where("score").Approx(0.5, 0.25), "(score ge 0.25 and score le 0.75)"},
+		{"all", builder.All(), "true"},
+		{"none", builder.None(), "false"},
+		{"raw", builder.Raw("odata", json.RawMessage(`"search.ismatch('go')"`)), "(search.ismatch('go'))"},
+		{
+			"nested",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Or(
+					builder.Where("score").Gt(0.5),
+					builder.Not(builder.Where("active").Eq(false)),
+				),
+			),
+			"category eq 'tech' and (score gt 0.5 or not (active eq false))",
+		},
+		{
+			"xor",
+			builder.Xor(builder.Where("active").Eq(true), builder.Where("count").Eq(0)),
+			"(active eq true or count eq 0) and not (active eq true and count eq 0)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.ToODataFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ToODataFilter() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToODataFilter() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_ToODataFilter_Fields(t *testing.T) {
+	t.Run("map entry", func(t *testing.T) {
+		builder, _ := New[attributedMetadata]()
+		got, err := builder.ToODataFilter(builder.Where("attributes.color").Eq("red"))
+		if err != nil {
+			t.Fatalf("ToODataFilter() error = %v", err)
+		}
+		if want := "attributes/color eq 'red'"; got != want {
+			t.Errorf("ToODataFilter() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("time", func(t *testing.T) {
+		builder, _ := New[eventMetadata]()
+		created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		got, err := builder.ToODataFilter(builder.Where("created_at").Gte(created))
+		if err != nil {
+			t.Fatalf("ToODataFilter() error = %v", err)
+		}
+		if want := "created_at ge 2025-01-02T03:04:05Z"; got != want {
+			t.Errorf("ToODataFilter() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("geo box", func(t *testing.T) {
+		builder, _ := New[placeMetadata]()
+		got, err := builder.ToODataFilter(builder.GeoBox("lat", "lng", 1, 2, 3, 4))
+		if err != nil {
+			t.Fatalf("ToODataFilter() error = %v", err)
+		}
+		if want := "((lat ge 1 and lat le 3) and (lng ge 2 and lng le 4))"; got != want {
+			t.Errorf("ToODataFilter() = %s, want %s", got, want)
+		}
+	})
+}
+
+func TestBuilder_ToODataFilter_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	folded, _ := New[testMetadata](WithCaseInsensitive("category"))
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		wantErr error
+	}{
+		{"filter error", builder.Where("missing").Eq("x"), ErrFieldNotFound},
+		{"nil filter", nil, ErrInvalidFilter},
+		{"like", builder.Where("category").Like("te%"), ErrInvalidFilter},
+		{"not like", builder.Where("category").NotLike("te%"), ErrInvalidFilter},
+		{"regex", builder.Where("category").Regex("^te"), ErrInvalidFilter},
+		{"prefix", builder.Where("category").StartsWith("te"), ErrInvalidFilter},
+		{"len", builder.Where("tags").Len(Gte, 2), ErrInvalidFilter},
+		{"case insensitive", folded.Where("category").Eq("Tech"), ErrInvalidFilter},
+		{"unsupported literal", builder.Where("category").Eq(struct{}{}), ErrInvalidFilter},
+		{"other raw backend", builder.Raw("sql", json.RawMessage(`"1 = 1"`)), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := builder.ToODataFilter(tt.filter); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ToODataFilter() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package vecna
+
+import (
+	"fmt"
+	"maps"
+)
+
+// Option configures a Builder created by New.
+type Option func(*config)
+
+// config holds the settings applied by Options.
+type config struct {
+	columns           map[string]string      // field name -> SQL column name
+	parsers           map[string]ValueParser // field name -> custom value parser
+	includeUnexported bool                   // register tagged unexported fields
+	excludeKinds      map[FieldKind]bool     // field kinds dropped from the spec
+	tags              []string               // struct tags consulted for field names, in priority order
+	failOnUnknown     bool                   // reject schemas with KindUnknown fields
+	allowEmptyIn      bool                   // accept empty In/Nin sets
+	maxDepth          int                    // deepest spec FromSpec accepts; 0 for no limit
+	oneOf             map[string][]any       // field name -> the only values it may be compared with
+	exampleSkipZero   bool                   // FromExample skips listed fields holding zero values
+	optInTag          string                 // tag marking filterable fields in opt-in mode; empty to register all
+	foldFields        map[string]bool        // string fields compared case-insensitively
+	baseFilter        any                    // func(*Builder[T]) *Filter for the builder's T; nil for none
+}
+
+// ValueParser normalizes or validates a filter value for a field.
+// It returns the value to store in the filter, or an error to reject it.
+type ValueParser func(value any) (any, error)
+
+// newConfig applies opts over the default configuration.
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		columns:      make(map[string]string),
+		parsers:      make(map[string]ValueParser),
+		excludeKinds: make(map[FieldKind]bool),
+		oneOf:        make(map[string][]any),
+		foldFields:   make(map[string]bool),
+		tags:         []string{"json"},
+		maxDepth:     DefaultMaxDepth,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithColumn maps a field name to a SQL column name used by ToSQL and
+// ToLanceDB. Fields without a mapping use their Go field name as the column
+// in ToSQL, and their field name in ToLanceDB.
+func WithColumn(field, column string) Option {
+	return func(c *config) {
+		c.columns[field] = column
+	}
+}
+
+// WithIncludeUnexported registers unexported struct fields that carry an
+// explicit json or vecna tag. Prefer `vecna:"name"`, since go vet rejects
+// json tags on unexported fields. Sentinel only reports exported fields, so
+// these are read directly via reflection. Such fields can be used in filters
+// and backend compilers, where only names and kinds matter, but their values
+// cannot be read reflectively for in-memory evaluation.
+func WithIncludeUnexported() Option {
+	return func(c *config) {
+		c.includeUnexported = true
+	}
+}
+
+// WithValueParser registers a parser that normalizes values for a field
+// before they are stored in a filter, for domain formats such as money
+// strings, IP addresses, or versions. It runs for values passed to Where
+// operators and FromSpec; In/Nin values are parsed element by element and
+// Like patterns are left untouched. Parser errors surface as ErrInvalidFilter.
+func WithValueParser(field string, parse ValueParser) Option {
+	return func(c *config) {
+		c.parsers[field] = parse
+	}
+}
+
+// WithEnumNames accepts names in place of the underlying values of an enum
+// field: each value passed to Where operators or FromSpec, including every
+// element of an In/Nin list, must be a key of names and is replaced by the
+// value it maps to. An unknown name, or a value that is not a string, yields
+// ErrInvalidFilter naming the first offending element. It is a ValueParser,
+// so it replaces any parser registered for the field with WithValueParser.
+func WithEnumNames(field string, names map[string]any) Option {
+	lookup := maps.Clone(names)
+	return WithValueParser(field, func(value any) (any, error) {
+		name, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("enum value must be a name, got %T", value)
+		}
+		v, ok := lookup[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown enum name %q", name)
+		}
+		return v, nil
+	})
+}
+
+// WithOneOf restricts a field to a fixed set of values: it may only be
+// filtered with Eq, Ne, In, or Nin, and every value, including each In/Nin
+// element, must be one of values. Other operators and values outside the
+// set yield ErrInvalidFilter. Values are checked after any ValueParser, and
+// numbers compare by value, so a JSON 2.0 matches an allowed 2.
+func WithOneOf(field string, values ...any) Option {
+	return func(c *config) {
+		c.oneOf[field] = append([]any(nil), values...)
+	}
+}
+
+// WithExcludeKinds drops every field whose resolved kind is one of kinds
+// from the filterable spec, e.g. WithExcludeKinds(KindSlice) to keep complex
+// fields out of a public filter API. Filtering an excluded field yields
+// ErrFieldNotFound.
+func WithExcludeKinds(kinds ...FieldKind) Option {
+	return func(c *config) {
+		for _, kind := range kinds {
+			c.excludeKinds[kind] = true
+		}
+	}
+}
+
+// WithTagPriority resolves field names from the listed struct tags in order,
+// falling back to the Go field name when none of them supplies a name, e.g.
+// WithTagPriority("json", "db") during a migration from db to json tags.
+// The first tag present on a field wins, so json:"-" still excludes it.
+// The default is WithTagPriority("json").
+func WithTagPriority(tags ...string) Option {
+	return func(c *config) {
+		c.tags = append([]string(nil), tags...)
+	}
+}
+
+// WithTag sets the primary struct tag used for field names in place of json,
+// e.g. WithTag("bson") for Mongo-backed metadata. Fallback tags are kept.
+func WithTag(tag string) Option {
+	return func(c *config) {
+		if len(c.tags) == 0 {
+			c.tags = []string{tag}
+			return
+		}
+		c.tags[0] = tag
+	}
+}
+
+// WithFallbackTag adds a struct tag consulted after the primary tag and any
+// earlier fallbacks, before the Go field name. WithTag("bson") with
+// WithFallbackTag("json") resolves from bson, then json, then the Go name.
+func WithFallbackTag(tag string) Option {
+	return func(c *config) {
+		c.tags = append(c.tags, tag)
+	}
+}
+
+// WithCaseInsensitive makes Eq, Ne, In, and Nin on the given string fields
+// compare without regard to case, e.g. for category values stored in mixed
+// case. Filters record this (see Filter.CaseInsensitive): Match compares
+// lowercased values and ToSQL emits LOWER(col) = LOWER($1). Other backend
+// compilers return ErrInvalidFilter rather than compare case-sensitively.
+func WithCaseInsensitive(fields ...string) Option {
+	return func(c *config) {
+		for _, field := range fields {
+			c.foldFields[field] = true
+		}
+	}
+}
+
+// WithOptInFiltering registers only fields tagged vecna:"filterable",
+// instead of every field, so new struct fields stay unfilterable until
+// explicitly opted in; others yield ErrFieldNotFound. Names still resolve
+// from the name tags, so json:"source_url" vecna:"filterable" is filtered as
+// source_url. Unexported fields included with WithIncludeUnexported must
+// name themselves first, e.g. vecna:"secret,filterable".
+func WithOptInFiltering() Option {
+	return func(c *config) {
+		c.optInTag = "vecna"
+	}
+}
+
+// WithFilterableTag enables opt-in filtering as WithOptInFiltering does,
+// but reads the filterable option from tag instead of vecna, e.g.
+// WithFilterableTag("search") for search:"filterable".
+func WithFilterableTag(tag string) Option {
+	return func(c *config) {
+		c.optInTag = tag
+	}
+}
+
+// WithFailOnUnknownKind makes New return ErrInvalidSchema, listing each
+// offending field and its Go type, if any field resolves to KindUnknown.
+// Such fields are otherwise registered but cannot be meaningfully filtered;
+// exclude them with a json:"-" tag or WithExcludeKinds(KindUnknown) instead.
+func WithFailOnUnknownKind() Option {
+	return func(c *config) {
+		c.failOnUnknown = true
+	}
+}
+
+// WithAllowEmptyIn accepts In and Nin with an empty set of values, which
+// are otherwise rejected with ErrInvalidFilter as a likely bug. An empty In
+// matches nothing and an empty Nin matches everything. ToSQL also checks it
+// before binding an empty ContainsAll or ContainsAny list.
+func WithAllowEmptyIn() Option {
+	return func(c *config) {
+		c.allowEmptyIn = true
+	}
+}
+
+// WithExampleSkipZero makes FromExample skip listed fields that hold their
+// zero value in the example, as it already does when no fields are listed.
+// Use it when zero means "unset", e.g. for optional form input.
+func WithExampleSkipZero() Option {
+	return func(c *config) {
+		c.exampleSkipZero = true
+	}
+}
+
+// WithBaseFilter sets a filter that every filter built by FromSpec (and so
+// FromCompact, FromJSONWithPos, and FromMongo), FromExample, or FromQuery
+// is ANDed with, with the caller's filter nested under it, e.g. to enforce
+// soft deletion:
+//
+//	vecna.New[Doc](vecna.WithBaseFilter(func(b *vecna.Builder[Doc]) *vecna.Filter {
+//		return b.Where("deleted").Eq(false)
+//	}))
+//
+// fn is called once by New, which fails if the filter has a construction
+// error or fn was written for a different type. NewFromJSONSchema applies
+// it the same way, with fn written for Builder[any]. Filters composed
+// directly with Where, And, and Or are left as built.
+func WithBaseFilter[T any](fn func(b *Builder[T]) *Filter) Option {
+	return func(c *config) {
+		c.baseFilter = fn
+	}
+}
+
+// DefaultMaxDepth is the deepest FilterSpec FromSpec accepts unless
+// WithMaxDepth says otherwise.
+const DefaultMaxDepth = 64
+
+// WithMaxDepth sets the maximum nesting depth of a FilterSpec accepted by
+// FromSpec, counting a single condition as depth 1. Deeper specs are
+// rejected with ErrInvalidFilter before any recursion, so untrusted input
+// cannot exhaust the stack. A limit of 0 or less disables the check.
+func WithMaxDepth(n int) Option {
+	return func(c *config) {
+		c.maxDepth = n
+	}
+}
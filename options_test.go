@@ -0,0 +1,679 @@
+package vecna
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Test metadata struct with a domain-formatted field.
+type pricedMetadata struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+var errBadMoney = errors.New("malformed money value")
+
+// parseMoney normalizes "$1,200.50" style strings to float64.
+func parseMoney(value any) (any, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		cleaned := strings.NewReplacer("$", "", ",", "").Replace(v)
+		f, err := strconv.ParseFloat(cleaned, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", errBadMoney, v)
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", errBadMoney, value)
+	}
+}
+
+func TestWithValueParser(t *testing.T) {
+	builder, err := New[pricedMetadata](WithValueParser("price", parseMoney))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	t.Run("where", func(t *testing.T) {
+		filter := builder.Where("price").Gte("$1,200.50")
+		if filter.Err() != nil {
+			t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+		}
+		if filter.Value() != 1200.5 {
+			t.Errorf("Filter.Value() = %v, want 1200.5", filter.Value())
+		}
+	})
+
+	t.Run("from spec", func(t *testing.T) {
+		filter := builder.FromSpec(&FilterSpec{Op: "lt", Field: "price", Value: "$99"})
+		if filter.Err() != nil {
+			t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+		}
+		if filter.Value() != 99.0 {
+			t.Errorf("Filter.Value() = %v, want 99", filter.Value())
+		}
+	})
+
+	t.Run("in parses each element", func(t *testing.T) {
+		filter := builder.Where("price").In("$1", "$2,000")
+		values, ok := filter.Value().([]any)
+		if !ok || len(values) != 2 || values[0] != 1.0 || values[1] != 2000.0 {
+			t.Errorf("Filter.Value() = %v, want [1 2000]", filter.Value())
+		}
+	})
+
+	t.Run("parser error", func(t *testing.T) {
+		filter := builder.Where("price").Eq("twelve dollars")
+		if !errors.Is(filter.Err(), ErrInvalidFilter) {
+			t.Errorf("Filter.Err() = %v, want %v", filter.Err(), ErrInvalidFilter)
+		}
+		if !errors.Is(filter.Err(), errBadMoney) {
+			t.Errorf("Filter.Err() = %v, want wrapped parser error", filter.Err())
+		}
+	})
+
+	t.Run("other fields unaffected", func(t *testing.T) {
+		filter := builder.Where("name").Eq("$5")
+		if filter.Err() != nil || filter.Value() != "$5" {
+			t.Errorf("Filter = %v, %v, want $5, nil", filter.Value(), filter.Err())
+		}
+	})
+}
+
+func TestWithExcludeKinds(t *testing.T) {
+	builder, err := New[testMetadata](WithExcludeKinds(KindSlice))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	spec := builder.Spec()
+	if spec.Field("tags") != nil {
+		t.Error("Spec.Field(tags) present, want excluded")
+	}
+	if spec.Field("category") == nil {
+		t.Error("Spec.Field(category) = nil, want present")
+	}
+
+	filter := builder.Where("tags").Contains("go")
+	if !errors.Is(filter.Err(), ErrFieldNotFound) {
+		t.Errorf("Filter.Err() = %v, want %v", filter.Err(), ErrFieldNotFound)
+	}
+
+	filter = builder.FromSpec(&FilterSpec{Op: "contains", Field: "tags", Value: "go"})
+	if !errors.Is(filter.Err(), ErrFieldNotFound) {
+		t.Errorf("FromSpec().Err() = %v, want %v", filter.Err(), ErrFieldNotFound)
+	}
+}
+
+// Test metadata struct midway through a db-to-json tag migration.
+type migratingMetadata struct {
+	Category string `json:"category" db:"legacy_category"`
+	Region   string `db:"region"`
+	Secret   string `json:"-" db:"secret"`
+	Plain    string
+}
+
+func TestWithTagPriority(t *testing.T) {
+	builder, err := New[migratingMetadata](WithTagPriority("json", "db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		field   string
+		present bool
+	}{
+		{"json wins", "category", true},
+		{"lower priority tag ignored", "legacy_category", false},
+		{"db fallback", "region", true},
+		{"json dash excludes", "secret", false},
+		{"go name fallback", "Plain", true},
+	}
+
+	spec := builder.Spec()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := spec.Field(tt.field) != nil; got != tt.present {
+				t.Errorf("Spec.Field(%s) present = %v, want %v", tt.field, got, tt.present)
+			}
+		})
+	}
+
+	if field := spec.Field("region"); field != nil && field.GoName != "Region" {
+		t.Errorf("FieldSpec.GoName = %s, want Region", field.GoName)
+	}
+
+	t.Run("default ignores db", func(t *testing.T) {
+		builder, _ := New[migratingMetadata]()
+		spec := builder.Spec()
+		if spec.Field("region") != nil {
+			t.Error("Spec.Field(region) present, want Go name Region")
+		}
+		if spec.Field("Region") == nil {
+			t.Error("Spec.Field(Region) = nil, want present")
+		}
+	})
+}
+
+// Test metadata struct for a Mongo-backed store.
+type mongoMetadata struct {
+	Category string  `bson:"cat" json:"category"`
+	Score    float64 `json:"score"`
+	Count    int
+}
+
+func TestWithTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		present []string
+		absent  []string
+	}{
+		{"bson only", []Option{WithTag("bson")}, []string{"cat", "Score", "Count"}, []string{"category", "score"}},
+		{"bson then json", []Option{WithTag("bson"), WithFallbackTag("json")}, []string{"cat", "score", "Count"}, []string{"category"}},
+		{"fallback before tag", []Option{WithFallbackTag("json"), WithTag("bson")}, []string{"cat", "score"}, []string{"category"}},
+		{"default json", nil, []string{"category", "score"}, []string{"cat"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := New[mongoMetadata](tt.opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			spec := builder.Spec()
+			for _, name := range tt.present {
+				if spec.Field(name) == nil {
+					t.Errorf("Spec.Field(%s) = nil, want present", name)
+				}
+			}
+			for _, name := range tt.absent {
+				if spec.Field(name) != nil {
+					t.Errorf("Spec.Field(%s) present, want absent", name)
+				}
+			}
+		})
+	}
+}
+
+// Test metadata struct with fields that have no filterable kind.
+type opaqueMetadata struct {
+	Name  string         `json:"name"`
+	Attrs map[int]string `json:"attrs"`
+	Blob  complex128     `json:"blob"`
+	Skip  map[string]int `json:"-"`
+}
+
+func TestWithFailOnUnknownKind(t *testing.T) {
+	if _, err := New[opaqueMetadata](); err != nil {
+		t.Fatalf("New() without option error = %v, want nil", err)
+	}
+
+	_, err := New[opaqueMetadata](WithFailOnUnknownKind())
+	if !errors.Is(err, ErrInvalidSchema) {
+		t.Fatalf("New() error = %v, want %v", err, ErrInvalidSchema)
+	}
+	for _, want := range []string{"Attrs (map[int]string)", "Blob (complex128)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("New() error = %v, want it to list %s", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "Skip") {
+		t.Errorf("New() error = %v, want json:\"-\" field omitted", err)
+	}
+
+	t.Run("excluded explicitly", func(t *testing.T) {
+		if _, err := New[opaqueMetadata](WithFailOnUnknownKind(), WithExcludeKinds(KindUnknown)); err != nil {
+			t.Errorf("New() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("json schema", func(t *testing.T) {
+		schema := []byte(`{"properties": {"name": {"type": "string"}, "meta": {"type": "object"}}}`)
+		_, err := NewFromJSONSchema(schema, WithFailOnUnknownKind())
+		if !errors.Is(err, ErrInvalidSchema) || !strings.Contains(err.Error(), "meta (object)") {
+			t.Errorf("NewFromJSONSchema() error = %v, want %v listing meta", err, ErrInvalidSchema)
+		}
+	})
+}
+
+func TestEmptyIn(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+	}{
+		{"variadic", builder.Where("category").In()},
+		{"nin", builder.Where("category").Nin()},
+		{"typed slice", builder.Where("category").In([]string{})},
+		{"json array", builder.FromSpec(&FilterSpec{Op: "in", Field: "category", Value: []any{}})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Err()
+			if !errors.Is(err, ErrInvalidFilter) || !strings.Contains(err.Error(), "requires at least one value") {
+				t.Errorf("Filter.Err() = %v, want %v for empty set", err, ErrInvalidFilter)
+			}
+		})
+	}
+
+	t.Run("WithAllowEmptyIn", func(t *testing.T) {
+		builder, _ := New[testMetadata](WithAllowEmptyIn())
+		in := builder.Where("category").In()
+		nin := builder.Where("category").Nin()
+		if in.Err() != nil || nin.Err() != nil {
+			t.Fatalf("Filter.Err() = %v, %v, want nil", in.Err(), nin.Err())
+		}
+		item := testMetadata{Category: "tech"}
+		if ok, _ := builder.Match(in, item); ok {
+			t.Error("Match(empty In) = true, want false")
+		}
+		if ok, _ := builder.Match(nin, item); !ok {
+			t.Error("Match(empty Nin) = false, want true")
+		}
+	})
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	spec := &FilterSpec{Op: "and", Children: []*FilterSpec{
+		{Op: "or", Children: []*FilterSpec{
+			{Op: "eq", Field: "category", Value: "tech"},
+		}},
+	}}
+
+	builder, _ := New[testMetadata](WithMaxDepth(2))
+	if err := builder.FromSpec(spec).Err(); !errors.Is(err, ErrInvalidFilter) || !strings.Contains(err.Error(), "maximum depth of 2") {
+		t.Errorf("FromSpec().Err() = %v, want maximum depth error", err)
+	}
+
+	builder, _ = New[testMetadata](WithMaxDepth(3))
+	if err := builder.FromSpec(spec).Err(); err != nil {
+		t.Errorf("FromSpec().Err() = %v, want nil", err)
+	}
+
+	builder, _ = New[testMetadata](WithMaxDepth(0))
+	deep := &FilterSpec{Op: "eq", Field: "category", Value: "tech"}
+	for range DefaultMaxDepth {
+		deep = &FilterSpec{Op: "not", Children: []*FilterSpec{deep}}
+	}
+	if err := builder.FromSpec(deep).Err(); err != nil {
+		t.Errorf("FromSpec().Err() = %v, want nil with no limit", err)
+	}
+}
+
+// ticketMetadata has an integer enum field.
+type ticketMetadata struct {
+	Status int    `json:"status"`
+	Title  string `json:"title"`
+}
+
+func TestWithEnumNames(t *testing.T) {
+	builder, err := New[ticketMetadata](WithEnumNames("status", map[string]any{
+		"open":   1,
+		"closed": 2,
+		"merged": 3,
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	t.Run("valid list", func(t *testing.T) {
+		filter := builder.FromSpec(&FilterSpec{Op: "in", Field: "status", Value: []any{"open", "merged"}})
+		if filter.Err() != nil {
+			t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+		}
+		values, ok := filter.Value().([]any)
+		if !ok || len(values) != 2 || values[0] != 1 || values[1] != 3 {
+			t.Errorf("Filter.Value() = %v, want [1 3]", filter.Value())
+		}
+		if ok, _ := builder.Match(filter, ticketMetadata{Status: 3}); !ok {
+			t.Error("Match() = false, want true")
+		}
+	})
+
+	t.Run("one bad name", func(t *testing.T) {
+		filter := builder.FromSpec(&FilterSpec{Op: "in", Field: "status", Value: []any{"open", "archived", "bogus"}})
+		err := filter.Err()
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Fatalf("Filter.Err() = %v, want %v", err, ErrInvalidFilter)
+		}
+		if !strings.Contains(err.Error(), `"archived"`) || strings.Contains(err.Error(), "bogus") {
+			t.Errorf("Filter.Err() = %v, want it to name the first invalid name", err)
+		}
+	})
+
+	t.Run("eq", func(t *testing.T) {
+		filter := builder.Where("status").Eq("closed")
+		if filter.Err() != nil || filter.Value() != 2 {
+			t.Errorf("Filter = %v, %v, want 2", filter.Value(), filter.Err())
+		}
+	})
+
+	t.Run("underlying value rejected", func(t *testing.T) {
+		if err := builder.Where("status").Eq(2).Err(); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("Filter.Err() = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+}
+
+// Test metadata struct first inspected before its msgpack tag is registered.
+type wireMetadata struct {
+	Category string `msgpack:"c" json:"category"`
+	Score    float64
+}
+
+func TestNew_OptionsAfterInspection(t *testing.T) {
+	// The default builder inspects the type before msgpack is registered;
+	// later builders must still see the tag rather than a stale spec.
+	plain, _ := New[wireMetadata]()
+	tagged, _ := New[wireMetadata](WithTag("msgpack"))
+	again, _ := New[wireMetadata]()
+
+	plainSpec, taggedSpec, againSpec := plain.Spec(), tagged.Spec(), again.Spec()
+	if plainSpec.Field("category") == nil || plainSpec.Field("c") != nil {
+		t.Errorf("default Spec.Fields = %v, want category", plainSpec.Fields)
+	}
+	if taggedSpec.Field("c") == nil || taggedSpec.Field("category") != nil {
+		t.Errorf("msgpack Spec.Fields = %v, want c", taggedSpec.Fields)
+	}
+	if againSpec.Field("category") == nil || againSpec.Field("c") != nil {
+		t.Errorf("repeated default Spec.Fields = %v, want category", againSpec.Fields)
+	}
+	if !reflect.DeepEqual(plainSpec, againSpec) {
+		t.Errorf("Spec() = %v, want %v for identical options", againSpec, plainSpec)
+	}
+}
+
+func TestWithOneOf(t *testing.T) {
+	builder, err := New[testMetadata](
+		WithOneOf("category", "tech", "science"),
+		WithOneOf("count", 1, 2, 3),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		valid  bool
+	}{
+		{"eq allowed", builder.Where("category").Eq("tech"), true},
+		{"ne allowed", builder.Where("category").Ne("science"), true},
+		{"in allowed", builder.Where("category").In("tech", "science"), true},
+		{"nin allowed", builder.Where("count").Nin(1, 3), true},
+		{"json number", builder.FromSpec(&FilterSpec{Op: "eq", Field: "count", Value: 2.0}), true},
+		{"unconstrained field", builder.Where("score").Gt(0.5), true},
+		{"eq outside set", builder.Where("category").Eq("art"), false},
+		{"in element outside set", builder.Where("category").In("tech", "art"), false},
+		{"nin element outside set", builder.Where("count").Nin(4), false},
+		{"comparison", builder.Where("count").Gt(1), false},
+		{"between", builder.Where("count").Between(1, 3), false},
+		{"like", builder.Where("category").Like("te%"), false},
+		{"prefix", builder.Where("category").StartsWith("te"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Err()
+			if tt.valid && err != nil {
+				t.Errorf("Filter.Err() = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("Filter.Err() = %v, want %v", err, ErrInvalidFilter)
+			}
+		})
+	}
+}
+
+type accountMetadata struct {
+	Region    string `json:"region" vecna:"filterable"`
+	Plan      string `json:"plan" search:"filterable"`
+	Email     string `json:"email"`
+	CreatedAt int64  `json:"created_at" vecna:"filterable,indexed"`
+	secret    string `vecna:"secret,filterable"`
+	hidden    string `vecna:"hidden"`
+}
+
+func TestWithOptInFiltering(t *testing.T) {
+	_ = accountMetadata{secret: "", hidden: ""}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{"default registers all", nil, []string{"region", "plan", "email", "created_at"}},
+		{"opt in", []Option{WithOptInFiltering()}, []string{"region", "created_at"}},
+		{"custom tag", []Option{WithFilterableTag("search")}, []string{"plan"}},
+		{"with unexported", []Option{WithOptInFiltering(), WithIncludeUnexported()}, []string{"region", "created_at", "secret"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := New[accountMetadata](tt.opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			spec := builder.Spec()
+			var got []string
+			for _, field := range spec.Fields {
+				got = append(got, field.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Spec().Fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("untagged field not found", func(t *testing.T) {
+		builder, _ := New[accountMetadata](WithOptInFiltering())
+		if err := builder.Where("email").Eq("a@b.c").Err(); !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("Where(email).Eq() error = %v, want %v", err, ErrFieldNotFound)
+		}
+		if err := builder.Where("region").Eq("eu").Err(); err != nil {
+			t.Errorf("Where(region).Eq() error = %v", err)
+		}
+	})
+}
+
+func TestWithCaseInsensitive(t *testing.T) {
+	builder, _ := New[testMetadata](WithCaseInsensitive("category", "count"))
+	doc := testMetadata{Category: "Tech", NoTag: "Tech"}
+
+	t.Run("match", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			filter *Filter
+			want   bool
+		}{
+			{"eq", builder.Where("category").Eq("tech"), true},
+			{"eq exact", builder.Where("category").Eq("Tech"), true},
+			{"eq other", builder.Where("category").Eq("science"), false},
+			{"ne", builder.Where("category").Ne("TECH"), false},
+			{"in", builder.Where("category").In("science", "TECH"), true},
+			{"nin", builder.Where("category").Nin("tech"), false},
+			{"spec", builder.FromSpec(&FilterSpec{Op: "eq", Field: "category", Value: "tEcH"}), true},
+			{"other field sensitive", builder.Where("NoTag").Eq("tech"), false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := builder.Match(tt.filter, doc)
+				if err != nil {
+					t.Fatalf("Match() error = %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("Match() = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			filter *Filter
+			want   bool
+		}{
+			{"eq", builder.Where("category").Eq("tech"), true},
+			{"like", builder.Where("category").Like("te%"), false},
+			{"non-string field", builder.Where("count").Eq(1), false},
+			{"unregistered field", builder.Where("NoTag").Eq("tech"), false},
+		}
+		for _, tt := range tests {
+			if got := tt.filter.CaseInsensitive(); got != tt.want {
+				t.Errorf("%s: CaseInsensitive() = %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("sql", func(t *testing.T) {
+		tests := []struct {
+			filter   *Filter
+			wantSQL  string
+			wantArgs []any
+		}{
+			{builder.Where("category").Eq("Tech"), `LOWER("Category") = LOWER($1)`, []any{"Tech"}},
+			{builder.Where("category").Ne("Tech"), `LOWER("Category") <> LOWER($1)`, []any{"Tech"}},
+			{builder.Where("category").In("A", "b"), `LOWER("Category") = ANY($1)`, []any{[]any{"a", "b"}}},
+			{builder.Where("category").Nin("A"), `LOWER("Category") <> ALL($1)`, []any{[]any{"a"}}},
+		}
+		for _, tt := range tests {
+			sql, args, err := builder.ToSQL(tt.filter)
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if sql != tt.wantSQL || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ToSQL() = %s %v, want %s %v", sql, args, tt.wantSQL, tt.wantArgs)
+			}
+		}
+	})
+
+	t.Run("string", func(t *testing.T) {
+		if got, want := builder.Where("category").Eq("tech").String(), `category == "tech" IGNORING CASE`; got != want {
+			t.Errorf("String() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("unsupported backends", func(t *testing.T) {
+		f := builder.And(builder.Where("score").Gt(0.5), builder.Where("category").Eq("tech"))
+		if _, err := CompileToPinot(f); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("CompileToPinot() error = %v, want %v", err, ErrInvalidFilter)
+		}
+		if _, _, err := CompileToJSONB(f, "metadata"); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("CompileToJSONB() error = %v, want %v", err, ErrInvalidFilter)
+		}
+		plan, err := builder.ToBitmapPlan(f)
+		if err != nil || !plan.HasResidual() {
+			t.Errorf("ToBitmapPlan() = %+v, %v, want a residual leaf", plan, err)
+		}
+	})
+}
+
+func TestWithBaseFilter(t *testing.T) {
+	builder, err := New[testMetadata](WithBaseFilter(func(b *Builder[testMetadata]) *Filter {
+		return b.Where("active").Eq(true)
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	t.Run("from spec", func(t *testing.T) {
+		filter := builder.FromSpec(&FilterSpec{Op: "eq", Field: "category", Value: "tech"})
+		if err := filter.Err(); err != nil {
+			t.Fatalf("Filter.Err() = %v, want nil", err)
+		}
+		if got, want := filter.String(), `(active == true AND category == "tech")`; got != want {
+			t.Errorf("FromSpec() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("from compact", func(t *testing.T) {
+		filter := builder.FromCompact([]byte(`["or", ["eq", "category", "tech"], ["gt", "score", 0.5]]`))
+		if got, want := filter.String(), `(active == true AND (category == "tech" OR score > 0.5))`; got != want {
+			t.Errorf("FromCompact() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("from mongo", func(t *testing.T) {
+		filter := builder.FromMongo(map[string]any{"category": "tech", "score": map[string]any{"$gt": 0.5}})
+		if err := filter.Err(); err != nil {
+			t.Fatalf("Filter.Err() = %v, want nil", err)
+		}
+		if got, want := filter.String(), `(active == true AND (category == "tech" AND score > 0.5))`; got != want {
+			t.Errorf("FromMongo() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("from example", func(t *testing.T) {
+		filter := builder.FromExample(testMetadata{Category: "tech"}, "category")
+		if got, want := filter.String(), `(active == true AND (category == "tech"))`; got != want {
+			t.Errorf("FromExample() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("match", func(t *testing.T) {
+		filter := builder.FromSpec(&FilterSpec{Op: "eq", Field: "category", Value: "tech"})
+		for _, tt := range []struct {
+			doc  testMetadata
+			want bool
+		}{
+			{testMetadata{Category: "tech", Active: true}, true},
+			{testMetadata{Category: "tech"}, false},
+			{testMetadata{Category: "science", Active: true}, false},
+		} {
+			if got, _ := builder.Match(filter, tt.doc); got != tt.want {
+				t.Errorf("Match(%+v) = %v, want %v", tt.doc, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("spec error", func(t *testing.T) {
+		filter := builder.FromSpec(&FilterSpec{Op: "eq", Field: "missing", Value: "x"})
+		if !errors.Is(filter.Err(), ErrFieldNotFound) {
+			t.Errorf("Filter.Err() = %v, want %v", filter.Err(), ErrFieldNotFound)
+		}
+	})
+
+	t.Run("where untouched", func(t *testing.T) {
+		filter := builder.Where("category").Eq("tech")
+		if got, want := filter.String(), `category == "tech"`; got != want {
+			t.Errorf("Where().Eq() = %s, want %s", got, want)
+		}
+	})
+}
+
+func TestWithBaseFilter_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+		want error
+	}{
+		{"unknown field", WithBaseFilter(func(b *Builder[testMetadata]) *Filter {
+			return b.Where("deleted").Eq(false)
+		}), ErrFieldNotFound},
+		{"nil filter", WithBaseFilter(func(*Builder[testMetadata]) *Filter {
+			return nil
+		}), ErrInvalidFilter},
+		{"other type", WithBaseFilter(func(b *Builder[pricedMetadata]) *Filter {
+			return b.Where("price").Gt(0)
+		}), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New[testMetadata](tt.opt); !errors.Is(err, tt.want) {
+				t.Errorf("New() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
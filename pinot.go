@@ -0,0 +1,175 @@
+package vecna
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompileToPinot compiles a filter into an Apache Pinot filter expression.
+// Identifiers are double-quoted and string literals single-quoted, e.g.
+// "category" = 'tech' AND "score" >= 0.5 AND "category" IN ('a','b').
+// Contains maps to equality, which Pinot evaluates as "any value matches"
+// on multi-value columns. All and None render as true and false. Operators
+// Pinot cannot express return an error.
+func CompileToPinot(f *Filter) (string, error) {
+	if err := checkCompilable(f); err != nil {
+		return "", err
+	}
+	if err := checkCaseSensitive(f, "Pinot"); err != nil {
+		return "", err
+	}
+	if err := checkNoEntries(f, "Pinot"); err != nil {
+		return "", err
+	}
+	return compilePinot(f)
+}
+
+// compilePinot renders a single filter node.
+func compilePinot(f *Filter) (string, error) {
+	switch f.op {
+	case And, Or:
+		parts := make([]string, len(f.children))
+		for i, child := range f.children {
+			part, err := compilePinot(child)
+			if err != nil {
+				return "", err
+			}
+			if isGroup(child) || child.op == Xor {
+				part = "(" + part + ")"
+			}
+			parts[i] = part
+		}
+		if len(parts) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		return strings.Join(parts, " "+strings.ToUpper(f.op.String())+" "), nil
+	case Not:
+		if len(f.children) != 1 {
+			return "", fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := compilePinot(f.children[0])
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + inner + ")", nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return "", err
+		}
+		return compilePinot(expanded)
+	case Raw:
+		return rawClause(f, "pinot")
+	case All:
+		return "true", nil
+	case None:
+		return "false", nil
+	}
+
+	col := quoteIdent(f.field)
+
+	switch f.op {
+	case Eq, Contains:
+		return pinotComparison(col, "=", f.value)
+	case Ne:
+		return pinotComparison(col, "!=", f.value)
+	case Gt:
+		return pinotComparison(col, ">", f.value)
+	case Gte:
+		return pinotComparison(col, ">=", f.value)
+	case Lt:
+		return pinotComparison(col, "<", f.value)
+	case Lte:
+		return pinotComparison(col, "<=", f.value)
+	case In:
+		return pinotList(col, "IN", f.value)
+	case Nin:
+		return pinotList(col, "NOT IN", f.value)
+	case Like:
+		return pinotComparison(col, "LIKE", f.value)
+	case NotLike:
+		return pinotComparison(col, "NOT LIKE", f.value)
+	case ContainsAny:
+		return pinotList(col, "IN", f.value)
+	case ContainsAll:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		parts := make([]string, len(values))
+		for i, v := range values {
+			if parts[i], err = pinotComparison(col, "=", v); err != nil {
+				return "", err
+			}
+		}
+		return "(" + strings.Join(parts, " AND ") + ")", nil
+	case Regex:
+		lit, err := scalarLiteral(f.value, pinotString)
+		if err != nil {
+			return "", err
+		}
+		return "REGEXP_LIKE(" + col + ", " + lit + ")", nil
+	case Prefix, Suffix:
+		pattern, err := affixRegex(f)
+		if err != nil {
+			return "", err
+		}
+		return "REGEXP_LIKE(" + col + ", " + pinotString(pattern) + ")", nil
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return "", err
+		}
+		lng := quoteIdent(box.LngField)
+		return fmt.Sprintf("(%s BETWEEN %s AND %s AND %s BETWEEN %s AND %s)",
+			col, formatFloat(box.MinLat), formatFloat(box.MaxLat),
+			lng, formatFloat(box.MinLng), formatFloat(box.MaxLng)), nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return "", err
+		}
+		lower, err := scalarLiteral(low, pinotString)
+		if err != nil {
+			return "", err
+		}
+		upper, err := scalarLiteral(high, pinotString)
+		if err != nil {
+			return "", err
+		}
+		return col + " BETWEEN " + lower + " AND " + upper, nil
+	default:
+		return "", fmt.Errorf("%w: operator %s not supported by Pinot", ErrInvalidFilter, f.op)
+	}
+}
+
+// pinotComparison renders "col op literal".
+func pinotComparison(col, op string, value any) (string, error) {
+	lit, err := scalarLiteral(value, pinotString)
+	if err != nil {
+		return "", err
+	}
+	return col + " " + op + " " + lit, nil
+}
+
+// pinotList renders "col IN (a,b)" style membership.
+func pinotList(col, op string, value any) (string, error) {
+	values, err := sliceValues(value)
+	if err != nil {
+		return "", err
+	}
+	lits := make([]string, len(values))
+	for i, v := range values {
+		lit, err := scalarLiteral(v, pinotString)
+		if err != nil {
+			return "", err
+		}
+		lits[i] = lit
+	}
+	return col + " " + op + " (" + strings.Join(lits, ",") + ")", nil
+}
+
+// pinotString single-quotes a string literal, doubling embedded quotes.
+func pinotString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
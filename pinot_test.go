@@ -0,0 +1,103 @@
+package vecna
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompileToPinot(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"eq string", builder.Where("category").Eq("tech"), `"category" = 'tech'`},
+		{"all", builder.All(), `true`},
+		{"none", builder.None(), `false`},
+		{"ne", builder.Where("category").Ne("tech"), `"category" != 'tech'`},
+		{"gte float", builder.Where("score").Gte(0.5), `"score" >= 0.5`},
+		{"lt int", builder.Where("count").Lt(10), `"count" < 10`},
+		{"eq bool", builder.Where("active").Eq(true), `"active" = true`},
+		{"in", builder.Where("category").In("a", "b"), `"category" IN ('a','b')`},
+		{"nin", builder.Where("category").Nin("a", "b"), `"category" NOT IN ('a','b')`},
+		{"in typed", builder.FromSpec(&FilterSpec{Op: "in", Field: "count", Value: []int{1, 2}}), `"count" IN (1,2)`},
+		{"contains", builder.Where("tags").Contains("go"), `"tags" = 'go'`},
+		{"regex", builder.Where("category").Regex("^te"), `REGEXP_LIKE("category", '^te')`},
+		{"prefix", builder.Where("category").StartsWith("a.b"), `REGEXP_LIKE("category", '^a\.b')`},
+		{"suffix", builder.Where("category").EndsWith("ch"), `REGEXP_LIKE("category", 'ch$')`},
+		{"approx", builder.Where("score").Approx(0.5, 0.25), `"score" BETWEEN 0.25 AND 0.75`},
+		{"contains all", builder.Where("tags").ContainsAll("go", "db"), `("tags" = 'go' AND "tags" = 'db')`},
+		{"contains any", builder.Where("tags").ContainsAny("go", "db"), `"tags" IN ('go','db')`},
+		{"between", builder.Where("count").Between(1, 10), `"count" BETWEEN 1 AND 10`},
+		{"like", builder.Where("category").Like("te%"), `"category" LIKE 'te%'`},
+		{"not like", builder.Where("category").NotLike("te%"), `"category" NOT LIKE 'te%'`},
+		{"escaping", builder.Where("category").Eq("o'reilly"), `"category" = 'o''reilly'`},
+		{
+			"and",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Where("score").Gte(0.5),
+				builder.Where("category").In("a", "b"),
+			),
+			`"category" = 'tech' AND "score" >= 0.5 AND "category" IN ('a','b')`,
+		},
+		{
+			"nested",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Or(
+					builder.Where("score").Gt(0.5),
+					builder.Not(builder.Where("active").Eq(false)),
+				),
+			),
+			`"category" = 'tech' AND ("score" > 0.5 OR NOT ("active" = false))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CompileToPinot(tt.filter)
+			if err != nil {
+				t.Fatalf("CompileToPinot() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CompileToPinot() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileToPinot_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	t.Run("filter error", func(t *testing.T) {
+		_, err := CompileToPinot(builder.Where("missing").Eq("x"))
+		if !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("CompileToPinot() error = %v, want %v", err, ErrFieldNotFound)
+		}
+	})
+
+	t.Run("nil filter", func(t *testing.T) {
+		_, err := CompileToPinot(nil)
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("CompileToPinot() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+
+	t.Run("unsupported literal", func(t *testing.T) {
+		_, err := CompileToPinot(builder.Where("category").Eq(struct{}{}))
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("CompileToPinot() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+
+	t.Run("map entry", func(t *testing.T) {
+		attributed, _ := New[attributedMetadata]()
+		_, err := CompileToPinot(attributed.Where("attributes.color").Eq("red"))
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("CompileToPinot() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package vecna
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FilterError locates an error reported by FromJSONWithPos in the source
+// document. Line and Column are 1-based; Column counts bytes.
+type FilterError struct {
+	Line   int
+	Column int
+	Offset int64 // Byte offset of the offending node
+	Err    error
+}
+
+// Error returns the message prefixed with the source position.
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FilterError) Unwrap() error {
+	return e.Err
+}
+
+// FromJSONWithPos decodes a JSON FilterSpec and converts it as FromSpec
+// does, but wraps each construction error in a *FilterError giving the line
+// and column of the offending node in data, for hand-written filter files.
+// Errors inside a $ref are located at the referencing node. Malformed JSON
+// yields ErrInvalidFilter located at the syntax error.
+func (b *Builder[T]) FromJSONWithPos(data []byte) *Filter {
+	var spec FilterSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		var offset int64
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			offset = syntaxErr.Offset - 1 // Offset counts the offending byte
+		case errors.As(err, &typeErr):
+			offset = typeErr.Offset - 1
+		}
+		return &Filter{err: newFilterError(data, offset, fmt.Errorf("%w: %w", ErrInvalidFilter, err))}
+	}
+
+	filter := b.FromSpec(&spec)
+	if filter.Err() == nil {
+		return filter
+	}
+
+	offsets := specOffsets(data)
+	_ = filter.Walk(func(n *Filter) error {
+		if n.err != nil {
+			var pe *pathError
+			path := ""
+			if errors.As(n.err, &pe) {
+				path = pe.path
+			}
+			n.err = newFilterError(data, nodeOffset(offsets, path), n.err)
+		}
+		return nil
+	})
+	return filter
+}
+
+// newFilterError wraps err with the line and column of offset in data.
+func newFilterError(data []byte, offset int64, err error) *FilterError {
+	offset = min(max(offset, 0), int64(len(data)))
+	before := data[:offset]
+	return &FilterError{
+		Line:   bytes.Count(before, []byte("\n")) + 1,
+		Column: int(offset) - (bytes.LastIndexByte(before, '\n') + 1) + 1,
+		Offset: offset,
+		Err:    err,
+	}
+}
+
+// childChain matches the leading children[i].children[j]... part of an
+// error path, before any field segment.
+var childChain = regexp.MustCompile(`^children\[\d+\](\.children\[\d+\])*`)
+
+// nodeOffset returns the offset of the node at path, or of its nearest
+// recorded ancestor.
+func nodeOffset(offsets map[string]int64, path string) int64 {
+	chain := childChain.FindString(path)
+	for {
+		if offset, ok := offsets[chain]; ok {
+			return offset
+		}
+		if chain == "" {
+			return 0
+		}
+		i := strings.LastIndexByte(chain, '.')
+		if i < 0 {
+			i = 0
+		}
+		chain = chain[:i]
+	}
+}
+
+// specOffsets records the byte offset of every object node in a JSON spec,
+// keyed by the path FromSpec reports for it.
+func specOffsets(data []byte) map[string]int64 {
+	offsets := make(map[string]int64)
+	scanSpecNode(json.NewDecoder(bytes.NewReader(data)), data, "", offsets)
+	return offsets
+}
+
+// scanSpecNode records the offset of the value about to be read from dec,
+// if it is an object, and descends into its children.
+func scanSpecNode(dec *json.Decoder, data []byte, path string, offsets map[string]int64) {
+	start := valueStart(data, dec.InputOffset())
+	if start >= int64(len(data)) || data[start] != '{' {
+		var skip json.RawMessage
+		_ = dec.Decode(&skip)
+		return
+	}
+	if _, err := dec.Token(); err != nil {
+		return
+	}
+	offsets[path] = start
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return
+		}
+		if key, _ := tok.(string); strings.EqualFold(key, "children") &&
+			data[valueStart(data, dec.InputOffset())] == '[' {
+			if _, err := dec.Token(); err != nil {
+				return
+			}
+			for i := 0; dec.More(); i++ {
+				scanSpecNode(dec, data, childPath(path, i), offsets)
+			}
+			if _, err := dec.Token(); err != nil {
+				return
+			}
+			continue
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return
+		}
+	}
+	_, _ = dec.Token()
+}
+
+// valueStart returns the offset of the next value at or after offset,
+// skipping whitespace and separators.
+func valueStart(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
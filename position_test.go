@@ -0,0 +1,90 @@
+package vecna
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuilder_FromJSONWithPos(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	doc := `{
+  "op": "and",
+  "children": [
+    {"op": "eq", "field": "category", "value": "tech"},
+    {
+      "op": "or",
+      "children": [
+        {"op": "gte", "field": "score", "value": 0.5},
+          {"op": "eq", "field": "missing", "value": 1}
+      ]
+    }
+  ]
+}`
+
+	f := builder.FromJSONWithPos([]byte(doc))
+	var fe *FilterError
+	if !errors.As(f.Err(), &fe) {
+		t.Fatalf("FromJSONWithPos() error = %v, want *FilterError", f.Err())
+	}
+	if fe.Line != 9 || fe.Column != 11 {
+		t.Errorf("FilterError position = %d:%d, want 9:11", fe.Line, fe.Column)
+	}
+	if doc[fe.Offset] != '{' {
+		t.Errorf("FilterError.Offset = %d points at %q, want the node's opening brace", fe.Offset, doc[fe.Offset])
+	}
+	if !errors.Is(f.Err(), ErrFieldNotFound) {
+		t.Errorf("FromJSONWithPos() error = %v, want %v", f.Err(), ErrFieldNotFound)
+	}
+	want := `line 9, column 11: children[1].children[1].field "missing": vecna: field not found: missing`
+	if got := f.Err().Error(); got != want {
+		t.Errorf("FromJSONWithPos() error = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name       string
+		doc        string
+		wantErr    error
+		wantLine   int
+		wantColumn int
+	}{
+		{"valid", `{"op": "eq", "field": "category", "value": "tech"}`, nil, 0, 0},
+		{"root", "\n\n  {\"op\": \"nand\"}", ErrInvalidFilter, 3, 3},
+		{"invalid operator for kind", "{\"op\": \"not\", \"children\": [\n\t{\"op\": \"gt\", \"field\": \"category\", \"value\": 1}]}", ErrInvalidFilter, 2, 2},
+		{"syntax", "{\"op\": \"eq\",\n \"field\": }", ErrInvalidFilter, 2, 11},
+		{"type mismatch", "{\n\"op\": 5}", ErrInvalidFilter, 2, 7},
+		{"ref", `{"$defs": {"bad": {"op": "eq", "field": "nope", "value": 1}}, "op": "and", "children": [{"op": "eq", "field": "active", "value": true}, {"$ref": "bad"}]}`,
+			ErrFieldNotFound, 1, 137},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := builder.FromJSONWithPos([]byte(tt.doc)).Err()
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("FromJSONWithPos() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) || !errors.As(err, &fe) {
+				t.Fatalf("FromJSONWithPos() error = %v, want *FilterError wrapping %v", err, tt.wantErr)
+			}
+			if fe.Line != tt.wantLine || fe.Column != tt.wantColumn {
+				t.Errorf("FilterError position = %d:%d, want %d:%d", fe.Line, fe.Column, tt.wantLine, tt.wantColumn)
+			}
+		})
+	}
+
+	t.Run("all errors located", func(t *testing.T) {
+		f := builder.FromJSONWithPos([]byte("{\"op\": \"or\", \"children\": [\n{\"op\": \"eq\", \"field\": \"a\", \"value\": 1},\n{\"op\": \"eq\", \"field\": \"b\", \"value\": 1}]}"))
+		errs := f.Errors()
+		if len(errs) != 2 {
+			t.Fatalf("len(Errors()) = %d, want 2", len(errs))
+		}
+		for i, err := range errs {
+			if !errors.As(err, &fe) || fe.Line != i+2 {
+				t.Errorf("Errors()[%d] = %v, want *FilterError on line %d", i, err, i+2)
+			}
+		}
+	})
+}
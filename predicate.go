@@ -0,0 +1,530 @@
+package vecna
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Compile turns a filter into a predicate over T for hot loops, such as
+// filtering millions of in-memory records. The filter is validated and
+// each field's accessor resolved once; the predicate then evaluates with
+// the same semantics as Match without re-walking the tree or looking up
+// fields by name. Conditions on string, numeric, and bool fields read the
+// field directly and do not allocate (ILike lowercases the value);
+// conditions on other fields fall back to Match's comparison.
+//
+// The predicate is safe for concurrent use. It reports false where Match
+// would return an error at evaluation time: for a nil pointer record, and
+// when a Comparable field's CompareTo fails. Filters Match cannot evaluate,
+// such as Raw, return ErrInvalidFilter from Compile.
+func (b *Builder[T]) Compile(f *Filter) (func(T) bool, error) {
+	if err := checkCompilable(f); err != nil {
+		return nil, err
+	}
+	pred, err := b.compilePredicate(f)
+	if err != nil {
+		return nil, err
+	}
+	// Records are copied into pooled storage, which, unlike a pointer to
+	// the argument, does not escape to the heap on every call
+	pool := &sync.Pool{New: func() any { return new(T) }}
+	return func(v T) bool {
+		p := pool.Get().(*T)
+		*p = v
+		ok := evalRecord(pred, reflect.ValueOf(p).Elem())
+		var zero T
+		*p = zero
+		pool.Put(p)
+		return ok
+	}, nil
+}
+
+// evalRecord evaluates pred against a record, dereferencing pointers; a
+// nil record matches nothing.
+func evalRecord(pred predicate, rv reflect.Value) bool {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return false
+		}
+		rv = rv.Elem()
+	}
+	return pred(rv)
+}
+
+// predicate evaluates a compiled filter against a struct value.
+type predicate func(rv reflect.Value) bool
+
+// compilePredicate compiles a single filter node.
+func (b *Builder[T]) compilePredicate(f *Filter) (predicate, error) {
+	switch f.op {
+	case And, Or:
+		preds := make([]predicate, len(f.children))
+		for i, child := range f.children {
+			pred, err := b.compilePredicate(child)
+			if err != nil {
+				return nil, err
+			}
+			preds[i] = pred
+		}
+		if f.op == And {
+			return func(rv reflect.Value) bool {
+				for _, pred := range preds {
+					if !pred(rv) {
+						return false
+					}
+				}
+				return true
+			}, nil
+		}
+		return func(rv reflect.Value) bool {
+			for _, pred := range preds {
+				if pred(rv) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case Not:
+		if len(f.children) != 1 {
+			return nil, fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		pred, err := b.compilePredicate(f.children[0])
+		if err != nil {
+			return nil, err
+		}
+		return func(rv reflect.Value) bool { return !pred(rv) }, nil
+	case Xor:
+		if len(f.children) != 2 {
+			return nil, fmt.Errorf("%w: %s requires exactly two children", ErrInvalidFilter, f.op)
+		}
+		a, err := b.compilePredicate(f.children[0])
+		if err != nil {
+			return nil, err
+		}
+		c, err := b.compilePredicate(f.children[1])
+		if err != nil {
+			return nil, err
+		}
+		return func(rv reflect.Value) bool { return a(rv) != c(rv) }, nil
+	case All, None:
+		ok := f.op == All
+		return func(reflect.Value) bool { return ok }, nil
+	case Raw:
+		return nil, fmt.Errorf("%w: %s predicates cannot be evaluated in memory", ErrInvalidFilter, f.op)
+	case GeoBox:
+		return b.compileGeoBox(f)
+	}
+
+	get, t, err := b.fieldAccessor(f.field)
+	if err != nil {
+		return nil, err
+	}
+	match, err := b.compileCondition(f, t)
+	if err != nil {
+		return nil, err
+	}
+	// Absent fields only satisfy negative conditions
+	absent := f.op == Ne || f.op == Nin || f.op == NotLike
+	return func(rv reflect.Value) bool {
+		fv, ok := get(rv)
+		if !ok {
+			return absent
+		}
+		return match(fv)
+	}, nil
+}
+
+// accessor reads a field from a struct value, with pointers and interfaces
+// unwrapped as by fieldValue. ok is false when the field is absent.
+type accessor func(rv reflect.Value) (fv reflect.Value, ok bool)
+
+// fieldAccessor resolves the named field to an accessor and the field's
+// static type with pointers removed.
+func (b *Builder[T]) fieldAccessor(name string) (accessor, reflect.Type, error) {
+	index, ok := b.index[name]
+	if !ok {
+		if field, key, isEntry := b.mapPath(name); isEntry {
+			return b.entryAccessor(field, key)
+		}
+		return nil, nil, fmt.Errorf("%w: %s", ErrFieldNotFound, name)
+	}
+	t := reflect.TypeFor[T]()
+	for _, i := range index {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		field := t.Field(i)
+		if !field.IsExported() {
+			return nil, nil, fmt.Errorf("%w: field %s is unexported and cannot be evaluated", ErrInvalidFilter, name)
+		}
+		t = field.Type
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return func(rv reflect.Value) (reflect.Value, bool) {
+		fv := rv
+		for _, i := range index {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					return fv, false // Nil embedded pointer: treat as absent
+				}
+				fv = fv.Elem()
+			}
+			fv = fv.Field(i)
+		}
+		for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+			if fv.IsNil() {
+				return fv, false
+			}
+			fv = fv.Elem()
+		}
+		return fv, true
+	}, t, nil
+}
+
+// entryAccessor resolves the entry key of map field to an accessor and the
+// map's value type with pointers removed.
+func (b *Builder[T]) entryAccessor(field, key string) (accessor, reflect.Type, error) {
+	get, t, err := b.fieldAccessor(field)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyV := reflect.ValueOf(key).Convert(t.Key())
+	elem := t.Elem()
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	return func(rv reflect.Value) (reflect.Value, bool) {
+		mv, ok := get(rv)
+		if !ok {
+			return mv, false
+		}
+		fv := mv.MapIndex(keyV)
+		for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+			if fv.IsNil() {
+				return fv, false
+			}
+			fv = fv.Elem()
+		}
+		return fv, fv.IsValid()
+	}, elem, nil
+}
+
+// compileGeoBox compiles a GeoBox filter over its latitude and longitude fields.
+func (b *Builder[T]) compileGeoBox(f *Filter) (predicate, error) {
+	box, err := geoBoxValue(f)
+	if err != nil {
+		return nil, err
+	}
+	lat, _, err := b.fieldAccessor(f.field)
+	if err != nil {
+		return nil, err
+	}
+	lng, _, err := b.fieldAccessor(box.LngField)
+	if err != nil {
+		return nil, err
+	}
+	return func(rv reflect.Value) bool {
+		latV, latOK := lat(rv)
+		lngV, lngOK := lng(rv)
+		if !latOK || !lngOK {
+			return false
+		}
+		latF, latOK := numericValue(latV)
+		lngF, lngOK := numericValue(lngV)
+		return latOK && lngOK &&
+			latF >= box.MinLat && latF <= box.MaxLat && lngF >= box.MinLng && lngF <= box.MaxLng
+	}, nil
+}
+
+// compileCondition compiles a field condition for a field of static type
+// t, using a direct reader for string, numeric, and bool fields where the
+// filter value allows it and evalCondition otherwise.
+func (b *Builder[T]) compileCondition(f *Filter, t reflect.Type) (func(fv reflect.Value) bool, error) {
+	f, err := evaluable(f)
+	if err != nil {
+		return nil, err
+	}
+	if !b.ordered[f.field] {
+		var match func(fv reflect.Value) bool
+		switch t.Kind() {
+		case reflect.String:
+			match = stringCondition(f)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			match = numericCondition(f)
+		case reflect.Bool:
+			match = boolCondition(f)
+		}
+		if match != nil {
+			return match, nil
+		}
+	}
+	return func(fv reflect.Value) bool {
+		ok, err := evalCondition(f, fv.Interface())
+		return ok && err == nil
+	}, nil
+}
+
+// evaluable checks up front the filter values evalCondition would reject,
+// returning a copy of f with its pattern compiled if it is a Regex.
+func evaluable(f *Filter) (*Filter, error) {
+	switch f.op {
+	case Eq, Ne, Gt, Gte, Lt, Lte, Like, ILike, NotLike, Prefix, Suffix, Contains, IsEmpty, IsNotEmpty:
+		return f, nil
+	case Between, Approx:
+		_, _, err := rangeBounds(f)
+		return f, err
+	case In, Nin, ContainsAll, ContainsAny:
+		_, err := sliceValues(f.value)
+		return f, err
+	case Len:
+		_, err := lenValue(f)
+		return f, err
+	case Regex:
+		re, err := filterRegex(f)
+		if err != nil {
+			return nil, err
+		}
+		compiled := *f
+		compiled.regex = re
+		return &compiled, nil
+	default:
+		return nil, fmt.Errorf("%w: operator %s cannot be evaluated", ErrInvalidFilter, f.op)
+	}
+}
+
+// stringCondition returns a direct matcher for a condition on a string
+// field, or nil if the filter value needs the generic comparison.
+func stringCondition(f *Filter) func(fv reflect.Value) bool {
+	switch f.op {
+	case Eq, Ne:
+		want, ok := f.value.(string)
+		if !ok {
+			return nil
+		}
+		eq := f.op == Eq
+		if f.fold {
+			return func(fv reflect.Value) bool { return strings.EqualFold(fv.String(), want) == eq }
+		}
+		return func(fv reflect.Value) bool { return (fv.String() == want) == eq }
+	case Gt, Gte, Lt, Lte:
+		want, ok := f.value.(string)
+		if !ok {
+			return nil
+		}
+		op := f.op
+		return func(fv reflect.Value) bool { return orderedMatch(op, strings.Compare(fv.String(), want)) }
+	case Between:
+		low, high, _ := rangeBounds(f)
+		lo, lok := low.(string)
+		hi, hok := high.(string)
+		if !lok || !hok {
+			return nil
+		}
+		return func(fv reflect.Value) bool { s := fv.String(); return s >= lo && s <= hi }
+	case In, Nin:
+		values, _ := sliceValues(f.value)
+		strs := make([]string, len(values))
+		set := make(map[string]struct{}, len(values))
+		for i, v := range values {
+			str, ok := v.(string)
+			if !ok {
+				return nil
+			}
+			strs[i] = str
+			set[str] = struct{}{}
+		}
+		in := f.op == In
+		if f.fold {
+			return func(fv reflect.Value) bool {
+				s := fv.String()
+				for _, str := range strs {
+					if strings.EqualFold(s, str) {
+						return in
+					}
+				}
+				return !in
+			}
+		}
+		return func(fv reflect.Value) bool {
+			_, found := set[fv.String()]
+			return found == in
+		}
+	case Like, NotLike:
+		pattern, ok := f.value.(string)
+		if !ok {
+			return nil
+		}
+		like := f.op == Like
+		return func(fv reflect.Value) bool { return likeMatch(fv.String(), pattern) == like }
+	case ILike:
+		pattern, ok := f.value.(string)
+		if !ok {
+			return nil
+		}
+		pattern = strings.ToLower(pattern)
+		return func(fv reflect.Value) bool { return likeMatch(strings.ToLower(fv.String()), pattern) }
+	case Prefix, Suffix:
+		affix, ok := f.value.(string)
+		if !ok {
+			return nil
+		}
+		if f.op == Prefix {
+			return func(fv reflect.Value) bool { return strings.HasPrefix(fv.String(), affix) }
+		}
+		return func(fv reflect.Value) bool { return strings.HasSuffix(fv.String(), affix) }
+	case Regex:
+		re := f.regex
+		return func(fv reflect.Value) bool { return re.MatchString(fv.String()) }
+	default:
+		return nil
+	}
+}
+
+// numericCondition returns a direct matcher for a condition on a numeric
+// field, or nil if the filter value needs the generic comparison.
+func numericCondition(f *Filter) func(fv reflect.Value) bool {
+	switch f.op {
+	case Eq, Ne, Gt, Gte, Lt, Lte:
+		want, ok := plainNumber(f.value)
+		if !ok {
+			return nil
+		}
+		op := f.op
+		return func(fv reflect.Value) bool {
+			switch op {
+			case Eq:
+				return equalNumber(fv, want)
+			case Ne:
+				return !equalNumber(fv, want)
+			default:
+				return orderedMatch(op, compareNumber(fv, want))
+			}
+		}
+	case Between, Approx:
+		low, high, _ := rangeBounds(f)
+		lo, lok := plainNumber(low)
+		hi, hok := plainNumber(high)
+		if !lok || !hok {
+			return nil
+		}
+		return func(fv reflect.Value) bool { return compareNumber(fv, lo) >= 0 && compareNumber(fv, hi) <= 0 }
+	case In, Nin:
+		values, _ := sliceValues(f.value)
+		nums := make([]number, len(values))
+		for i, v := range values {
+			n, ok := plainNumber(v)
+			if !ok {
+				return nil
+			}
+			nums[i] = n
+		}
+		in := f.op == In
+		return func(fv reflect.Value) bool {
+			for _, want := range nums {
+				if equalNumber(fv, want) {
+					return in
+				}
+			}
+			return !in
+		}
+	default:
+		return nil
+	}
+}
+
+// boolCondition returns a direct matcher for a condition on a bool field,
+// or nil if the filter value needs the generic comparison.
+func boolCondition(f *Filter) func(fv reflect.Value) bool {
+	want, ok := f.value.(bool)
+	if !ok || (f.op != Eq && f.op != Ne) {
+		return nil
+	}
+	eq := f.op == Eq
+	return func(fv reflect.Value) bool { return (fv.Bool() == want) == eq }
+}
+
+// number is a numeric filter value, kept as a reflect.Value so integers
+// compare exactly with integer fields, and as float64 for other fields.
+type number struct {
+	value reflect.Value
+	float float64
+}
+
+// plainNumber converts a numeric filter value to a number. Times, which
+// valuesEqual and compareValues compare as instants, are not plain numbers.
+func plainNumber(v any) (number, bool) {
+	if _, ok := v.(time.Time); ok {
+		return number{}, false
+	}
+	f, ok := toFloat64(v)
+	return number{value: reflect.ValueOf(v), float: f}, ok
+}
+
+// equalNumber reports whether a numeric field value equals want, as
+// valuesEqual does.
+func equalNumber(fv reflect.Value, want number) bool {
+	if c, ok := compareIntegers(fv, want.value); ok {
+		return c == 0
+	}
+	n, _ := numericValue(fv)
+	return n == want.float
+}
+
+// compareNumber orders a numeric field value against want, as
+// compareValues does.
+func compareNumber(fv reflect.Value, want number) int {
+	if c, ok := compareIntegers(fv, want.value); ok {
+		return c
+	}
+	n, _ := numericValue(fv)
+	return compareFloats(n, want.float)
+}
+
+// numericValue reads a numeric reflect.Value as float64, as toFloat64 does.
+func numericValue(fv reflect.Value) (float64, bool) {
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(fv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// compareFloats orders two float64 values.
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// orderedMatch reports whether a comparison result satisfies Gt, Gte, Lt, or Lte.
+func orderedMatch(op Op, cmp int) bool {
+	switch op {
+	case Gt:
+		return cmp > 0
+	case Gte:
+		return cmp >= 0
+	case Lt:
+		return cmp < 0
+	default:
+		return cmp <= 0
+	}
+}
@@ -0,0 +1,205 @@
+package vecna
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBuilder_Compile(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	folded, _ := New[testMetadata](WithCaseInsensitive("category"))
+
+	docs := []testMetadata{
+		{Category: "tech", Score: 0.75, Count: 10, Active: true, Tags: []string{"go", "vector"}},
+		{Category: "Science", Score: 0.2, Count: 3, Tags: []string{"rust"}},
+		{Category: "art"},
+	}
+
+	filters := map[string]*Filter{
+		"eq":           builder.Where("category").Eq("tech"),
+		"eq numeric":   builder.Where("count").Eq(10.0),
+		"ne":           builder.Where("category").Ne("tech"),
+		"gt":           builder.Where("score").Gt(0.5),
+		"lte":          builder.Where("count").Lte(3),
+		"between":      builder.Where("score").Between(0.1, 0.5),
+		"approx":       builder.Where("score").Approx(0.7, 0.1),
+		"in":           builder.Where("category").In("art", "tech"),
+		"nin numeric":  builder.Where("count").Nin(3, 4),
+		"like":         builder.Where("category").Like("t%"),
+		"ilike":        builder.Where("category").ILike("SCI%"),
+		"not like":     builder.Where("category").NotLike("a_t"),
+		"prefix":       builder.Where("category").StartsWith("te"),
+		"suffix":       builder.Where("category").EndsWith("ce"),
+		"regex":        builder.Where("category").Regex("^[a-z]+$"),
+		"bool":         builder.Where("active").Eq(true),
+		"bool ne":      builder.Where("active").Ne(true),
+		"contains":     builder.Where("tags").Contains("go"),
+		"contains any": builder.Where("tags").ContainsAny("rust", "zig"),
+		"contains all": builder.Where("tags").ContainsAll("go", "vector"),
+		"is empty":     builder.Where("tags").IsEmpty(),
+		"len":          builder.Where("tags").Len(Gte, 2),
+		"fold eq":      folded.Where("category").Eq("SCIENCE"),
+		"fold in":      folded.Where("category").In("TECH", "ART"),
+		"all":          builder.All(),
+		"none":         builder.None(),
+		"not":          builder.Not(builder.Where("category").Eq("tech")),
+		"xor":          builder.Xor(builder.Where("active").Eq(true), builder.Where("score").Gt(0.5)),
+		"and":          builder.And(builder.Where("score").Gte(0.2), builder.Where("count").Lt(5)),
+		"or":           builder.Or(builder.Where("active").Eq(true), builder.Where("tags").IsEmpty()),
+		"nested":       builder.And(builder.Or(builder.Where("category").Eq("art"), builder.Where("score").Gt(0.5)), builder.Not(builder.Where("count").Eq(3))),
+	}
+
+	for name, filter := range filters {
+		t.Run(name, func(t *testing.T) {
+			pred, err := builder.Compile(filter)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			for _, doc := range docs {
+				want, err := builder.Match(filter, doc)
+				if err != nil {
+					t.Fatalf("Match() error = %v", err)
+				}
+				if got := pred(doc); got != want {
+					t.Errorf("Compile()(%+v) = %v, Match() = %v", doc, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuilder_Compile_Indirect(t *testing.T) {
+	builder, _ := New[indirectMetadata]()
+	owner := "ana"
+	ownerPtr := &owner
+	rank := 3
+	var nilOwner *string
+
+	docs := []indirectMetadata{
+		{Label: &owner, Owner: &ownerPtr, Rank: &rank},
+		{},
+		{Label: nilOwner, Owner: &nilOwner},
+	}
+	filters := []*Filter{
+		builder.Where("label").Eq("ana"),
+		builder.Where("label").Ne("ana"),
+		builder.Where("owner").In("bo", "ana"),
+		builder.Where("owner").Nin("ana"),
+		builder.Where("rank").Eq(3),
+	}
+
+	for _, filter := range filters {
+		pred, err := builder.Compile(filter)
+		if err != nil {
+			t.Fatalf("Compile(%s) error = %v", filter, err)
+		}
+		for i, doc := range docs {
+			want, _ := builder.Match(filter, doc)
+			if got := pred(doc); got != want {
+				t.Errorf("Compile(%s)(docs[%d]) = %v, Match() = %v", filter, i, got, want)
+			}
+		}
+	}
+}
+
+func TestBuilder_Compile_Pointer(t *testing.T) {
+	builder, _ := New[*testMetadata]()
+	pred, err := builder.Compile(builder.Where("category").Eq("tech"))
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if !pred(&testMetadata{Category: "tech"}) {
+		t.Error("Compile()(&doc) = false, want true")
+	}
+	if pred(nil) {
+		t.Error("Compile()(nil) = true, want false")
+	}
+}
+
+func TestBuilder_Compile_Comparable(t *testing.T) {
+	builder, _ := New[releaseMetadata]()
+	filter := builder.Where("version").Gte("1.9")
+	pred, err := builder.Compile(filter)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	for _, v := range []Version{"1.10.0", "1.2"} {
+		doc := releaseMetadata{Version: v}
+		want, _ := builder.Match(filter, doc)
+		if got := pred(doc); got != want {
+			t.Errorf("Compile()(%s) = %v, Match() = %v", v, got, want)
+		}
+	}
+}
+
+func TestBuilder_Compile_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		wantErr error
+	}{
+		{"nil", nil, ErrInvalidFilter},
+		{"construction error", builder.Where("missing").Eq(1), ErrFieldNotFound},
+		{"raw", builder.Raw("sql", []byte(`"1 = 1"`)), ErrInvalidFilter},
+		{"raw nested", builder.Or(builder.Where("active").Eq(true), builder.Raw("sql", []byte(`"1 = 1"`))), ErrInvalidFilter},
+		{"bad regex", &Filter{op: Regex, field: "category", value: "("}, ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := builder.Compile(tt.filter); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Compile() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuilder_Compile_Allocations(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	pred, err := builder.Compile(builder.And(
+		builder.Where("category").In("tech", "art"),
+		builder.Where("score").Between(0.5, 1),
+		builder.Where("active").Eq(true),
+		builder.Where("category").Like("t%"),
+	))
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	doc := testMetadata{Category: "tech", Score: 0.75, Active: true, Tags: []string{"go"}}
+
+	if allocs := testing.AllocsPerRun(100, func() { pred(doc) }); allocs != 0 {
+		t.Errorf("Compile() predicate allocates %v times per call, want 0", allocs)
+	}
+}
+
+func TestBuilder_Compile_Time(t *testing.T) {
+	builder, _ := New[eventMetadata]()
+	now := time.Now()
+	deleted := now.Add(-time.Minute)
+
+	docs := []eventMetadata{
+		{CreatedAt: now, DeletedAt: &deleted},
+		{CreatedAt: now.Add(-2 * time.Hour)},
+	}
+	filters := []*Filter{
+		builder.Where("created_at").Gte(now.Add(-time.Hour)),
+		builder.Where("deleted_at").Lt(now),
+		builder.Where("deleted_at").Ne(deleted),
+	}
+
+	for _, filter := range filters {
+		pred, err := builder.Compile(filter)
+		if err != nil {
+			t.Fatalf("Compile(%s) error = %v", filter, err)
+		}
+		for i, doc := range docs {
+			want, _ := builder.Match(filter, doc)
+			if got := pred(doc); got != want {
+				t.Errorf("Compile(%s)(docs[%d]) = %v, Match() = %v", filter, i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package vecna
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// querySeparator separates a field name from its operator in a query key.
+const querySeparator = "__"
+
+// FromQuery builds a filter from URL query parameters of the form
+// field__op=value, e.g. ?category=tech&score__gte=0.5&tags__contains=go.
+// A key without an operator suffix, or one naming a field outright, means
+// eq. Values are parsed according to the field's kind (the element kind
+// for slice fields), so "0.5" becomes a float and "true" a bool; fields
+// with a ValueParser receive the raw string instead. Repeating a key gives
+// the values of in, nin, contains_all, and contains_any, and exactly two
+// of between; other operators yield one condition per value. is_empty and
+// is_not_empty take an empty value, as in ?tags__is_empty.
+//
+// Conditions are combined with And, in sorted key order. No parameters
+// yield All. Unknown fields, unknown or unsupported operators, and
+// unparsable values surface through Err, prefixed with the parameter, e.g.
+// parameter "score__gte": ....
+func (b *Builder[T]) FromQuery(values url.Values) *Filter {
+	var conds []*Filter
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		for _, cond := range b.fromQueryParam(key, values[key]) {
+			if cond.err != nil {
+				cond.err = withPath(fmt.Sprintf("parameter %q", key), cond.err)
+			}
+			conds = append(conds, cond)
+		}
+	}
+
+	switch len(conds) {
+	case 0:
+		return b.withBase(b.All())
+	case 1:
+		return b.withBase(conds[0])
+	default:
+		return b.withBase(b.And(conds...))
+	}
+}
+
+// fromQueryParam converts one query key and its values into conditions.
+func (b *Builder[T]) fromQueryParam(key string, raw []string) []*Filter {
+	field, op, err := b.parseQueryKey(key)
+	if err != nil {
+		return []*Filter{{op: op, field: field, err: err}}
+	}
+	if !b.HasField(field) {
+		return []*Filter{b.Where(field).makeFilter(op, raw)}
+	}
+
+	switch op {
+	case In, Nin, ContainsAll, ContainsAny, Between:
+		if op == Between && len(raw) != 2 {
+			return []*Filter{{op: op, field: field, value: raw,
+				err: fmt.Errorf("%w: between requires exactly two values, got %d", ErrInvalidFilter, len(raw))}}
+		}
+		list := make([]any, len(raw))
+		for i, s := range raw {
+			if list[i], err = b.queryValue(field, s); err != nil {
+				return []*Filter{{op: op, field: field, value: raw, err: err}}
+			}
+		}
+		return []*Filter{b.fromFieldSpec(op, field, list)}
+	case IsEmpty, IsNotEmpty:
+		if slices.ContainsFunc(raw, func(s string) bool { return s != "" }) {
+			return []*Filter{{op: op, field: field, value: raw, err: fmt.Errorf("%w: %s takes no value", ErrInvalidFilter, op)}}
+		}
+		return []*Filter{b.fromFieldSpec(op, field, nil)}
+	case Eq, Ne, Gt, Gte, Lt, Lte, Like, ILike, NotLike, Contains, Regex, Prefix, Suffix:
+		conds := make([]*Filter, len(raw))
+		for i, s := range raw {
+			var value any = s
+			if !isStringOp(op) {
+				if value, err = b.queryValue(field, s); err != nil {
+					conds[i] = &Filter{op: op, field: field, value: s, err: err}
+					continue
+				}
+			}
+			conds[i] = b.fromFieldSpec(op, field, value)
+		}
+		return conds
+	default:
+		return []*Filter{{op: op, field: field, value: raw,
+			err: fmt.Errorf("%w: operator %s not supported in query parameters", ErrInvalidFilter, op)}}
+	}
+}
+
+// parseQueryKey splits a query key into its field and operator.
+func (b *Builder[T]) parseQueryKey(key string) (string, Op, error) {
+	if b.HasField(key) {
+		return key, Eq, nil
+	}
+	i := strings.LastIndex(key, querySeparator)
+	if i < 0 {
+		return key, Eq, nil
+	}
+	op, err := parseOp(key[i+len(querySeparator):])
+	return key[:i], op, err
+}
+
+// queryValue parses a query string value according to the field's kind,
+// or its element kind for slice fields. Fields with a ValueParser, and
+// kinds without a textual form to parse, keep the string.
+func (b *Builder[T]) queryValue(field, s string) (any, error) {
+	if _, ok := b.parsers[field]; ok {
+		return s, nil
+	}
+
+	spec, _ := b.lookupField(field)
+	kind := spec.Kind
+	if kind == KindSlice {
+		kind = KindString
+		if elem := b.sliceElemType(field); elem != nil {
+			kind = resolveFieldKind(sentinelKind(elem), elem.String())
+		}
+	}
+
+	var value any
+	var err error
+	switch kind {
+	case KindInt:
+		value, err = strconv.ParseInt(s, 10, 64)
+	case KindUint:
+		value, err = strconv.ParseUint(s, 10, 64)
+	case KindFloat:
+		value, err = strconv.ParseFloat(s, 64)
+	case KindBool:
+		value, err = strconv.ParseBool(s)
+	default:
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot parse %q as %s", ErrInvalidFilter, s, kind)
+	}
+	return value, nil
+}
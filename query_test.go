@@ -0,0 +1,113 @@
+package vecna
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestBuilder_FromQuery(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"eq default", "category=tech", `category == "tech"`},
+		{"eq explicit", "category__eq=tech", `category == "tech"`},
+		{"float", "score__gte=0.5", `score >= 0.5`},
+		{"int", "count__lt=10", `count < 10`},
+		{"bool", "active=true", `active == true`},
+		{"slice element", "tags__contains=featured", `tags CONTAINS "featured"`},
+		{"in", "category__in=tech&category__in=science", `category IN ["tech", "science"]`},
+		{"nin", "category__nin=spam", `category NOT IN ["spam"]`},
+		{"between", "count__between=1&count__between=10", `count BETWEEN 1 AND 10`},
+		{"like", "category__like=te%25", `category LIKE "te%"`},
+		{"is empty", "tags__is_empty", `tags IS EMPTY`},
+		{"repeated", "score__gt=0.1&score__gt=0.2", `(score > 0.1 AND score > 0.2)`},
+		{"sorted keys", "score__gte=0.5&category=tech&tags__contains=featured",
+			`(category == "tech" AND score >= 0.5 AND tags CONTAINS "featured")`},
+		{"empty", "", `ALL`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("url.ParseQuery() error = %v", err)
+			}
+			filter := builder.FromQuery(values)
+			if filter.Err() != nil {
+				t.Fatalf("FromQuery() error = %v", filter.Err())
+			}
+			if got := filter.String(); got != tt.want {
+				t.Errorf("FromQuery() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_FromQuery_Match(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	values, _ := url.ParseQuery("category=tech&score__gte=0.5&tags__contains=go")
+	filter := builder.FromQuery(values)
+
+	ok, err := builder.Match(filter, testMetadata{Category: "tech", Score: 0.75, Tags: []string{"go"}})
+	if err != nil || !ok {
+		t.Errorf("Match() = %v, %v, want true, nil", ok, err)
+	}
+	ok, _ = builder.Match(filter, testMetadata{Category: "tech", Score: 0.25, Tags: []string{"go"}})
+	if ok {
+		t.Error("Match() = true, want false for low score")
+	}
+}
+
+func TestBuilder_FromQuery_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr error
+		wantMsg string
+	}{
+		{"unknown field", "missing=1", ErrFieldNotFound, `parameter "missing"`},
+		{"unknown op", "score__near=1", ErrInvalidFilter, `unknown operator "near"`},
+		{"bad float", "score__gte=high", ErrInvalidFilter, `cannot parse "high" as float`},
+		{"bad bool", "active=yes", ErrInvalidFilter, `cannot parse "yes" as bool`},
+		{"bad list element", "count__in=1&count__in=x", ErrInvalidFilter, `cannot parse "x" as int`},
+		{"invalid op for kind", "category__gt=a", ErrInvalidFilter, "not valid for string field"},
+		{"between arity", "count__between=1", ErrInvalidFilter, "exactly two values"},
+		{"unsupported op", "score__approx=1", ErrInvalidFilter, "not supported in query parameters"},
+		{"is empty value", "tags__is_empty=yes", ErrInvalidFilter, "takes no value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("url.ParseQuery() error = %v", err)
+			}
+			err = builder.FromQuery(values).Err()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FromQuery() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("FromQuery() error = %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestBuilder_FromQuery_BaseFilter(t *testing.T) {
+	builder, _ := New[testMetadata](WithBaseFilter(func(b *Builder[testMetadata]) *Filter {
+		return b.Where("active").Eq(true)
+	}))
+
+	filter := builder.FromQuery(url.Values{"category": {"tech"}})
+	if got, want := filter.String(), `(active == true AND category == "tech")`; got != want {
+		t.Errorf("FromQuery() = %s, want %s", got, want)
+	}
+}
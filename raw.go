@@ -0,0 +1,70 @@
+package vecna
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RawValue is the value of a Raw filter: an opaque predicate that only the
+// compiler named by Backend emits.
+type RawValue struct {
+	Backend string          `json:"backend"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Raw creates an escape-hatch filter carrying a backend-specific predicate,
+// so stored specs can mix portable conditions with the occasional one vecna
+// cannot express. Backend names the compiler that emits it: "sql" (ToSQL),
+// "jsonb", "sqlite", "pinot", "surreal", "govaluate", "logquery",
+// "dynamodb", "meili", "cypher", "lancedb" (ToLanceDB), "typesense"
+// (ToTypesense), "odata" (ToODataFilter), "mongo" (ToMongo), or
+// "elasticsearch" (ToElasticsearch). For the text-based compilers the
+// payload is a JSON string holding the predicate, emitted verbatim in
+// parentheses without validation; for mongo and elasticsearch it is a query
+// document object.
+// Any other compiler, and in-memory matching, returns ErrInvalidFilter.
+func (b *Builder[T]) Raw(backend string, payload json.RawMessage) *Filter {
+	raw := RawValue{Backend: backend, Payload: payload}
+	filter := &Filter{op: Raw, value: raw}
+
+	switch {
+	case backend == "":
+		filter.err = fmt.Errorf("%w: raw requires a backend", ErrInvalidFilter)
+	case len(payload) == 0 || !json.Valid(payload):
+		filter.err = fmt.Errorf("%w: raw payload for %s must be valid JSON", ErrInvalidFilter, backend)
+	}
+	return filter
+}
+
+// rawClause returns the parenthesized predicate text of a Raw filter targeting backend.
+func rawClause(f *Filter, backend string) (string, error) {
+	raw, ok := f.value.(RawValue)
+	if !ok {
+		return "", fmt.Errorf("%w: %s requires a RawValue, got %T", ErrInvalidFilter, f.op, f.value)
+	}
+	if raw.Backend != backend {
+		return "", fmt.Errorf("%w: raw predicate for %s not supported by %s", ErrInvalidFilter, raw.Backend, backend)
+	}
+	var clause string
+	if err := json.Unmarshal(raw.Payload, &clause); err != nil {
+		return "", fmt.Errorf("%w: raw payload for %s must be a JSON string", ErrInvalidFilter, backend)
+	}
+	return "(" + clause + ")", nil
+}
+
+// rawObject returns the query document of a Raw filter targeting backend,
+// for the compilers that emit structured documents rather than text.
+func rawObject(f *Filter, backend string) (map[string]any, error) {
+	raw, ok := f.value.(RawValue)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s requires a RawValue, got %T", ErrInvalidFilter, f.op, f.value)
+	}
+	if raw.Backend != backend {
+		return nil, fmt.Errorf("%w: raw predicate for %s not supported by %s", ErrInvalidFilter, raw.Backend, backend)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(raw.Payload, &doc); err != nil || doc == nil {
+		return nil, fmt.Errorf("%w: raw payload for %s must be a JSON object", ErrInvalidFilter, backend)
+	}
+	return doc, nil
+}
@@ -0,0 +1,114 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBuilder_Raw_FromSpec(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	var spec FilterSpec
+	if err := json.Unmarshal([]byte(`{
+		"op": "and",
+		"children": [
+			{"op": "eq", "field": "category", "value": "tech"},
+			{"op": "raw", "backend": "jsonb", "raw": "\"metadata\" ? 'featured'"}
+		]
+	}`), &spec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	filter := builder.FromSpec(&spec)
+	if filter.Err() != nil {
+		t.Fatalf("FromSpec().Err() = %v, want nil", filter.Err())
+	}
+
+	t.Run("matching compiler emits", func(t *testing.T) {
+		sql, args, err := CompileToJSONB(filter, "metadata")
+		if err != nil {
+			t.Fatalf("CompileToJSONB() error = %v", err)
+		}
+		want := `("metadata"->>'category' = $1 AND ("metadata" ? 'featured'))`
+		if sql != want {
+			t.Errorf("CompileToJSONB() = %s, want %s", sql, want)
+		}
+		if !reflect.DeepEqual(args, []any{"tech"}) {
+			t.Errorf("CompileToJSONB() args = %v, want [tech]", args)
+		}
+	})
+
+	t.Run("other compilers error", func(t *testing.T) {
+		if _, _, err := builder.ToSQL(filter); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("ToSQL() error = %v, want %v", err, ErrInvalidFilter)
+		}
+		if _, err := CompileToPinot(filter); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("CompileToPinot() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		out, err := filter.ToSpec()
+		if err != nil {
+			t.Fatalf("ToSpec() error = %v", err)
+		}
+		data, err := json.Marshal(out)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		var decoded FilterSpec
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		raw := decoded.Children[1]
+		if raw.Op != "raw" || raw.Backend != "jsonb" || raw.Value != nil {
+			t.Errorf("raw spec = %+v, want op raw, backend jsonb, no value", *raw)
+		}
+
+		rebuilt := builder.FromSpec(&decoded)
+		sql, _, err := CompileToJSONB(rebuilt, "metadata")
+		if err != nil {
+			t.Fatalf("CompileToJSONB() error = %v", err)
+		}
+		if want, _, _ := CompileToJSONB(filter, "metadata"); sql != want {
+			t.Errorf("round trip = %s, want %s", sql, want)
+		}
+	})
+
+	t.Run("match errors", func(t *testing.T) {
+		if _, err := builder.Match(filter, testMetadata{Category: "tech"}); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("Match() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+}
+
+func TestBuilder_Raw_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+	}{
+		{"no backend", builder.Raw("", json.RawMessage(`"x"`))},
+		{"no payload", builder.Raw("sql", nil)},
+		{"invalid payload", builder.Raw("sql", json.RawMessage(`{`))},
+		{"spec without payload", builder.FromSpec(&FilterSpec{Op: "raw", Backend: "sql"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.filter.Err(), ErrInvalidFilter) {
+				t.Errorf("Filter.Err() = %v, want %v", tt.filter.Err(), ErrInvalidFilter)
+			}
+		})
+	}
+
+	t.Run("non-string payload", func(t *testing.T) {
+		_, _, err := builder.ToSQL(builder.Raw("sql", json.RawMessage(`{"must": []}`)))
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("ToSQL() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+}
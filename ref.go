@@ -0,0 +1,89 @@
+package vecna
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// refPrefix is the optional JSON Schema style prefix of a $ref value.
+const refPrefix = "#/$defs/"
+
+// expandRefs returns spec with every $ref node replaced by the definition it
+// names in the root's $defs. Subtrees without references are shared with
+// spec rather than copied.
+func expandRefs(spec *FilterSpec) (*FilterSpec, error) {
+	r := &refResolver{defs: spec.Defs, active: make(map[string]bool)}
+	return r.expand(spec, "")
+}
+
+// refResolver expands references against a set of definitions, tracking the
+// definitions currently being expanded to detect cycles.
+type refResolver struct {
+	defs   map[string]*FilterSpec
+	active map[string]bool
+}
+
+// expand resolves references in the spec found at path.
+func (r *refResolver) expand(spec *FilterSpec, path string) (*FilterSpec, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	if spec.Ref != "" {
+		return r.resolve(spec, path)
+	}
+
+	var children []*FilterSpec
+	for i, child := range spec.Children {
+		cpath := childPath(path, i)
+		if child != nil && child.Defs != nil {
+			return nil, withPath(cpath, fmt.Errorf("%w: $defs is only allowed at the root", ErrInvalidFilter))
+		}
+		expanded, err := r.expand(child, cpath)
+		if err != nil {
+			return nil, err
+		}
+		if expanded != child {
+			if children == nil {
+				children = slices.Clone(spec.Children)
+			}
+			children[i] = expanded
+		}
+	}
+	if children == nil {
+		return spec, nil
+	}
+	clone := *spec
+	clone.Children = children
+	return &clone, nil
+}
+
+// resolve replaces a $ref node with its expanded definition, keeping the
+// node's ID so references can be used in group expressions.
+func (r *refResolver) resolve(spec *FilterSpec, path string) (*FilterSpec, error) {
+	name := strings.TrimPrefix(spec.Ref, refPrefix)
+	if spec.Op != "" || spec.Field != "" || spec.Value != nil || len(spec.Children) > 0 || spec.Group != "" {
+		return nil, withPath(path, fmt.Errorf("%w: $ref %q cannot be combined with other keys", ErrInvalidFilter, spec.Ref))
+	}
+	def, ok := r.defs[name]
+	if !ok || def == nil {
+		return nil, withPath(path, fmt.Errorf("%w: unknown $ref %q", ErrInvalidFilter, spec.Ref))
+	}
+	if def.Defs != nil {
+		return nil, withPath(path, fmt.Errorf("%w: $defs is only allowed at the root", ErrInvalidFilter))
+	}
+	if r.active[name] {
+		return nil, withPath(path, fmt.Errorf("%w: $ref cycle through %q", ErrInvalidFilter, name))
+	}
+
+	r.active[name] = true
+	expanded, err := r.expand(def, path)
+	delete(r.active, name)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := *expanded
+	clone.ID = spec.ID
+	return &clone, nil
+}
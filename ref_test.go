@@ -0,0 +1,134 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBuilder_FromSpec_Refs(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	var spec FilterSpec
+	if err := json.Unmarshal([]byte(`{
+		"$defs": {
+			"scope": {"op": "and", "children": [
+				{"op": "eq", "field": "active", "value": true},
+				{"$ref": "#/$defs/tech"}
+			]},
+			"tech": {"op": "eq", "field": "category", "value": "tech"}
+		},
+		"op": "or",
+		"children": [
+			{"op": "and", "children": [{"$ref": "scope"}, {"op": "gte", "field": "score", "value": 0.5}]},
+			{"op": "and", "children": [{"$ref": "scope"}, {"op": "contains", "field": "tags", "value": "go"}]}
+		]
+	}`), &spec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	filter := builder.FromSpec(&spec)
+	if filter.Err() != nil {
+		t.Fatalf("FromSpec().Err() = %v, want nil", filter.Err())
+	}
+
+	want := `(((active == true AND category == "tech") AND score >= 0.5) OR ((active == true AND category == "tech") AND tags CONTAINS "go"))`
+	if got := filter.String(); got != want {
+		t.Errorf("Filter.String() = %s, want %s", got, want)
+	}
+
+	tests := []struct {
+		item testMetadata
+		want bool
+	}{
+		{testMetadata{Active: true, Category: "tech", Score: 0.9}, true},
+		{testMetadata{Active: true, Category: "tech", Tags: []string{"go"}}, true},
+		{testMetadata{Active: false, Category: "tech", Score: 0.9}, false},
+	}
+	for _, tt := range tests {
+		if got, _ := builder.Match(filter, tt.item); got != tt.want {
+			t.Errorf("Match(%+v) = %v, want %v", tt.item, got, tt.want)
+		}
+	}
+
+	if spec.Children[0].Children[0].Ref != "scope" {
+		t.Error("FromSpec() mutated the input spec")
+	}
+}
+
+func TestBuilder_FromSpec_RefInGroup(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.FromSpec(&FilterSpec{
+		Defs:  map[string]*FilterSpec{"tech": {Op: "eq", Field: "category", Value: "tech"}},
+		Group: "a AND b",
+		Children: []*FilterSpec{
+			{ID: "a", Ref: "tech"},
+			{ID: "b", Op: "eq", Field: "active", Value: true},
+		},
+	})
+	if filter.Err() != nil {
+		t.Fatalf("FromSpec().Err() = %v, want nil", filter.Err())
+	}
+}
+
+func TestBuilder_FromSpec_RefErrors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name string
+		spec *FilterSpec
+		msg  string
+	}{
+		{
+			"cycle",
+			&FilterSpec{
+				Defs: map[string]*FilterSpec{
+					"a": {Op: "and", Children: []*FilterSpec{{Ref: "b"}}},
+					"b": {Op: "not", Children: []*FilterSpec{{Ref: "a"}}},
+				},
+				Ref: "a",
+			},
+			"cycle",
+		},
+		{
+			"self reference",
+			&FilterSpec{
+				Defs: map[string]*FilterSpec{"a": {Op: "or", Children: []*FilterSpec{{Ref: "a"}}}},
+				Op:   "and", Children: []*FilterSpec{{Ref: "a"}},
+			},
+			`children[0].children[0]: vecna: invalid filter: $ref cycle through "a"`,
+		},
+		{
+			"unknown",
+			&FilterSpec{Op: "and", Children: []*FilterSpec{{Ref: "missing"}}},
+			`children[0]: vecna: invalid filter: unknown $ref "missing"`,
+		},
+		{
+			"combined keys",
+			&FilterSpec{
+				Defs: map[string]*FilterSpec{"a": {Op: "eq", Field: "category", Value: "x"}},
+				Ref:  "a", Op: "eq",
+			},
+			"cannot be combined",
+		},
+		{
+			"nested defs",
+			&FilterSpec{Op: "and", Children: []*FilterSpec{{Defs: map[string]*FilterSpec{}, Op: "eq", Field: "category", Value: "x"}}},
+			"only allowed at the root",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := builder.FromSpec(tt.spec).Err()
+			if !errors.Is(err, ErrInvalidFilter) {
+				t.Fatalf("FromSpec().Err() = %v, want %v", err, ErrInvalidFilter)
+			}
+			if !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("FromSpec().Err() = %v, want it to contain %s", err, tt.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package vecna
+
+// Intersect returns a filter matching what both a and b match: And(a, b),
+// simplified (see Simplify). All is the identity, so Intersect(a, All) is
+// a, and None absorbs, so Intersect(a, None) is None. If either operand
+// carries a construction error, the unsimplified And is returned so that
+// Err still reports it.
+func (*Builder[T]) Intersect(a, b *Filter) *Filter {
+	return simplifyValid(group(And, []*Filter{a, b}))
+}
+
+// Union returns a filter matching what either a or b matches: Or(a, b),
+// simplified. None is the identity, so Union(a, None) is a, and All
+// absorbs, so Union(a, All) is All. Errors are preserved as for Intersect.
+func (*Builder[T]) Union(a, b *Filter) *Filter {
+	return simplifyValid(group(Or, []*Filter{a, b}))
+}
+
+// Difference returns a filter matching what a matches and b does not:
+// And(a, Not(b)), simplified. Difference(a, None) is a, Difference(a, All)
+// and Difference(None, b) are None, and Difference(All, b) is Not(b).
+// Errors are preserved as for Intersect.
+func (*Builder[T]) Difference(a, b *Filter) *Filter {
+	return simplifyValid(group(And, []*Filter{a, {op: Not, children: []*Filter{b}}}))
+}
+
+// simplifyValid simplifies f unless it carries a construction error, which
+// simplification could discard along with an absorbed sibling.
+func simplifyValid(f *Filter) *Filter {
+	if f.Err() != nil {
+		return f
+	}
+	return f.Simplify()
+}
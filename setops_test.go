@@ -0,0 +1,112 @@
+package vecna
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuilder_SetOperations(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	tech := builder.Where("category").Eq("tech")
+	active := builder.Where("active").Eq(true)
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"intersect", builder.Intersect(tech, active), `(category == "tech" AND active == true)`},
+		{"intersect all", builder.Intersect(tech, builder.All()), `category == "tech"`},
+		{"intersect none", builder.Intersect(tech, builder.None()), `NONE`},
+		{"intersect flattens", builder.Intersect(builder.And(tech, active), builder.Where("count").Gt(1)), `(category == "tech" AND active == true AND count > 1)`},
+		{"intersect merges ranges", builder.Intersect(builder.Where("score").Gte(0.2), builder.Where("score").Lte(0.8)), `score BETWEEN 0.2 AND 0.8`},
+		{"union", builder.Union(tech, active), `(category == "tech" OR active == true)`},
+		{"union none", builder.Union(tech, builder.None()), `category == "tech"`},
+		{"union all", builder.Union(tech, builder.All()), `ALL`},
+		{"union all first", builder.Union(builder.All(), tech), `ALL`},
+		{"union simplified all", builder.Union(tech, builder.Not(builder.None())), `ALL`},
+		{"difference", builder.Difference(tech, active), `(category == "tech" AND NOT (active == true))`},
+		{"difference none", builder.Difference(tech, builder.None()), `category == "tech"`},
+		{"difference all", builder.Difference(tech, builder.All()), `NONE`},
+		{"difference from none", builder.Difference(builder.None(), tech), `NONE`},
+		{"difference from all", builder.Difference(builder.All(), tech), `NOT (category == "tech")`},
+		{"difference of negation", builder.Difference(tech, builder.Not(active)), `(category == "tech" AND active == true)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Err(); err != nil {
+				t.Fatalf("Filter.Err() = %v, want nil", err)
+			}
+			if got := tt.filter.String(); got != tt.want {
+				t.Errorf("Filter.String() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_SetOperations_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	bad := builder.Where("missing").Eq("x")
+	tech := builder.Where("category").Eq("tech")
+
+	tests := []struct {
+		name   string
+		filter *Filter
+	}{
+		{"intersect left", builder.Intersect(bad, tech)},
+		{"intersect absorbed", builder.Intersect(bad, builder.None())},
+		{"union right", builder.Union(tech, bad)},
+		{"union absorbed", builder.Union(bad, builder.All())},
+		{"difference left", builder.Difference(bad, tech)},
+		{"difference right", builder.Difference(tech, bad)},
+		{"difference absorbed", builder.Difference(bad, builder.All())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Err(); !errors.Is(err, ErrFieldNotFound) {
+				t.Errorf("Filter.Err() = %v, want %v", err, ErrFieldNotFound)
+			}
+		})
+	}
+}
+
+func TestBuilder_SetOperations_Match(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	tech := builder.Where("category").Eq("tech")
+	active := builder.Where("active").Eq(true)
+
+	docs := []testMetadata{
+		{Category: "tech", Active: true},
+		{Category: "tech"},
+		{Category: "science", Active: true},
+		{Category: "science"},
+	}
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   func(inA, inB bool) bool
+	}{
+		{"intersect", builder.Intersect(tech, active), func(a, b bool) bool { return a && b }},
+		{"union", builder.Union(tech, active), func(a, b bool) bool { return a || b }},
+		{"difference", builder.Difference(tech, active), func(a, b bool) bool { return a && !b }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, doc := range docs {
+				inA, _ := builder.Match(tech, doc)
+				inB, _ := builder.Match(active, doc)
+				got, err := builder.Match(tt.filter, doc)
+				if err != nil {
+					t.Fatalf("Match() error = %v", err)
+				}
+				if want := tt.want(inA, inB); got != want {
+					t.Errorf("Match(%+v) = %v, want %v", doc, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package vecna
+
+import "sync"
+
+// SliceOption configures FilterSlice.
+type SliceOption func(*sliceConfig)
+
+// sliceConfig holds the settings applied by SliceOptions.
+type sliceConfig struct {
+	workers int
+}
+
+// WithWorkers evaluates items across n goroutines.
+// Values below 2 evaluate sequentially, which is the default.
+func WithWorkers(n int) SliceOption {
+	return func(c *sliceConfig) {
+		c.workers = n
+	}
+}
+
+// FilterSlice returns the items that match the filter, in input order.
+// It serves as a client-side fallback when a vector store cannot express a
+// filter. The filter is compiled once with Compile, so field accessors are
+// resolved for the whole batch rather than per item, and items are matched
+// with Match semantics. As with Compile, a nil pointer item, or one whose
+// Comparable field fails CompareTo, does not match.
+func (b *Builder[T]) FilterSlice(f *Filter, items []T, opts ...SliceOption) ([]T, error) {
+	pred, err := b.Compile(f)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &sliceConfig{workers: 1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	matched := make([]bool, len(items))
+	if cfg.workers < 2 || len(items) < 2 {
+		for i := range items {
+			matched[i] = pred(items[i])
+		}
+	} else {
+		matchParallel(pred, items, matched, cfg.workers)
+	}
+
+	result := make([]T, 0, len(items))
+	for i, ok := range matched {
+		if ok {
+			result = append(result, items[i])
+		}
+	}
+	return result, nil
+}
+
+// matchParallel evaluates contiguous chunks of items across workers,
+// recording results by index so input order is preserved.
+func matchParallel[T any](pred func(T) bool, items []T, matched []bool, workers int) {
+	if workers > len(items) {
+		workers = len(items)
+	}
+	chunk := (len(items) + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start := w * chunk
+		end := min(start+chunk, len(items))
+		if start >= end {
+			break
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				matched[i] = pred(items[i])
+			}
+		}(start, end)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,165 @@
+package vecna
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestBuilder_FilterSlice(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	items := []testMetadata{
+		{Category: "tech", Score: 0.9},
+		{Category: "art", Score: 0.8},
+		{Category: "tech", Score: 0.2},
+		{Category: "tech", Score: 0.7},
+	}
+
+	filter := builder.And(
+		builder.Where("category").Eq("tech"),
+		builder.Where("score").Gte(0.5),
+	)
+
+	got, err := builder.FilterSlice(filter, items)
+	if err != nil {
+		t.Fatalf("FilterSlice() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Score != 0.9 || got[1].Score != 0.7 {
+		t.Errorf("FilterSlice() = %+v, want the two matching tech items in order", got)
+	}
+}
+
+func TestBuilder_FilterSlice_Parallel(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	items := make([]testMetadata, 1000)
+	for i := range items {
+		items[i] = testMetadata{Count: i, Category: fmt.Sprintf("c%d", i%3)}
+	}
+	filter := builder.Where("category").Eq("c1")
+
+	sequential, err := builder.FilterSlice(filter, items)
+	if err != nil {
+		t.Fatalf("FilterSlice() error = %v", err)
+	}
+
+	for _, workers := range []int{2, 7, 64, 5000} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			parallel, err := builder.FilterSlice(filter, items, WithWorkers(workers))
+			if err != nil {
+				t.Fatalf("FilterSlice() error = %v", err)
+			}
+			if len(parallel) != len(sequential) {
+				t.Fatalf("len = %d, want %d", len(parallel), len(sequential))
+			}
+			for i := range parallel {
+				if parallel[i].Count != sequential[i].Count {
+					t.Fatalf("item %d Count = %d, want %d (order not preserved)", i, parallel[i].Count, sequential[i].Count)
+				}
+			}
+		})
+	}
+}
+
+func TestBuilder_FilterSlice_MatchesMatch(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	categories := []string{"tech", "Tech", "science", ""}
+	items := make([]testMetadata, 200)
+	for i := range items {
+		items[i] = testMetadata{
+			Category: categories[i%len(categories)],
+			Score:    float64(i%10) / 10,
+			Count:    i % 7,
+			Active:   i%3 == 0,
+		}
+		if i%5 != 0 {
+			items[i].Tags = []string{"go", fmt.Sprintf("t%d", i%4)}
+		}
+	}
+
+	filters := map[string]*Filter{
+		"eq":       builder.Where("category").Eq("tech"),
+		"ilike":    builder.Where("category").ILike("te%"),
+		"range":    builder.Where("score").Between(0.2, 0.6),
+		"in":       builder.Where("count").In(1, 3, 5),
+		"contains": builder.Where("tags").ContainsAny("t1", "t3"),
+		"is empty": builder.Where("tags").IsEmpty(),
+		"not":      builder.Not(builder.Where("active").Eq(true)),
+		"xor":      builder.Xor(builder.Where("active").Eq(true), builder.Where("count").Gt(3)),
+		"nested":   builder.Or(builder.And(builder.Where("category").Eq("science"), builder.Where("score").Gte(0.5)), builder.Where("count").Eq(0)),
+		"all":      builder.All(),
+		"none":     builder.None(),
+		"ne":       builder.Where("category").Ne("art"),
+	}
+
+	for name, filter := range filters {
+		t.Run(name, func(t *testing.T) {
+			var want []testMetadata
+			for _, item := range items {
+				ok, err := builder.Match(filter, item)
+				if err != nil {
+					t.Fatalf("Match() error = %v", err)
+				}
+				if ok {
+					want = append(want, item)
+				}
+			}
+
+			for _, workers := range []int{1, 4} {
+				got, err := builder.FilterSlice(filter, items, WithWorkers(workers))
+				if err != nil {
+					t.Fatalf("FilterSlice() error = %v", err)
+				}
+				if len(got) != len(want) {
+					t.Fatalf("workers=%d: len(FilterSlice()) = %d, want %d", workers, len(got), len(want))
+				}
+				for i := range got {
+					if !reflect.DeepEqual(got[i], want[i]) {
+						t.Fatalf("workers=%d: FilterSlice()[%d] = %+v, want %+v", workers, i, got[i], want[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBuilder_FilterSlice_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	items := []testMetadata{{Category: "tech"}, {Category: "art"}}
+
+	t.Run("filter error", func(t *testing.T) {
+		_, err := builder.FilterSlice(builder.Where("missing").Eq("x"), items)
+		if !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("FilterSlice() error = %v, want %v", err, ErrFieldNotFound)
+		}
+	})
+
+	t.Run("unevaluable filter", func(t *testing.T) {
+		unevaluable := &Filter{op: Op(99), field: "category"}
+		_, err := builder.FilterSlice(unevaluable, items, WithWorkers(2))
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("FilterSlice() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+
+	t.Run("nil pointer item", func(t *testing.T) {
+		pointers, _ := New[*testMetadata]()
+		got, err := pointers.FilterSlice(pointers.Where("category").Ne("art"), []*testMetadata{nil, {Category: "tech"}})
+		if err != nil {
+			t.Fatalf("FilterSlice() error = %v", err)
+		}
+		if len(got) != 1 || got[0] == nil {
+			t.Errorf("FilterSlice() = %v, want only the non-nil item", got)
+		}
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		got, err := builder.FilterSlice(builder.Where("category").Eq("tech"), nil, WithWorkers(4))
+		if err != nil || len(got) != 0 {
+			t.Errorf("FilterSlice(nil) = %v, %v, want empty, nil", got, err)
+		}
+	})
+}
@@ -1,40 +1,243 @@
 package vecna
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
 
 // FilterSpec represents a serializable filter specification.
 // This enables programmatic filter construction from JSON or other external sources.
 type FilterSpec struct {
-	Op       string        `json:"op"`                 // Operator: "eq", "ne", "gt", "gte", "lt", "lte", "in", "and", "or"
-	Field    string        `json:"field,omitempty"`    // Field name (for field conditions)
-	Value    any           `json:"value,omitempty"`    // Comparison value (for field conditions)
-	Children []*FilterSpec `json:"children,omitempty"` // Child filters (for and/or)
+	Op        string          `json:"op"`                  // Operator: "eq", "ne", "gt", "gte", "lt", "lte", "in", "and", "or"
+	Field     string          `json:"field,omitempty"`     // Field name (for field conditions)
+	Value     any             `json:"value,omitempty"`     // Comparison value (for field conditions)
+	Children  []*FilterSpec   `json:"children,omitempty"`  // Child filters (for and/or)
+	ID        string          `json:"id,omitempty"`        // Node name referenced by a parent's Group
+	Group     string          `json:"group,omitempty"`     // Precedence expression over child IDs, e.g. "a AND (b OR c)"
+	Tolerance float64         `json:"tolerance,omitempty"` // Absolute tolerance (for approx)
+	Backend   string          `json:"backend,omitempty"`   // Target compiler (for raw)
+	Raw       json.RawMessage `json:"raw,omitempty"`       // Opaque backend-specific payload (for raw)
+
+	Defs map[string]*FilterSpec `json:"$defs,omitempty"` // Reusable sub-specs (root only)
+	Ref  string                 `json:"$ref,omitempty"`  // Name of a sub-spec in the root's Defs
 }
 
 // FromSpec converts a FilterSpec to a validated Filter.
 // The spec is validated against the schema defined by T.
-// Any validation errors are accessible via Filter.Err().
+// Any validation errors are accessible via Filter.Err(). Errors from nested
+// specs are prefixed with the offending node's location, e.g.
+// children[1].children[0].field "invalid", and still satisfy errors.Is.
+// An and/or spec with a single child yields that child's filter directly.
+//
+// Whole-number values decoded from JSON as float64 are converted to int for
+// int fields and uint for unsigned fields; 10.5 on an int field is rejected,
+// as is a value outside the range of the field's Go type.
+//
+// Nodes of the form {"$ref": "name"} are replaced by the sub-spec named in
+// the root's $defs (a "#/$defs/" prefix is optional), so shared subfilters
+// can be written once. Unknown and cyclic references yield ErrInvalidFilter.
+//
+// Specs nested deeper than the builder's limit (DefaultMaxDepth unless set
+// with WithMaxDepth) are rejected with ErrInvalidFilter.
+//
+// If the builder was created with WithBaseFilter, the result is an And of
+// the base filter and the filter built from spec.
 func (b *Builder[T]) FromSpec(spec *FilterSpec) *Filter {
+	if spec != nil {
+		if err := b.checkDepth(spec); err != nil {
+			return &Filter{err: err}
+		}
+		expanded, err := expandRefs(spec)
+		if err != nil {
+			return &Filter{err: err}
+		}
+		if expanded != spec {
+			// References can nest definitions inside one another
+			if err := b.checkDepth(expanded); err != nil {
+				return &Filter{err: err}
+			}
+		}
+		spec = expanded
+	}
+	return b.withBase(b.fromSpec(spec, ""))
+}
+
+// checkDepth returns ErrInvalidFilter if spec or any of its definitions nests
+// deeper than the builder's limit. It walks iteratively, so arbitrarily deep
+// input is rejected without recursion.
+func (b *Builder[T]) checkDepth(spec *FilterSpec) error {
+	if b.maxDepth <= 0 {
+		return nil
+	}
+
+	type entry struct {
+		spec  *FilterSpec
+		depth int
+	}
+	stack := []entry{{spec, 1}}
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if e.spec == nil {
+			continue
+		}
+		if e.depth > b.maxDepth {
+			return fmt.Errorf("%w: spec exceeds maximum depth of %d", ErrInvalidFilter, b.maxDepth)
+		}
+		for _, child := range e.spec.Children {
+			stack = append(stack, entry{child, e.depth + 1})
+		}
+		for _, def := range e.spec.Defs {
+			stack = append(stack, entry{def, 1})
+		}
+	}
+	return nil
+}
+
+// fromSpec converts the spec found at path within the root spec,
+// prefixing any error produced for this node with its location.
+func (b *Builder[T]) fromSpec(spec *FilterSpec, path string) *Filter {
+	filter := b.fromSpecNode(spec, path)
+	if filter.err != nil {
+		if spec != nil && spec.Field != "" {
+			path = joinPath(path, fmt.Sprintf("field %q", spec.Field))
+		}
+		filter.err = withPath(path, filter.err)
+	}
+	return filter
+}
+
+// fromSpecNode converts a single spec node; children are converted at their own paths.
+func (b *Builder[T]) fromSpecNode(spec *FilterSpec, path string) *Filter {
 	if spec == nil {
 		return &Filter{err: fmt.Errorf("%w: nil spec", ErrInvalidFilter)}
 	}
 
+	// Handle the compact group form
+	if spec.Group != "" {
+		return b.fromGroupSpec(spec, path)
+	}
+
 	op, err := parseOp(spec.Op)
 	if err != nil {
 		return &Filter{err: err}
 	}
 
 	// Handle logical operators
-	if op == And || op == Or || op == Not {
-		return b.fromLogicalSpec(op, spec.Children)
+	if op == And || op == Or || op == Not || op == Xor {
+		return b.fromLogicalSpec(op, spec.Children, path)
+	}
+
+	// Field operators never have children; reject rather than silently drop them
+	if len(spec.Children) > 0 {
+		return &Filter{
+			op:    op,
+			field: spec.Field,
+			value: spec.Value,
+			err:   fmt.Errorf("%w: %s does not accept children", ErrInvalidFilter, op),
+		}
+	}
+
+	// Handle approx, geo_box, and len, whose values need special decoding
+	switch op {
+	case Approx:
+		return b.fromApproxSpec(spec)
+	case GeoBox:
+		return b.fromGeoBoxSpec(spec)
+	case Len:
+		return b.fromLenSpec(spec)
+	case Raw:
+		return b.Raw(spec.Backend, spec.Raw)
+	case All:
+		return b.All()
+	case None:
+		return b.None()
 	}
 
 	// Handle field operators
 	return b.fromFieldSpec(op, spec.Field, spec.Value)
 }
 
+// fromApproxSpec converts an approx spec, whose value must be numeric, to a Filter.
+func (b *Builder[T]) fromApproxSpec(spec *FilterSpec) *Filter {
+	value, ok := toFloat64(spec.Value)
+	if !ok {
+		return &Filter{
+			op:    Approx,
+			field: spec.Field,
+			value: spec.Value,
+			err:   fmt.Errorf("%w: approx requires numeric value", ErrInvalidFilter),
+		}
+	}
+	return b.Where(spec.Field).Approx(value, spec.Tolerance)
+}
+
+// childPath returns the path of the i-th child of the spec at path.
+func childPath(path string, i int) string {
+	return joinPath(path, "children["+strconv.Itoa(i)+"]")
+}
+
+// ToSpec converts a filter back into a serializable FilterSpec, the inverse
+// of FromSpec. Op strings come from Op.String(); fields, values, and children
+// are preserved. Returns the filter's construction error if it has one.
+func (f *Filter) ToSpec() (*FilterSpec, error) {
+	if err := checkCompilable(f); err != nil {
+		return nil, err
+	}
+	return toSpec(f)
+}
+
+// toSpec converts a single validated filter node.
+func toSpec(f *Filter) (*FilterSpec, error) {
+	spec := &FilterSpec{Op: f.op.String(), Field: f.field, Value: f.value}
+
+	switch {
+	case f.op == Approx:
+		values, err := sliceValues(f.value)
+		if err != nil || len(values) != 2 {
+			return nil, fmt.Errorf("%w: %s requires value and tolerance", ErrInvalidFilter, f.op)
+		}
+		tolerance, ok := toFloat64(values[1])
+		if !ok {
+			return nil, fmt.Errorf("%w: %s requires numeric tolerance", ErrInvalidFilter, f.op)
+		}
+		spec.Value, spec.Tolerance = values[0], tolerance
+	case f.op == Raw:
+		raw, ok := f.value.(RawValue)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s requires a RawValue, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		spec.Value, spec.Backend, spec.Raw = nil, raw.Backend, raw.Payload
+	case isListOp(f.op):
+		// Flatten variadic typed slices so the spec holds a plain array
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return nil, err
+		}
+		spec.Value = values
+	}
+
+	if f.children != nil {
+		spec.Children = make([]*FilterSpec, len(f.children))
+		for i, child := range f.children {
+			if child == nil {
+				return nil, fmt.Errorf("%w: nil child filter", ErrInvalidFilter)
+			}
+			childSpec, err := toSpec(child)
+			if err != nil {
+				return nil, err
+			}
+			spec.Children[i] = childSpec
+		}
+	}
+	return spec, nil
+}
+
 // fromLogicalSpec converts a logical operator spec (and/or/not) to a Filter.
-func (b *Builder[T]) fromLogicalSpec(op Op, children []*FilterSpec) *Filter {
+func (b *Builder[T]) fromLogicalSpec(op Op, children []*FilterSpec, path string) *Filter {
 	if len(children) == 0 {
 		return &Filter{
 			op:  op,
@@ -42,17 +245,28 @@ func (b *Builder[T]) fromLogicalSpec(op Op, children []*FilterSpec) *Filter {
 		}
 	}
 
-	// Not requires exactly one child
+	// Not requires exactly one child, and Xor exactly two
 	if op == Not && len(children) != 1 {
 		return &Filter{
 			op:  op,
 			err: fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, op),
 		}
 	}
+	if op == Xor && len(children) != 2 {
+		return &Filter{
+			op:  op,
+			err: fmt.Errorf("%w: %s requires exactly two children", ErrInvalidFilter, op),
+		}
+	}
 
 	filters := make([]*Filter, len(children))
 	for i, child := range children {
-		filters[i] = b.FromSpec(child)
+		filters[i] = b.fromSpec(child, childPath(path, i))
+	}
+
+	// A lone And/Or child stands on its own, carrying any error it has
+	if op != Not && len(filters) == 1 {
+		return filters[0]
 	}
 
 	switch op {
@@ -62,6 +276,8 @@ func (b *Builder[T]) fromLogicalSpec(op Op, children []*FilterSpec) *Filter {
 		return b.Or(filters...)
 	case Not:
 		return b.Not(filters[0])
+	case Xor:
+		return b.Xor(filters...)
 	default:
 		return &Filter{op: op, err: fmt.Errorf("%w: unsupported logical operator %s", ErrInvalidFilter, op)}
 	}
@@ -70,6 +286,11 @@ func (b *Builder[T]) fromLogicalSpec(op Op, children []*FilterSpec) *Filter {
 // fromFieldSpec converts a field operator spec to a Filter.
 func (b *Builder[T]) fromFieldSpec(op Op, field string, value any) *Filter {
 	fb := b.Where(field)
+	converted, err := b.specValue(op, field, value)
+	if err != nil {
+		return &Filter{op: op, field: field, value: value, err: err}
+	}
+	value = converted
 
 	switch op {
 	case Eq:
@@ -94,8 +315,64 @@ func (b *Builder[T]) fromFieldSpec(op Op, field string, value any) *Filter {
 			return &Filter{op: op, field: field, value: value, err: fmt.Errorf("%w: like requires string value", ErrInvalidFilter)}
 		}
 		return fb.Like(str)
+	case ILike:
+		str, ok := value.(string)
+		if !ok {
+			return &Filter{op: op, field: field, value: value, err: fmt.Errorf("%w: ilike requires string value", ErrInvalidFilter)}
+		}
+		return fb.ILike(str)
+	case NotLike:
+		str, ok := value.(string)
+		if !ok {
+			return &Filter{op: op, field: field, value: value, err: fmt.Errorf("%w: not_like requires string value", ErrInvalidFilter)}
+		}
+		return fb.NotLike(str)
 	case Contains:
 		return fb.Contains(value)
+	case ContainsAll:
+		slice, ok := value.([]any)
+		if !ok {
+			return fb.ContainsAll(value)
+		}
+		return fb.ContainsAll(slice...)
+	case ContainsAny:
+		slice, ok := value.([]any)
+		if !ok {
+			return fb.ContainsAny(value)
+		}
+		return fb.ContainsAny(slice...)
+	case Regex:
+		str, ok := value.(string)
+		if !ok {
+			return &Filter{op: op, field: field, value: value, err: fmt.Errorf("%w: regex requires string value", ErrInvalidFilter)}
+		}
+		return fb.Regex(str)
+	case Prefix:
+		str, ok := value.(string)
+		if !ok {
+			return &Filter{op: op, field: field, value: value, err: fmt.Errorf("%w: prefix requires string value", ErrInvalidFilter)}
+		}
+		return fb.StartsWith(str)
+	case Suffix:
+		str, ok := value.(string)
+		if !ok {
+			return &Filter{op: op, field: field, value: value, err: fmt.Errorf("%w: suffix requires string value", ErrInvalidFilter)}
+		}
+		return fb.EndsWith(str)
+	case IsEmpty, IsNotEmpty:
+		if value != nil {
+			return &Filter{op: op, field: field, value: value, err: fmt.Errorf("%w: %s takes no value", ErrInvalidFilter, op)}
+		}
+		if op == IsEmpty {
+			return fb.IsEmpty()
+		}
+		return fb.IsNotEmpty()
+	case Between:
+		bounds, ok := value.([]any)
+		if !ok || len(bounds) != 2 {
+			return &Filter{op: op, field: field, value: value, err: fmt.Errorf("%w: between requires [low, high] value", ErrInvalidFilter)}
+		}
+		return fb.Between(bounds[0], bounds[1])
 	default:
 		return &Filter{
 			op:    op,
@@ -106,6 +383,89 @@ func (b *Builder[T]) fromFieldSpec(op Op, field string, value any) *Filter {
 	}
 }
 
+// specValue converts the integral float64 values JSON decoding produces to
+// int for KindInt fields and uint for KindUint fields, element-wise for In,
+// Nin, and Between, so compilers bind integers for integer columns. Values
+// outside the range of the field's Go type, such as 2^63 for an int64 field
+// or a negative number for an unsigned field, are rejected rather than
+// wrapped. Other values, such as 10.5, are left for validation to reject.
+// Fields with a ValueParser keep the raw value.
+func (b *Builder[T]) specValue(op Op, field string, value any) (any, error) {
+	if _, ok := b.parsers[field]; ok {
+		return value, nil
+	}
+	spec, ok := b.lookupField(field)
+	if !ok || (spec.Kind != KindInt && spec.Kind != KindUint) {
+		return value, nil
+	}
+	t := reflect.TypeFor[int64]()
+	if spec.Kind == KindUint {
+		t = reflect.TypeFor[uint64]()
+	}
+	if _, ft, err := b.fieldAccessor(field); err == nil {
+		t = ft
+	}
+
+	switch op {
+	case Eq, Ne, Gt, Gte, Lt, Lte:
+		return integralValue(field, t, value)
+	case In, Nin, Between:
+		values, ok := value.([]any)
+		if !ok {
+			return value, nil
+		}
+		converted := make([]any, len(values))
+		for i, v := range values {
+			c, err := integralValue(field, t, v)
+			if err != nil {
+				return nil, err
+			}
+			converted[i] = c
+		}
+		return converted, nil
+	default:
+		return value, nil
+	}
+}
+
+// integralValue converts a float64 holding a whole number to int for a
+// signed integer type t, or to uint for an unsigned one, returning
+// ErrInvalidFilter if it does not fit in t.
+func integralValue(field string, t reflect.Type, v any) (any, error) {
+	f, ok := v.(float64)
+	if !ok || f != math.Trunc(f) || math.IsInf(f, 0) {
+		return v, nil
+	}
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if f < 0 {
+			return nil, fmt.Errorf("%w: negative value %s for unsigned field %s",
+				ErrInvalidFilter, formatWhole(f), field)
+		}
+		if f >= math.Ldexp(1, t.Bits()) {
+			return nil, fmt.Errorf("%w: value %s overflows %s field %s",
+				ErrInvalidFilter, formatWhole(f), t, field)
+		}
+		return uint(f), nil
+	default:
+		bits := 64
+		if t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64 {
+			bits = t.Bits()
+		}
+		if limit := math.Ldexp(1, bits-1); f < -limit || f >= limit {
+			return nil, fmt.Errorf("%w: value %s overflows %s field %s",
+				ErrInvalidFilter, formatWhole(f), t, field)
+		}
+		return int(f), nil
+	}
+}
+
+// formatWhole formats a whole float64 exactly and without an exponent, so
+// overflow errors show 2^63 as 9223372036854775808.
+func formatWhole(f float64) string {
+	return strconv.FormatFloat(f, 'f', 0, 64)
+}
+
 // fromInSpec handles the In operator which expects a slice value.
 func (*Builder[T]) fromInSpec(fb *FieldBuilder[T], value any) *Filter {
 	// Value should be a slice when deserialized from JSON
@@ -157,6 +517,40 @@ func parseOp(s string) (Op, error) {
 		return Or, nil
 	case "not":
 		return Not, nil
+	case "between":
+		return Between, nil
+	case "regex":
+		return Regex, nil
+	case "prefix":
+		return Prefix, nil
+	case "suffix":
+		return Suffix, nil
+	case "approx":
+		return Approx, nil
+	case "contains_all":
+		return ContainsAll, nil
+	case "contains_any":
+		return ContainsAny, nil
+	case "geo_box":
+		return GeoBox, nil
+	case "raw":
+		return Raw, nil
+	case "all":
+		return All, nil
+	case "none":
+		return None, nil
+	case "ilike":
+		return ILike, nil
+	case "not_like":
+		return NotLike, nil
+	case "is_empty":
+		return IsEmpty, nil
+	case "is_not_empty":
+		return IsNotEmpty, nil
+	case "len":
+		return Len, nil
+	case "xor":
+		return Xor, nil
 	default:
 		return 0, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, s)
 	}
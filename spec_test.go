@@ -3,6 +3,9 @@ package vecna
 import (
 	"encoding/json"
 	"errors"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -249,6 +252,123 @@ func TestBuilder_FromSpec_JSON(t *testing.T) {
 	}
 }
 
+func TestBuilder_FromSpec_JSON_IntegerValues(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name string
+		json string
+		want any
+	}{
+		{"lt", `{"op":"lt","field":"count","value":10}`, 10},
+		{"in", `{"op":"in","field":"count","value":[1,2]}`, []any{1, 2}},
+		{"between", `{"op":"between","field":"count","value":[1,10]}`, []any{1, 10}},
+		{"float field", `{"op":"lt","field":"score","value":1}`, 1.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var spec FilterSpec
+			if err := json.Unmarshal([]byte(tt.json), &spec); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			f := builder.FromSpec(&spec)
+			if f.Err() != nil {
+				t.Fatalf("FromSpec() error = %v", f.Err())
+			}
+			if !reflect.DeepEqual(f.Value(), tt.want) {
+				t.Errorf("Filter.Value() = %#v, want %#v", f.Value(), tt.want)
+			}
+		})
+	}
+
+	t.Run("unsigned", func(t *testing.T) {
+		counters, _ := New[counterMetadata]()
+		f := counters.FromSpec(&FilterSpec{Op: "gte", Field: "hits", Value: float64(5)})
+		if f.Err() != nil {
+			t.Fatalf("FromSpec() error = %v", f.Err())
+		}
+		if got, want := f.Value(), any(uint(5)); got != want {
+			t.Errorf("Filter.Value() = %#v, want %#v", got, want)
+		}
+	})
+
+	t.Run("fractional", func(t *testing.T) {
+		var spec FilterSpec
+		if err := json.Unmarshal([]byte(`{"op":"lt","field":"count","value":10.5}`), &spec); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		if f := builder.FromSpec(&spec); !errors.Is(f.Err(), ErrInvalidFilter) {
+			t.Errorf("FromSpec() error = %v, want %v", f.Err(), ErrInvalidFilter)
+		}
+	})
+}
+
+type widthMetadata struct {
+	Small int8   `json:"small"`
+	Big   int64  `json:"big"`
+	Byte  uint8  `json:"byte"`
+	Huge  uint64 `json:"huge"`
+}
+
+func TestBuilder_FromSpec_IntegerRange(t *testing.T) {
+	builder, _ := New[widthMetadata]()
+
+	tests := []struct {
+		name    string
+		field   string
+		value   any
+		want    any
+		wantErr bool
+	}{
+		{"int8 max", "small", float64(127), 127, false},
+		{"int8 min", "small", float64(-128), -128, false},
+		{"int8 above max", "small", float64(128), nil, true},
+		{"int8 below min", "small", float64(-129), nil, true},
+		{"int64 below 2^63", "big", float64(1 << 62), 1 << 62, false},
+		{"int64 min", "big", -math.Ldexp(1, 63), math.MinInt64, false},
+		{"int64 2^63", "big", math.Ldexp(1, 63), nil, true},
+		{"uint8 max", "byte", float64(255), uint(255), false},
+		{"uint8 above max", "byte", float64(256), nil, true},
+		{"uint8 negative", "byte", float64(-1), nil, true},
+		{"uint64 2^63", "huge", math.Ldexp(1, 63), uint(1 << 63), false},
+		{"uint64 2^64", "huge", math.Ldexp(1, 64), nil, true},
+		{"uint64 negative", "huge", float64(-1), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := builder.FromSpec(&FilterSpec{Op: "eq", Field: tt.field, Value: tt.value})
+			if tt.wantErr {
+				if !errors.Is(f.Err(), ErrInvalidFilter) {
+					t.Errorf("FromSpec() error = %v, want %v", f.Err(), ErrInvalidFilter)
+				}
+				return
+			}
+			if f.Err() != nil {
+				t.Fatalf("FromSpec() error = %v", f.Err())
+			}
+			if f.Value() != tt.want {
+				t.Errorf("Filter.Value() = %#v, want %#v", f.Value(), tt.want)
+			}
+		})
+	}
+
+	t.Run("list element", func(t *testing.T) {
+		f := builder.FromSpec(&FilterSpec{Op: "in", Field: "small", Value: []any{float64(1), float64(300)}})
+		if !errors.Is(f.Err(), ErrInvalidFilter) {
+			t.Errorf("FromSpec() error = %v, want %v", f.Err(), ErrInvalidFilter)
+		}
+	})
+
+	t.Run("message", func(t *testing.T) {
+		f := builder.FromSpec(&FilterSpec{Op: "eq", Field: "big", Value: math.Ldexp(1, 63)})
+		if want := "value 9223372036854775808 overflows int64 field big"; f.Err() == nil || !strings.Contains(f.Err().Error(), want) {
+			t.Errorf("FromSpec() error = %v, want it to contain %q", f.Err(), want)
+		}
+	})
+}
+
 func TestBuilder_FromSpec_InvalidField(t *testing.T) {
 	builder, _ := New[testMetadata]()
 
@@ -358,10 +478,24 @@ func TestParseOp(t *testing.T) {
 		{"in", In, false},
 		{"nin", Nin, false},
 		{"like", Like, false},
+		{"ilike", ILike, false},
+		{"not_like", NotLike, false},
 		{"contains", Contains, false},
+		{"is_empty", IsEmpty, false},
+		{"is_not_empty", IsNotEmpty, false},
 		{"and", And, false},
 		{"or", Or, false},
 		{"not", Not, false},
+		{"between", Between, false},
+		{"regex", Regex, false},
+		{"prefix", Prefix, false},
+		{"suffix", Suffix, false},
+		{"approx", Approx, false},
+		{"contains_all", ContainsAll, false},
+		{"contains_any", ContainsAny, false},
+		{"geo_box", GeoBox, false},
+		{"raw", Raw, false},
+		{"xor", Xor, false},
 		{"invalid", 0, true},
 		{"", 0, true},
 		{"EQ", 0, true}, // case-sensitive
@@ -483,6 +617,167 @@ func TestBuilder_FromSpec_Like_InvalidValue(t *testing.T) {
 	}
 }
 
+func TestBuilder_FromSpec_ILike(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.FromSpec(&FilterSpec{Op: "ilike", Field: "category", Value: "%Tech%"})
+	if filter.Err() != nil {
+		t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+	}
+	if filter.Op() != ILike {
+		t.Errorf("Filter.Op() = %v, want %v", filter.Op(), ILike)
+	}
+
+	filter = builder.FromSpec(&FilterSpec{Op: "ilike", Field: "category", Value: 123})
+	if !errors.Is(filter.Err(), ErrInvalidFilter) {
+		t.Errorf("Filter.Err() = %v, want %v", filter.Err(), ErrInvalidFilter)
+	}
+}
+
+func TestBuilder_FromSpec_NotLike(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.FromSpec(&FilterSpec{Op: "not_like", Field: "category", Value: "%tech%"})
+	if filter.Err() != nil {
+		t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+	}
+	if filter.Op() != NotLike {
+		t.Errorf("Filter.Op() = %v, want %v", filter.Op(), NotLike)
+	}
+
+	filter = builder.FromSpec(&FilterSpec{Op: "not_like", Field: "category", Value: 123})
+	if !errors.Is(filter.Err(), ErrInvalidFilter) {
+		t.Errorf("Filter.Err() = %v, want %v", filter.Err(), ErrInvalidFilter)
+	}
+}
+
+func TestBuilder_FromSpec_IsEmpty(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.FromSpec(&FilterSpec{Op: "is_not_empty", Field: "tags"})
+	if filter.Err() != nil {
+		t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+	}
+	if filter.Op() != IsNotEmpty {
+		t.Errorf("Filter.Op() = %v, want %v", filter.Op(), IsNotEmpty)
+	}
+
+	filter = builder.FromSpec(&FilterSpec{Op: "is_empty", Field: "tags", Value: "go"})
+	if !errors.Is(filter.Err(), ErrInvalidFilter) {
+		t.Errorf("Filter.Err() = %v, want %v", filter.Err(), ErrInvalidFilter)
+	}
+}
+
+func TestBuilder_FromSpec_Between(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	var spec FilterSpec
+	if err := json.Unmarshal([]byte(`{"op": "between", "field": "score", "value": [0.2, 0.8]}`), &spec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	filter := builder.FromSpec(&spec)
+	if filter.Err() != nil {
+		t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+	}
+	if filter.Op() != Between {
+		t.Errorf("Filter.Op() = %v, want %v", filter.Op(), Between)
+	}
+
+	bad := builder.FromSpec(&FilterSpec{Op: "between", Field: "score", Value: []any{0.2}})
+	if !errors.Is(bad.Err(), ErrInvalidFilter) {
+		t.Errorf("Filter.Err() = %v, want %v", bad.Err(), ErrInvalidFilter)
+	}
+}
+
+func TestBuilder_FromSpec_Regex(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.FromSpec(&FilterSpec{Op: "regex", Field: "category", Value: "^te"})
+	if filter.Err() != nil {
+		t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+	}
+	if filter.Op() != Regex {
+		t.Errorf("Filter.Op() = %v, want %v", filter.Op(), Regex)
+	}
+
+	bad := builder.FromSpec(&FilterSpec{Op: "regex", Field: "category", Value: 42})
+	if !errors.Is(bad.Err(), ErrInvalidFilter) {
+		t.Errorf("Filter.Err() = %v, want %v", bad.Err(), ErrInvalidFilter)
+	}
+}
+
+func TestBuilder_FromSpec_PrefixSuffix(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	prefix := builder.FromSpec(&FilterSpec{Op: "prefix", Field: "category", Value: "te"})
+	if prefix.Err() != nil || prefix.Op() != Prefix {
+		t.Errorf("FromSpec(prefix) = %v, %v, want %v, nil", prefix.Op(), prefix.Err(), Prefix)
+	}
+
+	suffix := builder.FromSpec(&FilterSpec{Op: "suffix", Field: "category", Value: "ch"})
+	if suffix.Err() != nil || suffix.Op() != Suffix {
+		t.Errorf("FromSpec(suffix) = %v, %v, want %v, nil", suffix.Op(), suffix.Err(), Suffix)
+	}
+
+	bad := builder.FromSpec(&FilterSpec{Op: "prefix", Field: "category", Value: 1})
+	if !errors.Is(bad.Err(), ErrInvalidFilter) {
+		t.Errorf("Filter.Err() = %v, want %v", bad.Err(), ErrInvalidFilter)
+	}
+}
+
+func TestBuilder_FromSpec_Approx(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	var spec FilterSpec
+	if err := json.Unmarshal([]byte(`{"op": "approx", "field": "score", "value": 0.5, "tolerance": 0.01}`), &spec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	filter := builder.FromSpec(&spec)
+	if filter.Err() != nil {
+		t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+	}
+	if filter.Op() != Approx {
+		t.Errorf("Filter.Op() = %v, want %v", filter.Op(), Approx)
+	}
+	if !reflect.DeepEqual(filter.Value(), []any{0.5, 0.01}) {
+		t.Errorf("Filter.Value() = %v, want [0.5 0.01]", filter.Value())
+	}
+
+	bad := builder.FromSpec(&FilterSpec{Op: "approx", Field: "score", Value: "0.5"})
+	if !errors.Is(bad.Err(), ErrInvalidFilter) {
+		t.Errorf("Filter.Err() = %v, want %v", bad.Err(), ErrInvalidFilter)
+	}
+}
+
+func TestBuilder_FromSpec_ContainsAllAny(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	var spec FilterSpec
+	if err := json.Unmarshal([]byte(`{"op": "contains_any", "field": "tags", "value": ["go", "db"]}`), &spec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	filter := builder.FromSpec(&spec)
+	if filter.Err() != nil {
+		t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+	}
+	if filter.Op() != ContainsAny {
+		t.Errorf("Filter.Op() = %v, want %v", filter.Op(), ContainsAny)
+	}
+
+	ok, err := builder.Match(filter, testMetadata{Tags: []string{"db"}})
+	if err != nil || !ok {
+		t.Errorf("Match() = %v, %v, want true, nil", ok, err)
+	}
+
+	all := builder.FromSpec(&FilterSpec{Op: "contains_all", Field: "tags", Value: []string{"go", "db"}})
+	if all.Err() != nil || all.Op() != ContainsAll {
+		t.Errorf("FromSpec(contains_all) = %v, %v, want %v, nil", all.Op(), all.Err(), ContainsAll)
+	}
+}
+
 func TestBuilder_FromSpec_Contains(t *testing.T) {
 	builder, _ := New[testMetadata]()
 
@@ -556,6 +851,83 @@ func TestBuilder_FromSpec_Not_MultipleChildren(t *testing.T) {
 	}
 }
 
+func TestBuilder_FromSpec_Not_NoChildren(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.FromSpec(&FilterSpec{Op: "not"})
+	if !errors.Is(filter.Err(), ErrInvalidFilter) {
+		t.Errorf("Filter.Err() = %v, want %v", filter.Err(), ErrInvalidFilter)
+	}
+}
+
+func TestBuilder_FromSpec_NotOr_RoundTrip(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	original := builder.Not(builder.Or(
+		builder.Where("category").Eq("spam"),
+		builder.Where("tags").Contains("hidden"),
+	))
+
+	spec, err := original.ToSpec()
+	if err != nil {
+		t.Fatalf("ToSpec() error = %v", err)
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded FilterSpec
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	rebuilt := builder.FromSpec(&decoded)
+	if rebuilt.Err() != nil {
+		t.Fatalf("FromSpec() error = %v", rebuilt.Err())
+	}
+	if !rebuilt.Equal(original) {
+		t.Errorf("FromSpec(ToSpec()) = %s, want %s", rebuilt, original)
+	}
+}
+
+func TestBuilder_FromSpec_Xor(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	var spec FilterSpec
+	if err := json.Unmarshal([]byte(`{"op": "xor", "children": [
+		{"op": "eq", "field": "active", "value": true},
+		{"op": "contains", "field": "tags", "value": "sale"}
+	]}`), &spec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	f := builder.FromSpec(&spec)
+	if f.Err() != nil {
+		t.Fatalf("FromSpec() error = %v", f.Err())
+	}
+	if f.Op() != Xor || len(f.Children()) != 2 {
+		t.Fatalf("FromSpec() = %v with %d children, want xor with 2", f.Op(), len(f.Children()))
+	}
+
+	back, err := f.ToSpec()
+	if err != nil {
+		t.Fatalf("ToSpec() error = %v", err)
+	}
+	if !builder.FromSpec(back).Equal(f) {
+		t.Errorf("FromSpec(ToSpec()) = %s, want %s", builder.FromSpec(back), f)
+	}
+
+	for _, n := range []int{1, 3} {
+		children := make([]*FilterSpec, n)
+		for i := range children {
+			children[i] = &FilterSpec{Op: "eq", Field: "active", Value: true}
+		}
+		bad := builder.FromSpec(&FilterSpec{Op: "xor", Children: children})
+		if !errors.Is(bad.Err(), ErrInvalidFilter) {
+			t.Errorf("FromSpec(xor with %d children) error = %v, want %v", n, bad.Err(), ErrInvalidFilter)
+		}
+	}
+}
+
 func TestBuilder_FromSpec_Not_Nested(t *testing.T) {
 	builder, _ := New[testMetadata]()
 
@@ -590,3 +962,309 @@ func TestBuilder_FromSpec_Not_Nested(t *testing.T) {
 		t.Errorf("Filter.Err() = %v, want nil", filter.Err())
 	}
 }
+
+func TestBuilder_FromSpec_NotOr_JSON(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	jsonSpec := `{
+		"op": "not",
+		"children": [{
+			"op": "or",
+			"children": [
+				{"op": "nin", "field": "category", "value": ["spam", "junk"]},
+				{"op": "like", "field": "category", "value": "%test%"},
+				{"op": "contains", "field": "tags", "value": "hidden"}
+			]
+		}]
+	}`
+
+	var spec FilterSpec
+	if err := json.Unmarshal([]byte(jsonSpec), &spec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	filter := builder.FromSpec(&spec)
+
+	if filter.Err() != nil {
+		t.Fatalf("Filter.Err() = %v, want nil", filter.Err())
+	}
+	if filter.Op() != Not {
+		t.Errorf("Filter.Op() = %v, want %v", filter.Op(), Not)
+	}
+
+	orChild := filter.Children()[0]
+	if orChild.Op() != Or {
+		t.Fatalf("Child Filter.Op() = %v, want %v", orChild.Op(), Or)
+	}
+
+	wantOps := []Op{Nin, Like, Contains}
+	for i, child := range orChild.Children() {
+		if child.Op() != wantOps[i] {
+			t.Errorf("Grandchild %d Op() = %v, want %v", i, child.Op(), wantOps[i])
+		}
+	}
+}
+
+func TestFilter_ToSpec_RoundTrip(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	original := builder.And(
+		builder.Where("category").Eq("tech"),
+		builder.Where("score").Approx(0.5, 0.25),
+		builder.Or(
+			builder.Where("count").Between(1, 10),
+			builder.Where("tags").ContainsAny("go", "db"),
+			builder.Not(builder.Where("category").In([]string{"spam", "junk"})),
+		),
+		builder.Where("category").Regex("^te"),
+	)
+
+	spec, err := original.ToSpec()
+	if err != nil {
+		t.Fatalf("ToSpec() error = %v", err)
+	}
+	if spec.Op != "and" || len(spec.Children) != 4 {
+		t.Fatalf("ToSpec() = %s with %d children, want and with 4", spec.Op, len(spec.Children))
+	}
+	if got := spec.Children[1]; got.Value != 0.5 || got.Tolerance != 0.25 {
+		t.Errorf("approx spec = %v ± %v, want 0.5 ± 0.25", got.Value, got.Tolerance)
+	}
+	if got := spec.Children[2].Children[2].Children[0].Value; !reflect.DeepEqual(got, []any{"spam", "junk"}) {
+		t.Errorf("in spec value = %v, want flattened [spam junk]", got)
+	}
+
+	// Round-trip through JSON and compare the compiled output
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded FilterSpec
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	rebuilt := builder.FromSpec(&decoded)
+	if rebuilt.Err() != nil {
+		t.Fatalf("FromSpec() error = %v", rebuilt.Err())
+	}
+
+	want, _, err := builder.ToSQL(original)
+	if err != nil {
+		t.Fatalf("ToSQL(original) error = %v", err)
+	}
+	got, _, err := builder.ToSQL(rebuilt)
+	if err != nil {
+		t.Fatalf("ToSQL(rebuilt) error = %v", err)
+	}
+	if got != want {
+		t.Errorf("round trip ToSQL() = %s, want %s", got, want)
+	}
+
+	doc := testMetadata{Category: "tech", Score: 0.6, Count: 3}
+	wantMatch, _ := builder.Match(original, doc)
+	gotMatch, err := builder.Match(rebuilt, doc)
+	if err != nil || gotMatch != wantMatch {
+		t.Errorf("round trip Match() = %v, %v, want %v, nil", gotMatch, err, wantMatch)
+	}
+}
+
+func TestFilter_ToSpec_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	if _, err := builder.Where("missing").Eq("x").ToSpec(); !errors.Is(err, ErrFieldNotFound) {
+		t.Errorf("ToSpec() error = %v, want %v", err, ErrFieldNotFound)
+	}
+
+	var f *Filter
+	if _, err := f.ToSpec(); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("nil ToSpec() error = %v, want %v", err, ErrInvalidFilter)
+	}
+}
+
+func TestBuilder_FromSpec_FieldOpWithChildren(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	var spec FilterSpec
+	if err := json.Unmarshal([]byte(`{
+		"op": "eq",
+		"field": "category",
+		"value": "tech",
+		"children": [{"op": "eq", "field": "active", "value": true}]
+	}`), &spec); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	filter := builder.FromSpec(&spec)
+	if !errors.Is(filter.Err(), ErrInvalidFilter) {
+		t.Errorf("Filter.Err() = %v, want %v", filter.Err(), ErrInvalidFilter)
+	}
+}
+
+func TestBuilder_FromSpec_ErrorPath(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name string
+		spec *FilterSpec
+		want string
+		is   error
+	}{
+		{
+			"root field",
+			&FilterSpec{Op: "eq", Field: "invalid", Value: "x"},
+			`field "invalid": vecna: field not found: invalid`,
+			ErrFieldNotFound,
+		},
+		{
+			"nested field",
+			&FilterSpec{Op: "and", Children: []*FilterSpec{
+				{Op: "eq", Field: "category", Value: "tech"},
+				{Op: "or", Children: []*FilterSpec{
+					{Op: "eq", Field: "invalid", Value: "x"},
+				}},
+			}},
+			`children[1].children[0].field "invalid": vecna: field not found: invalid`,
+			ErrFieldNotFound,
+		},
+		{
+			"nested nil child",
+			&FilterSpec{Op: "not", Children: []*FilterSpec{nil}},
+			`children[0]: vecna: invalid filter: nil spec`,
+			ErrInvalidFilter,
+		},
+		{
+			"group child",
+			&FilterSpec{Group: "a OR b", Children: []*FilterSpec{
+				{ID: "a", Op: "eq", Field: "category", Value: "tech"},
+				{ID: "b", Op: "gt", Field: "category", Value: "x"},
+			}},
+			`children[1].field "category": vecna: invalid filter: operator gt not valid for string field category`,
+			ErrInvalidFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := builder.FromSpec(tt.spec).Err()
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Filter.Err() = %v, want %s", err, tt.want)
+			}
+			if !errors.Is(err, tt.is) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", err, tt.is)
+			}
+		})
+	}
+}
+
+func TestBuilder_FromSpec_ErrorPath_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.FromSpec(&FilterSpec{Op: "and", Children: []*FilterSpec{
+		{Op: "eq", Field: "missing", Value: "x"},
+		{Op: "eq", Field: "category", Value: "tech"},
+	}})
+
+	errs := filter.Errors()
+	if len(errs) != 1 {
+		t.Fatalf("Filter.Errors() = %v, want 1 error", errs)
+	}
+	want := `children[0].field "missing": vecna: field not found: missing`
+	if errs[0].Error() != want {
+		t.Errorf("Filter.Errors()[0] = %v, want %s", errs[0], want)
+	}
+}
+
+func TestBuilder_FromSpec_MaxDepth(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	// nestedSpec wraps a condition in depth-1 Not nodes
+	nestedSpec := func(depth int) *FilterSpec {
+		spec := &FilterSpec{Op: "eq", Field: "category", Value: "tech"}
+		for i := 1; i < depth; i++ {
+			spec = &FilterSpec{Op: "not", Children: []*FilterSpec{spec}}
+		}
+		return spec
+	}
+
+	t.Run("very deep spec", func(t *testing.T) {
+		filter := builder.FromSpec(nestedSpec(10000))
+		if !errors.Is(filter.Err(), ErrInvalidFilter) {
+			t.Errorf("FromSpec().Err() = %v, want %v", filter.Err(), ErrInvalidFilter)
+		}
+	})
+
+	t.Run("at default limit", func(t *testing.T) {
+		filter := builder.FromSpec(nestedSpec(DefaultMaxDepth))
+		if filter.Err() != nil {
+			t.Fatalf("FromSpec().Err() = %v", filter.Err())
+		}
+		if filter.Depth() != DefaultMaxDepth {
+			t.Errorf("Depth() = %d, want %d", filter.Depth(), DefaultMaxDepth)
+		}
+	})
+
+	t.Run("beyond default limit", func(t *testing.T) {
+		filter := builder.FromSpec(nestedSpec(DefaultMaxDepth + 1))
+		if !errors.Is(filter.Err(), ErrInvalidFilter) {
+			t.Errorf("FromSpec().Err() = %v, want %v", filter.Err(), ErrInvalidFilter)
+		}
+	})
+
+	t.Run("through refs", func(t *testing.T) {
+		builder, _ := New[testMetadata](WithMaxDepth(3))
+		spec := &FilterSpec{
+			Op:       "not",
+			Children: []*FilterSpec{{Ref: "outer"}},
+			Defs: map[string]*FilterSpec{
+				"outer": {Op: "not", Children: []*FilterSpec{{Ref: "inner"}}},
+				"inner": {Op: "not", Children: []*FilterSpec{{Op: "eq", Field: "category", Value: "tech"}}},
+			},
+		}
+		filter := builder.FromSpec(spec)
+		if !errors.Is(filter.Err(), ErrInvalidFilter) {
+			t.Errorf("FromSpec().Err() = %v, want %v", filter.Err(), ErrInvalidFilter)
+		}
+	})
+}
+
+func TestBuilder_FromSpec_SingleChild(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	for _, op := range []string{"and", "or"} {
+		t.Run(op, func(t *testing.T) {
+			filter := builder.FromSpec(&FilterSpec{
+				Op:       op,
+				Children: []*FilterSpec{{Op: "gte", Field: "score", Value: 0.5}},
+			})
+			if filter.Err() != nil {
+				t.Fatalf("FromSpec().Err() = %v", filter.Err())
+			}
+			if filter.Op() != Gte || filter.Field() != "score" || filter.Children() != nil {
+				t.Errorf("FromSpec() = %s, want lone child", filter)
+			}
+		})
+
+		t.Run(op+" error", func(t *testing.T) {
+			filter := builder.FromSpec(&FilterSpec{
+				Op:       op,
+				Children: []*FilterSpec{{Op: "eq", Field: "missing", Value: "x"}},
+			})
+			err := filter.Err()
+			if !errors.Is(err, ErrFieldNotFound) {
+				t.Fatalf("FromSpec().Err() = %v, want %v", err, ErrFieldNotFound)
+			}
+			if got := err.Error(); got != `children[0].field "missing": vecna: field not found: missing` {
+				t.Errorf("FromSpec().Err() = %s, want path to the child", got)
+			}
+		})
+	}
+
+	t.Run("not keeps its child", func(t *testing.T) {
+		filter := builder.FromSpec(&FilterSpec{
+			Op:       "not",
+			Children: []*FilterSpec{{Op: "eq", Field: "active", Value: true}},
+		})
+		if filter.Op() != Not {
+			t.Errorf("FromSpec().Op() = %v, want %v", filter.Op(), Not)
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package vecna
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ToSQL compiles a filter into a parameterized SQL WHERE-clause body.
+// Values are never interpolated into the returned string; they are bound
+// as $1, $2, ... placeholders in the returned args slice, in order.
+// Column names default to the field's Go name and may be overridden with WithColumn.
+func (b *Builder[T]) ToSQL(f *Filter) (string, []any, error) {
+	if err := checkCompilable(f); err != nil {
+		return "", nil, err
+	}
+	if err := checkNoEntries(f, "SQL"); err != nil {
+		return "", nil, err
+	}
+
+	c := &sqlCompiler{column: b.column, allowEmptyIn: b.allowEmptyIn}
+	clause, err := c.compile(f)
+	if err != nil {
+		return "", nil, err
+	}
+	return clause, c.args, nil
+}
+
+// column resolves the SQL column name for a field.
+func (b *Builder[T]) column(field string) string {
+	if column, ok := b.columns[field]; ok {
+		return column
+	}
+	if spec, ok := b.fields[field]; ok {
+		return spec.GoName
+	}
+	return field
+}
+
+// sqlCompiler accumulates bound arguments while rendering a filter tree.
+type sqlCompiler struct {
+	argBinder
+	column       func(string) string
+	allowEmptyIn bool // bind empty lists rather than reject them
+}
+
+// argBinder collects positional arguments for $n placeholders.
+type argBinder struct {
+	args []any
+}
+
+// bind appends a value to the argument list and returns its placeholder.
+func (a *argBinder) bind(value any) string {
+	a.args = append(a.args, value)
+	return "$" + strconv.Itoa(len(a.args))
+}
+
+// compile renders a single filter node.
+func (c *sqlCompiler) compile(f *Filter) (string, error) {
+	switch f.op {
+	case And, Or:
+		return c.compileGroup(f)
+	case Not:
+		if len(f.children) != 1 {
+			return "", fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := c.compile(f.children[0])
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + inner + ")", nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return "", err
+		}
+		return c.compile(expanded)
+	case Raw:
+		return rawClause(f, "sql")
+	case All:
+		return "TRUE", nil
+	case None:
+		return "FALSE", nil
+	}
+
+	col := quoteIdent(c.column(f.field))
+
+	if f.op == Contains || f.op == ContainsAll || f.op == ContainsAny {
+		if err := checkScalarElements(f, "SQL"); err != nil {
+			return "", err
+		}
+	}
+
+	if f.fold {
+		return c.compileFold(f, col)
+	}
+
+	switch f.op {
+	case Eq:
+		return col + " = " + c.bind(f.value), nil
+	case Ne:
+		return col + " <> " + c.bind(f.value), nil
+	case Gt:
+		return col + " > " + c.bind(f.value), nil
+	case Gte:
+		return col + " >= " + c.bind(f.value), nil
+	case Lt:
+		return col + " < " + c.bind(f.value), nil
+	case Lte:
+		return col + " <= " + c.bind(f.value), nil
+	case In, Nin:
+		values, err := c.listValues(f)
+		if err != nil {
+			return "", err
+		}
+		if f.op == Nin {
+			return col + " <> ALL(" + c.bind(values) + ")", nil
+		}
+		return col + " = ANY(" + c.bind(values) + ")", nil
+	case Like:
+		return col + " LIKE " + c.bind(f.value), nil
+	case ILike:
+		return col + " ILIKE " + c.bind(f.value), nil
+	case NotLike:
+		return col + " NOT LIKE " + c.bind(f.value), nil
+	case Contains:
+		return c.bind(f.value) + " = ANY(" + col + ")", nil
+	case IsEmpty:
+		return "cardinality(" + col + ") = 0", nil
+	case IsNotEmpty:
+		return "cardinality(" + col + ") > 0", nil
+	case Len:
+		return lenClause(f, "cardinality("+col+")", c.bind)
+	case ContainsAll, ContainsAny:
+		values, err := c.listValues(f)
+		if err != nil {
+			return "", err
+		}
+		if f.op == ContainsAll {
+			return col + " @> " + c.bind(values), nil
+		}
+		return col + " && " + c.bind(values), nil
+	case Regex:
+		return col + " ~ " + c.bind(f.value), nil
+	case Prefix, Suffix:
+		affix, ok := f.value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		if f.op == Prefix {
+			return col + " LIKE " + c.bind(escapeLike(affix)+"%"), nil
+		}
+		return col + " LIKE " + c.bind("%"+escapeLike(affix)), nil
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return "", err
+		}
+		lng := quoteIdent(c.column(box.LngField))
+		return "(" + col + " BETWEEN " + c.bind(box.MinLat) + " AND " + c.bind(box.MaxLat) +
+			" AND " + lng + " BETWEEN " + c.bind(box.MinLng) + " AND " + c.bind(box.MaxLng) + ")", nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return "", err
+		}
+		return col + " BETWEEN " + c.bind(low) + " AND " + c.bind(high), nil
+	default:
+		return "", fmt.Errorf("%w: operator %s not supported by SQL", ErrInvalidFilter, f.op)
+	}
+}
+
+// compileFold renders a case-insensitive Eq, Ne, In, or Nin by lowercasing
+// both sides; list values are lowercased before binding.
+func (c *sqlCompiler) compileFold(f *Filter, col string) (string, error) {
+	col = "LOWER(" + col + ")"
+	switch f.op {
+	case Eq:
+		return col + " = LOWER(" + c.bind(f.value) + ")", nil
+	case Ne:
+		return col + " <> LOWER(" + c.bind(f.value) + ")", nil
+	case In, Nin:
+		values, err := c.listValues(f)
+		if err != nil {
+			return "", err
+		}
+		lowered := make([]any, len(values))
+		for i, v := range values {
+			if s, ok := v.(string); ok {
+				v = strings.ToLower(s)
+			}
+			lowered[i] = v
+		}
+		if f.op == Nin {
+			return col + " <> ALL(" + c.bind(lowered) + ")", nil
+		}
+		return col + " = ANY(" + c.bind(lowered) + ")", nil
+	default:
+		return "", fmt.Errorf("%w: operator %s cannot compare case-insensitively", ErrInvalidFilter, f.op)
+	}
+}
+
+// listValues returns the elements of a list-valued filter as a []any for
+// binding as one array argument, rejecting an empty list unless
+// WithAllowEmptyIn is set.
+func (c *sqlCompiler) listValues(f *Filter) ([]any, error) {
+	values, err := sliceValues(f.value)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		if !c.allowEmptyIn {
+			return nil, fmt.Errorf("%w: %s requires at least one value", ErrInvalidFilter, f.op)
+		}
+		return []any{}, nil // A nil slice would bind as NULL
+	}
+	return values, nil
+}
+
+// compileGroup renders an And/Or node as a parenthesized expression.
+func (c *sqlCompiler) compileGroup(f *Filter) (string, error) {
+	if len(f.children) == 0 {
+		return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+	}
+
+	parts := make([]string, len(f.children))
+	for i, child := range f.children {
+		part, err := c.compile(child)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = part
+	}
+
+	sep := " AND "
+	if f.op == Or {
+		sep = " OR "
+	}
+	return "(" + strings.Join(parts, sep) + ")", nil
+}
+
+// escapeLike escapes LIKE wildcards and the escape character itself so the
+// string matches literally. PostgreSQL uses backslash as the default escape.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// quoteIdent quotes a SQL identifier, escaping embedded double quotes.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
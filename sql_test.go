@@ -0,0 +1,172 @@
+package vecna
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuilder_ToSQL(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name     string
+		filter   *Filter
+		wantSQL  string
+		wantArgs []any
+	}{
+		{"eq", builder.Where("category").Eq("tech"), `"Category" = $1`, []any{"tech"}},
+		{"ne", builder.Where("category").Ne("tech"), `"Category" <> $1`, []any{"tech"}},
+		{"gt", builder.Where("score").Gt(0.5), `"Score" > $1`, []any{0.5}},
+		{"gte", builder.Where("score").Gte(0.5), `"Score" >= $1`, []any{0.5}},
+		{"lt", builder.Where("count").Lt(10), `"Count" < $1`, []any{10}},
+		{"lte", builder.Where("count").Lte(10), `"Count" <= $1`, []any{10}},
+		{"in", builder.Where("category").In("a", "b"), `"Category" = ANY($1)`, []any{[]any{"a", "b"}}},
+		{"nin", builder.Where("category").Nin("a", "b"), `"Category" <> ALL($1)`, []any{[]any{"a", "b"}}},
+		{"in slice", builder.Where("category").In([]string{"a", "b"}), `"Category" = ANY($1)`, []any{[]any{"a", "b"}}},
+		{"like", builder.Where("category").Like("%tech%"), `"Category" LIKE $1`, []any{"%tech%"}},
+		{"ilike", builder.Where("category").ILike("%Tech%"), `"Category" ILIKE $1`, []any{"%Tech%"}},
+		{"not like", builder.Where("category").NotLike("%tech%"), `"Category" NOT LIKE $1`, []any{"%tech%"}},
+		{"contains", builder.Where("tags").Contains("go"), `$1 = ANY("Tags")`, []any{"go"}},
+		{"is empty", builder.Where("tags").IsEmpty(), `cardinality("Tags") = 0`, nil},
+		{"is not empty", builder.Where("tags").IsNotEmpty(), `cardinality("Tags") > 0`, nil},
+		{"regex", builder.Where("category").Regex("^te"), `"Category" ~ $1`, []any{"^te"}},
+		{"prefix", builder.Where("category").StartsWith("te"), `"Category" LIKE $1`, []any{"te%"}},
+		{"prefix escaping", builder.Where("category").StartsWith(`50%_off\`), `"Category" LIKE $1`, []any{`50\%\_off\\%`}},
+		{"suffix", builder.Where("category").EndsWith("ch"), `"Category" LIKE $1`, []any{"%ch"}},
+		{"approx", builder.Where("score").Approx(0.5, 0.25), `"Score" BETWEEN $1 AND $2`, []any{0.25, 0.75}},
+		{"contains all", builder.Where("tags").ContainsAll("go", "db"), `"Tags" @> $1`, []any{[]any{"go", "db"}}},
+		{"contains any", builder.Where("tags").ContainsAny("go", "db"), `"Tags" && $1`, []any{[]any{"go", "db"}}},
+		{"contains all slice", builder.Where("tags").ContainsAll([]string{"go", "db"}), `"Tags" @> $1`, []any{[]any{"go", "db"}}},
+		{"between", builder.Where("score").Between(0.2, 0.8), `"Score" BETWEEN $1 AND $2`, []any{0.2, 0.8}},
+		{
+			"nested",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Or(
+					builder.Where("score").Gte(0.5),
+					builder.Not(builder.Where("active").Eq(false)),
+				),
+			),
+			`("Category" = $1 AND ("Score" >= $2 OR NOT ("Active" = $3)))`,
+			[]any{"tech", 0.5, false},
+		},
+		{
+			"xor",
+			builder.Xor(builder.Where("active").Eq(true), builder.Where("score").Gte(0.5)),
+			`(("Active" = $1 OR "Score" >= $2) AND NOT (("Active" = $3 AND "Score" >= $4)))`,
+			[]any{true, 0.5, true, 0.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := builder.ToSQL(tt.filter)
+			if err != nil {
+				t.Fatalf("ToSQL() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSQL() sql = %s, want %s", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ToSQL() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestBuilder_ToSQL_NoInterpolation(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	sql, args, err := builder.ToSQL(builder.Where("category").Eq("'; DROP TABLE docs; --"))
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if sql != `"Category" = $1` {
+		t.Errorf("ToSQL() sql = %s, want value bound as placeholder", sql)
+	}
+	if len(args) != 1 || args[0] != "'; DROP TABLE docs; --" {
+		t.Errorf("ToSQL() args = %v, want the raw value", args)
+	}
+}
+
+func TestBuilder_ToSQL_ColumnMapping(t *testing.T) {
+	builder, _ := New[testMetadata](WithColumn("category", "doc_category"))
+
+	sql, _, err := builder.ToSQL(builder.And(
+		builder.Where("category").Eq("tech"),
+		builder.Where("score").Gt(0.5),
+	))
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if sql != `("doc_category" = $1 AND "Score" > $2)` {
+		t.Errorf("ToSQL() sql = %s", sql)
+	}
+}
+
+func TestBuilder_ToSQL_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	t.Run("filter error", func(t *testing.T) {
+		_, _, err := builder.ToSQL(builder.Where("missing").Eq("x"))
+		if !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("ToSQL() error = %v, want %v", err, ErrFieldNotFound)
+		}
+	})
+
+	t.Run("nil filter", func(t *testing.T) {
+		_, _, err := builder.ToSQL(nil)
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("ToSQL() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+
+	t.Run("empty group", func(t *testing.T) {
+		_, _, err := builder.ToSQL(builder.And())
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("ToSQL() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+
+	t.Run("map entry", func(t *testing.T) {
+		attributed, _ := New[attributedMetadata]()
+		_, _, err := attributed.ToSQL(attributed.Where("attributes.color").Eq("red"))
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("ToSQL() error = %v, want %v", err, ErrInvalidFilter)
+		}
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		_, _, err := builder.ToSQL(builder.Where("tags").ContainsAny())
+		if !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("ToSQL() error = %v, want %v", err, ErrInvalidFilter)
+		}
+
+		allowed, _ := New[testMetadata](WithAllowEmptyIn())
+		sql, args, err := allowed.ToSQL(allowed.Where("category").In())
+		if err != nil {
+			t.Fatalf("ToSQL() error = %v", err)
+		}
+		if want := `"Category" = ANY($1)`; sql != want || !reflect.DeepEqual(args, []any{[]any{}}) {
+			t.Errorf("ToSQL() = %s, %v, want %s, [[]]", sql, args, want)
+		}
+	})
+}
+
+func TestBuilder_ToSQL_Time(t *testing.T) {
+	builder, _ := New[eventMetadata]()
+
+	sql, args, err := builder.ToSQL(builder.Where("created_at").Gte("2024-01-02T03:04:05Z"))
+	if err != nil {
+		t.Fatalf("ToSQL() error = %v", err)
+	}
+	if sql != `"CreatedAt" >= $1` {
+		t.Errorf("ToSQL() sql = %s", sql)
+	}
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if len(args) != 1 || args[0] != want {
+		t.Errorf("ToSQL() args = %#v, want [%v] bound as time.Time", args, want)
+	}
+}
@@ -0,0 +1,245 @@
+package vecna
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CompileToSQLite compiles a filter into a parameterized SQLite WHERE-clause
+// body over metadata stored as JSON text in a single column. Fields are read
+// with json_extract(column, '$.field') and values are bound as ? placeholders
+// in the returned args slice. Numeric fields are wrapped in CAST(... AS REAL);
+// bools compare against the 1/0 that json_extract yields. Like, StartsWith,
+// and EndsWith render LIKE with ESCAPE '\', and NotLike NOT LIKE; note that
+// SQLite's LIKE is case-insensitive for ASCII by default. All and None
+// render as TRUE and FALSE (SQLite 3.23 or later).
+//
+// SQLite has no array type, so Contains, ContainsAll, and ContainsAny on a
+// slice field test the elements of the JSON array with
+// EXISTS (SELECT 1 FROM json_each(column, '$.field') WHERE value = ?), which
+// requires the JSON1 functions (built in since SQLite 3.38). Eq and Ne on
+// slice or unknown fields, struct elements, and Regex are not supported and
+// return ErrInvalidFilter.
+func CompileToSQLite(f *Filter, column string) (string, []any, error) {
+	if err := checkCompilable(f); err != nil {
+		return "", nil, err
+	}
+	if err := checkCaseSensitive(f, "SQLite"); err != nil {
+		return "", nil, err
+	}
+
+	c := &sqliteCompiler{column: quoteIdent(column)}
+	clause, err := c.compile(f)
+	if err != nil {
+		return "", nil, err
+	}
+	return clause, c.args, nil
+}
+
+// sqliteCompiler accumulates bound arguments while rendering a filter tree.
+type sqliteCompiler struct {
+	args   []any
+	column string
+}
+
+// bind appends a value to the argument list and returns its placeholder.
+// Times are bound as RFC3339 text, matching how they are stored in JSON.
+func (c *sqliteCompiler) bind(value any) string {
+	if t, ok := value.(time.Time); ok {
+		value = t.Format(time.RFC3339Nano)
+	}
+	c.args = append(c.args, value)
+	return "?"
+}
+
+// bindList binds each value and returns a parenthesized placeholder list.
+func (c *sqliteCompiler) bindList(values []any) string {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = c.bind(v)
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")"
+}
+
+// compile renders a single filter node.
+func (c *sqliteCompiler) compile(f *Filter) (string, error) {
+	switch f.op {
+	case And, Or:
+		if len(f.children) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(f.children))
+		for i, child := range f.children {
+			part, err := c.compile(child)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return "(" + strings.Join(parts, " "+strings.ToUpper(f.op.String())+" ") + ")", nil
+	case Not:
+		if len(f.children) != 1 {
+			return "", fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := c.compile(f.children[0])
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + inner + ")", nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return "", err
+		}
+		return c.compile(expanded)
+	case Raw:
+		return rawClause(f, "sqlite")
+	case All:
+		return "TRUE", nil
+	case None:
+		return "FALSE", nil
+	}
+
+	value := c.valueExpr(f)
+
+	if f.op == Contains || f.op == ContainsAll || f.op == ContainsAny {
+		if err := checkScalarElements(f, "SQLite"); err != nil {
+			return "", err
+		}
+	}
+
+	switch f.op {
+	case Eq, Ne:
+		if f.kind == KindSlice || f.kind == KindUnknown {
+			return "", fmt.Errorf("%w: operator %s on %s field %s not supported by SQLite", ErrInvalidFilter, f.op, f.kind, f.field)
+		}
+		if f.op == Ne {
+			return value + " <> " + c.bind(f.value), nil
+		}
+		return value + " = " + c.bind(f.value), nil
+	case Gt:
+		return value + " > " + c.bind(f.value), nil
+	case Gte:
+		return value + " >= " + c.bind(f.value), nil
+	case Lt:
+		return value + " < " + c.bind(f.value), nil
+	case Lte:
+		return value + " <= " + c.bind(f.value), nil
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return "", err
+		}
+		lng := c.valueExpr(&Filter{field: box.LngField, kind: KindFloat})
+		return "(" + value + " BETWEEN " + c.bind(box.MinLat) + " AND " + c.bind(box.MaxLat) +
+			" AND " + lng + " BETWEEN " + c.bind(box.MinLng) + " AND " + c.bind(box.MaxLng) + ")", nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return "", err
+		}
+		return value + " BETWEEN " + c.bind(low) + " AND " + c.bind(high), nil
+	case In, Nin:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		if len(values) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one value", ErrInvalidFilter, f.op)
+		}
+		if f.op == Nin {
+			return value + " NOT IN " + c.bindList(values), nil
+		}
+		return value + " IN " + c.bindList(values), nil
+	case Like:
+		return value + ` LIKE ` + c.bind(f.value) + ` ESCAPE '\'`, nil
+	case NotLike:
+		return value + ` NOT LIKE ` + c.bind(f.value) + ` ESCAPE '\'`, nil
+	case Prefix, Suffix:
+		affix, ok := f.value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		pattern := "%" + escapeLike(affix)
+		if f.op == Prefix {
+			pattern = escapeLike(affix) + "%"
+		}
+		return value + ` LIKE ` + c.bind(pattern) + ` ESCAPE '\'`, nil
+	case Contains:
+		return c.elementExists(f, "value = "+c.bind(f.value)), nil
+	case IsEmpty:
+		return c.arrayLength(f) + " = 0", nil
+	case IsNotEmpty:
+		return c.arrayLength(f) + " > 0", nil
+	case Len:
+		return lenClause(f, c.arrayLength(f), c.bind)
+	case ContainsAll:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		if len(values) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one value", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(values))
+		for i, v := range values {
+			parts[i] = c.elementExists(f, "value = "+c.bind(v))
+		}
+		return "(" + strings.Join(parts, " AND ") + ")", nil
+	case ContainsAny:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		if len(values) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one value", ErrInvalidFilter, f.op)
+		}
+		return c.elementExists(f, "value IN "+c.bindList(values)), nil
+	default:
+		return "", fmt.Errorf("%w: operator %s not supported by SQLite", ErrInvalidFilter, f.op)
+	}
+}
+
+// valueExpr renders the json_extract accessor, cast according to the field kind.
+func (c *sqliteCompiler) valueExpr(f *Filter) string {
+	extract := "json_extract(" + c.column + ", " + filterPath(f) + ")"
+	if isNumericKind(f.kind) {
+		return "CAST(" + extract + " AS REAL)"
+	}
+	return extract
+}
+
+// elementExists renders an EXISTS test over the elements of a JSON array field.
+func (c *sqliteCompiler) elementExists(f *Filter, cond string) string {
+	return "EXISTS (SELECT 1 FROM json_each(" + c.column + ", " + filterPath(f) + ") WHERE " + cond + ")"
+}
+
+// arrayLength renders the element count of a JSON array field.
+func (c *sqliteCompiler) arrayLength(f *Filter) string {
+	return "json_array_length(" + c.column + ", " + filterPath(f) + ")"
+}
+
+// filterPath renders the JSON path to the filter's value: the field, or for
+// a KindMap entry such as "attributes.color", the key within the map.
+func filterPath(f *Filter) string {
+	if field, key, ok := f.entry(); ok {
+		return sqlitePath(field, key)
+	}
+	return sqlitePath(f.field)
+}
+
+// sqlitePath renders a JSON path through keys as a single-quoted literal,
+// quoting each key that is not a plain identifier.
+func sqlitePath(keys ...string) string {
+	path := "$"
+	for _, key := range keys {
+		if key == "" || strings.ContainsFunc(key, func(r rune) bool {
+			return r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+		}) {
+			key = `"` + strings.ReplaceAll(key, `"`, `\"`) + `"`
+		}
+		path += "." + key
+	}
+	return "'" + strings.ReplaceAll(path, "'", "''") + "'"
+}
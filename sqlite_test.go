@@ -0,0 +1,143 @@
+package vecna
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCompileToSQLite(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name     string
+		filter   *Filter
+		wantSQL  string
+		wantArgs []any
+	}{
+		{"string eq", builder.Where("category").Eq("tech"), `json_extract("meta", '$.category') = ?`, []any{"tech"}},
+		{"all", builder.All(), `TRUE`, nil},
+		{"none", builder.None(), `FALSE`, nil},
+		{"string ne", builder.Where("category").Ne("tech"), `json_extract("meta", '$.category') <> ?`, []any{"tech"}},
+		{"numeric gte", builder.Where("score").Gte(0.5), `CAST(json_extract("meta", '$.score') AS REAL) >= ?`, []any{0.5}},
+		{"numeric lt", builder.Where("count").Lt(10), `CAST(json_extract("meta", '$.count') AS REAL) < ?`, []any{10}},
+		{"bool eq", builder.Where("active").Eq(true), `json_extract("meta", '$.active') = ?`, []any{true}},
+		{"between", builder.Where("count").Between(1, 5), `CAST(json_extract("meta", '$.count') AS REAL) BETWEEN ? AND ?`, []any{1, 5}},
+		{"in", builder.Where("category").In("a", "b"), `json_extract("meta", '$.category') IN (?, ?)`, []any{"a", "b"}},
+		{"nin", builder.Where("count").Nin(1, 2), `CAST(json_extract("meta", '$.count') AS REAL) NOT IN (?, ?)`, []any{1, 2}},
+		{"like", builder.Where("category").Like("te%"), `json_extract("meta", '$.category') LIKE ? ESCAPE '\'`, []any{"te%"}},
+		{"not like", builder.Where("category").NotLike("te%"), `json_extract("meta", '$.category') NOT LIKE ? ESCAPE '\'`, []any{"te%"}},
+		{"prefix", builder.Where("category").StartsWith("a_"), `json_extract("meta", '$.category') LIKE ? ESCAPE '\'`, []any{`a\_%`}},
+		{"suffix", builder.Where("category").EndsWith("ch"), `json_extract("meta", '$.category') LIKE ? ESCAPE '\'`, []any{`%ch`}},
+		{"contains", builder.Where("tags").Contains("go"), `EXISTS (SELECT 1 FROM json_each("meta", '$.tags') WHERE value = ?)`, []any{"go"}},
+		{"is empty", builder.Where("tags").IsEmpty(), `json_array_length("meta", '$.tags') = 0`, nil},
+		{"is not empty", builder.Where("tags").IsNotEmpty(), `json_array_length("meta", '$.tags') > 0`, nil},
+		{
+			"contains all",
+			builder.Where("tags").ContainsAll("go", "db"),
+			`(EXISTS (SELECT 1 FROM json_each("meta", '$.tags') WHERE value = ?) AND EXISTS (SELECT 1 FROM json_each("meta", '$.tags') WHERE value = ?))`,
+			[]any{"go", "db"},
+		},
+		{
+			"contains any",
+			builder.Where("tags").ContainsAny("go", "db"),
+			`EXISTS (SELECT 1 FROM json_each("meta", '$.tags') WHERE value IN (?, ?))`,
+			[]any{"go", "db"},
+		},
+		{
+			"grouping",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Or(builder.Where("score").Gte(0.5), builder.Not(builder.Where("category").In("old"))),
+			),
+			`(json_extract("meta", '$.category') = ? AND (CAST(json_extract("meta", '$.score') AS REAL) >= ? OR NOT (json_extract("meta", '$.category') IN (?))))`,
+			[]any{"tech", 0.5, "old"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := CompileToSQLite(tt.filter, "meta")
+			if err != nil {
+				t.Fatalf("CompileToSQLite() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("CompileToSQLite() = %s, want %s", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("CompileToSQLite() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSQLitePath(t *testing.T) {
+	tests := []struct {
+		keys []string
+		want string
+	}{
+		{[]string{"category"}, `'$.category'`},
+		{[]string{"primary-category"}, `'$."primary-category"'`},
+		{[]string{`it's "x"`}, `'$."it''s \"x\""'`},
+		{[]string{"attributes", "color"}, `'$.attributes.color'`},
+		{[]string{"attributes", "a.b"}, `'$.attributes."a.b"'`},
+	}
+
+	for _, tt := range tests {
+		if got := sqlitePath(tt.keys...); got != tt.want {
+			t.Errorf("sqlitePath(%q) = %s, want %s", tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestCompileToSQLite_MapEntries(t *testing.T) {
+	builder, _ := New[attributedMetadata]()
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		wantSQL string
+	}{
+		{"string eq", builder.Where("attributes.color").Eq("red"), `json_extract("meta", '$.attributes.color') = ?`},
+		{"numeric gte", builder.Where("scores.rank").Gte(0.5), `CAST(json_extract("meta", '$.scores.rank') AS REAL) >= ?`},
+		{"contains", builder.Where("labels.env").Contains("prod"), `EXISTS (SELECT 1 FROM json_each("meta", '$.labels.env') WHERE value = ?)`},
+		{"is empty", builder.Where("labels.env").IsEmpty(), `json_array_length("meta", '$.labels.env') = 0`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, _, err := CompileToSQLite(tt.filter, "meta")
+			if err != nil {
+				t.Fatalf("CompileToSQLite() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("CompileToSQLite() = %s, want %s", sql, tt.wantSQL)
+			}
+		})
+	}
+}
+
+func TestCompileToSQLite_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   error
+	}{
+		{"filter error", builder.Where("missing").Eq("x"), ErrFieldNotFound},
+		{"nil filter", nil, ErrInvalidFilter},
+		{"empty group", builder.And(), ErrInvalidFilter},
+		{"regex", builder.Where("category").Regex("^te"), ErrInvalidFilter},
+		{"slice eq", builder.Where("tags").Eq([]string{"go"}), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := CompileToSQLite(tt.filter, "meta")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CompileToSQLite() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
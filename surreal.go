@@ -0,0 +1,185 @@
+package vecna
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// surrealIdentPattern matches identifiers SurrealQL accepts unquoted.
+var surrealIdentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// CompileToSurreal compiles a filter into a SurrealDB WHERE expression, e.g.
+// category = "tech" AND score >= 0.5 AND "go" INSIDE tags.
+// Set membership uses INSIDE/NOT INSIDE, slice membership uses INSIDE against
+// the field, and logical operators map to AND/OR/!. Like is rendered with the
+// fuzzy ~ operator and is only supported for %text% (substring) patterns.
+// All and None render as true and false. Operators SurrealDB cannot express
+// return an error.
+func CompileToSurreal(f *Filter) (string, error) {
+	if err := checkCompilable(f); err != nil {
+		return "", err
+	}
+	if err := checkCaseSensitive(f, "SurrealDB"); err != nil {
+		return "", err
+	}
+	return compileSurreal(f)
+}
+
+// compileSurreal renders a single filter node.
+func compileSurreal(f *Filter) (string, error) {
+	switch f.op {
+	case And, Or:
+		if len(f.children) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(f.children))
+		for i, child := range f.children {
+			part, err := compileSurreal(child)
+			if err != nil {
+				return "", err
+			}
+			if isGroup(child) || child.op == Xor {
+				part = "(" + part + ")"
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, " "+strings.ToUpper(f.op.String())+" "), nil
+	case Not:
+		if len(f.children) != 1 {
+			return "", fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		inner, err := compileSurreal(f.children[0])
+		if err != nil {
+			return "", err
+		}
+		return "!(" + inner + ")", nil
+	case Xor:
+		expanded, err := expandXor(f)
+		if err != nil {
+			return "", err
+		}
+		return compileSurreal(expanded)
+	case Raw:
+		return rawClause(f, "surreal")
+	case All:
+		return "true", nil
+	case None:
+		return "false", nil
+	}
+
+	field := surrealIdent(f.field)
+	if name, key, ok := f.entry(); ok {
+		field = surrealIdent(name) + "." + surrealIdent(key)
+	}
+
+	switch f.op {
+	case Eq:
+		return surrealComparison(field, "=", f.value)
+	case Ne:
+		return surrealComparison(field, "!=", f.value)
+	case Gt:
+		return surrealComparison(field, ">", f.value)
+	case Gte:
+		return surrealComparison(field, ">=", f.value)
+	case Lt:
+		return surrealComparison(field, "<", f.value)
+	case Lte:
+		return surrealComparison(field, "<=", f.value)
+	case In:
+		return surrealList(field, "INSIDE", f.value)
+	case Nin:
+		return surrealList(field, "NOT INSIDE", f.value)
+	case Contains:
+		lit, err := scalarLiteral(f.value, surrealString)
+		if err != nil {
+			return "", err
+		}
+		return lit + " INSIDE " + field, nil
+	case ContainsAll:
+		return surrealList(field, "CONTAINSALL", f.value)
+	case ContainsAny:
+		return surrealList(field, "CONTAINSANY", f.value)
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return "", err
+		}
+		lng := surrealIdent(box.LngField)
+		return fmt.Sprintf("(%s >= %s AND %s <= %s AND %s >= %s AND %s <= %s)",
+			field, formatFloat(box.MinLat), field, formatFloat(box.MaxLat),
+			lng, formatFloat(box.MinLng), lng, formatFloat(box.MaxLng)), nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return "", err
+		}
+		lower, err := surrealComparison(field, ">=", low)
+		if err != nil {
+			return "", err
+		}
+		upper, err := surrealComparison(field, "<=", high)
+		if err != nil {
+			return "", err
+		}
+		return "(" + lower + " AND " + upper + ")", nil
+	case Prefix, Suffix:
+		affix, ok := f.value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: %s requires string value, got %T", ErrInvalidFilter, f.op, f.value)
+		}
+		fn := "string::starts_with"
+		if f.op == Suffix {
+			fn = "string::ends_with"
+		}
+		return fn + "(" + field + ", " + surrealString(affix) + ")", nil
+	case Like:
+		pattern, _ := f.value.(string)
+		if len(pattern) < 2 || pattern[0] != '%' || pattern[len(pattern)-1] != '%' ||
+			strings.ContainsAny(pattern[1:len(pattern)-1], "%_") {
+			return "", fmt.Errorf("%w: SurrealDB only supports %%text%% like patterns, got %q", ErrInvalidFilter, pattern)
+		}
+		return field + " ~ " + surrealString(pattern[1:len(pattern)-1]), nil
+	default:
+		return "", fmt.Errorf("%w: operator %s not supported by SurrealDB", ErrInvalidFilter, f.op)
+	}
+}
+
+// surrealComparison renders "field op literal".
+func surrealComparison(field, op string, value any) (string, error) {
+	lit, err := scalarLiteral(value, surrealString)
+	if err != nil {
+		return "", err
+	}
+	return field + " " + op + " " + lit, nil
+}
+
+// surrealList renders "field INSIDE [a, b]" style membership.
+func surrealList(field, op string, value any) (string, error) {
+	values, err := sliceValues(value)
+	if err != nil {
+		return "", err
+	}
+	lits := make([]string, len(values))
+	for i, v := range values {
+		lit, err := scalarLiteral(v, surrealString)
+		if err != nil {
+			return "", err
+		}
+		lits[i] = lit
+	}
+	return field + " " + op + " [" + strings.Join(lits, ", ") + "]", nil
+}
+
+// surrealIdent renders a field name, backtick-quoting it when needed.
+func surrealIdent(name string) string {
+	if surrealIdentPattern.MatchString(name) {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "\\`") + "`"
+}
+
+// surrealString double-quotes a string literal, escaping backslashes and quotes.
+func surrealString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
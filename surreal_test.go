@@ -0,0 +1,117 @@
+package vecna
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompileToSurreal(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"eq", builder.Where("category").Eq("tech"), `category = "tech"`},
+		{"all", builder.All(), `true`},
+		{"none", builder.And(builder.Where("category").Eq("tech"), builder.None()), `category = "tech" AND false`},
+		{"ne", builder.Where("category").Ne("tech"), `category != "tech"`},
+		{"gte", builder.Where("score").Gte(0.5), `score >= 0.5`},
+		{"lt", builder.Where("count").Lt(10), `count < 10`},
+		{"bool", builder.Where("active").Eq(false), `active = false`},
+		{"in", builder.Where("category").In("a", "b"), `category INSIDE ["a", "b"]`},
+		{"nin", builder.Where("category").Nin("a"), `category NOT INSIDE ["a"]`},
+		{"contains", builder.Where("tags").Contains("go"), `"go" INSIDE tags`},
+		{"prefix", builder.Where("category").StartsWith("te"), `string::starts_with(category, "te")`},
+		{"suffix", builder.Where("category").EndsWith("ch"), `string::ends_with(category, "ch")`},
+		{"approx", builder.Where("score").Approx(0.5, 0.25), `(score >= 0.25 AND score <= 0.75)`},
+		{"contains all", builder.Where("tags").ContainsAll("go", "db"), `tags CONTAINSALL ["go", "db"]`},
+		{"contains any", builder.Where("tags").ContainsAny("go", "db"), `tags CONTAINSANY ["go", "db"]`},
+		{"between", builder.Where("count").Between(1, 10), `(count >= 1 AND count <= 10)`},
+		{"like substring", builder.Where("category").Like("%tech%"), `category ~ "tech"`},
+		{"escaping", builder.Where("category").Eq(`say "hi" \o/`), `category = "say \"hi\" \\o/"`},
+		{"quoted ident", builder.Where("NoTag").Eq("x"), `NoTag = "x"`},
+		{
+			"grouping",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Where("score").Gte(0.5),
+				builder.Or(
+					builder.Where("tags").Contains("go"),
+					builder.Not(builder.Where("active").Eq(true)),
+				),
+			),
+			`category = "tech" AND score >= 0.5 AND ("go" INSIDE tags OR !(active = true))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CompileToSurreal(tt.filter)
+			if err != nil {
+				t.Fatalf("CompileToSurreal() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CompileToSurreal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileToSurreal_MapEntries(t *testing.T) {
+	builder, _ := New[attributedMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"eq", builder.Where("attributes.color").Eq("red"), `attributes.color = "red"`},
+		{"quoted key", builder.Where("attributes.a-b").Eq("x"), "attributes.`a-b` = \"x\""},
+		{"dotted key", builder.Where("attributes.a.b").Eq("x"), "attributes.`a.b` = \"x\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CompileToSurreal(tt.filter)
+			if err != nil {
+				t.Fatalf("CompileToSurreal() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CompileToSurreal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSurrealIdent(t *testing.T) {
+	if got := surrealIdent("primary-category"); got != "`primary-category`" {
+		t.Errorf("surrealIdent() = %s, want backtick-quoted", got)
+	}
+}
+
+func TestCompileToSurreal_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   error
+	}{
+		{"filter error", builder.Where("missing").Eq("x"), ErrFieldNotFound},
+		{"nil filter", nil, ErrInvalidFilter},
+		{"prefix like", builder.Where("category").Like("tech%"), ErrInvalidFilter},
+		{"inner wildcard", builder.Where("category").Like("%te_h%"), ErrInvalidFilter},
+		{"regex", builder.Where("category").Regex("^te"), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileToSurreal(tt.filter)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CompileToSurreal() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -88,3 +88,74 @@ func BenchmarkFilterErr(b *testing.B) {
 		_ = filter.Err()
 	}
 }
+
+func benchItems(n int) []BenchMetadata {
+	categories := []string{"tech", "science", "art"}
+	items := make([]BenchMetadata, n)
+	for i := range items {
+		items[i] = BenchMetadata{
+			Category: categories[i%len(categories)],
+			Score:    float64(i%100) / 100,
+			Active:   i%2 == 0,
+			Tags:     []string{"a", "b"},
+		}
+	}
+	return items
+}
+
+func BenchmarkFilterSlice(b *testing.B) {
+	builder, _ := vecna.New[BenchMetadata]()
+	items := benchItems(50000)
+	filter := builder.And(
+		builder.Where("category").Eq("tech"),
+		builder.Where("score").Gte(0.5),
+	)
+	b.ResetTimer()
+	for b.Loop() {
+		_, _ = builder.FilterSlice(filter, items)
+	}
+}
+
+func BenchmarkFilterSliceParallel(b *testing.B) {
+	builder, _ := vecna.New[BenchMetadata]()
+	items := benchItems(50000)
+	filter := builder.And(
+		builder.Where("category").Eq("tech"),
+		builder.Where("score").Gte(0.5),
+	)
+	b.ResetTimer()
+	for b.Loop() {
+		_, _ = builder.FilterSlice(filter, items, vecna.WithWorkers(4))
+	}
+}
+
+func BenchmarkMatch(b *testing.B) {
+	builder, _ := vecna.New[BenchMetadata]()
+	items := benchItems(1000)
+	filter := builder.And(
+		builder.Where("category").Eq("tech"),
+		builder.Where("score").Gte(0.5),
+	)
+	b.ResetTimer()
+	for b.Loop() {
+		for _, item := range items {
+			_, _ = builder.Match(filter, item)
+		}
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	builder, _ := vecna.New[BenchMetadata]()
+	items := benchItems(1000)
+	filter := builder.And(
+		builder.Where("category").Eq("tech"),
+		builder.Where("score").Gte(0.5),
+	)
+	pred, _ := builder.Compile(filter)
+	b.ResetTimer()
+	for b.Loop() {
+		for _, item := range items {
+			_ = pred(item)
+		}
+	}
+}
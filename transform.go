@@ -0,0 +1,362 @@
+package vecna
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Walk visits f and every descendant in depth-first pre-order, calling fn on
+// each node. It stops and returns the first error fn returns. Walk on a nil
+// filter is a no-op.
+func (f *Filter) Walk(fn func(*Filter) error) error {
+	if f == nil {
+		return nil
+	}
+	if err := fn(f); err != nil {
+		return err
+	}
+	for _, child := range f.children {
+		if err := child.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenameField returns a copy of the filter with every reference to field
+// oldName replaced by newName. Values, operators, and structure are preserved.
+// Migration tooling uses this to update stored filters after a field rename;
+// the result is not revalidated against any schema.
+func (f *Filter) RenameField(oldName, newName string) *Filter {
+	if f == nil {
+		return nil
+	}
+
+	clone := *f
+	if clone.field == oldName {
+		clone.field = newName
+		clone.key = ""
+		if field, _, ok := f.entry(); ok && strings.HasPrefix(newName, field+".") {
+			clone.key = newName[len(field)+1:]
+		}
+	}
+	if box, ok := clone.value.(GeoBoxValue); ok && box.LngField == oldName {
+		box.LngField = newName
+		clone.value = box
+	}
+	if f.children != nil {
+		clone.children = make([]*Filter, len(f.children))
+		for i, child := range f.children {
+			clone.children[i] = child.RenameField(oldName, newName)
+		}
+	}
+	return &clone
+}
+
+// RenameField returns a copy of the spec with every reference to field
+// oldName replaced by newName, including the longitude field of a geo_box
+// value and those in $defs definitions. Values, operators, and structure
+// are preserved.
+func (s *FilterSpec) RenameField(oldName, newName string) *FilterSpec {
+	if s == nil {
+		return nil
+	}
+
+	clone := *s
+	if clone.Field == oldName {
+		clone.Field = newName
+	}
+	switch box := clone.Value.(type) {
+	case GeoBoxValue:
+		if box.LngField == oldName {
+			box.LngField = newName
+			clone.Value = box
+		}
+	case map[string]any:
+		if clone.Op == GeoBox.String() && box["lng_field"] == oldName {
+			renamed := maps.Clone(box)
+			renamed["lng_field"] = newName
+			clone.Value = renamed
+		}
+	}
+	if s.Children != nil {
+		clone.Children = make([]*FilterSpec, len(s.Children))
+		for i, child := range s.Children {
+			clone.Children[i] = child.RenameField(oldName, newName)
+		}
+	}
+	if s.Defs != nil {
+		clone.Defs = make(map[string]*FilterSpec, len(s.Defs))
+		for name, def := range s.Defs {
+			clone.Defs[name] = def.RenameField(oldName, newName)
+		}
+	}
+	return &clone
+}
+
+// DefaultBalanceThreshold is the child count above which Balance rebuilds
+// an And/Or node into a binary tree.
+const DefaultBalanceThreshold = 16
+
+// Balance returns a copy of the filter in which every And/Or node with more
+// than DefaultBalanceThreshold children is rebuilt as a balanced binary tree
+// of the same operator. Semantics are preserved; backends that degrade on
+// very wide boolean nodes get logarithmic depth instead.
+func (f *Filter) Balance() *Filter {
+	return f.BalanceAt(DefaultBalanceThreshold)
+}
+
+// BalanceAt is like Balance but rebuilds And/Or nodes with more than
+// threshold children. Thresholds below 2 are treated as 2.
+func (f *Filter) BalanceAt(threshold int) *Filter {
+	if f == nil {
+		return nil
+	}
+	threshold = max(threshold, 2)
+
+	clone := *f
+	if f.children != nil {
+		clone.children = make([]*Filter, len(f.children))
+		for i, child := range f.children {
+			clone.children[i] = child.BalanceAt(threshold)
+		}
+	}
+	if isGroup(&clone) && len(clone.children) > threshold {
+		clone.children = balanceGroup(clone.op, clone.children).children
+	}
+	return &clone
+}
+
+// balanceGroup builds a balanced binary tree of op over children.
+func balanceGroup(op Op, children []*Filter) *Filter {
+	if len(children) == 1 {
+		return children[0]
+	}
+	mid := len(children) / 2
+	return &Filter{
+		op:       op,
+		children: []*Filter{balanceGroup(op, children[:mid]), balanceGroup(op, children[mid:])},
+	}
+}
+
+// Normalize returns a copy of the filter with redundant structure removed:
+// And children of an And (and Or children of an Or) are merged into their
+// parent, single-child And/Or nodes are replaced by their child, and double
+// negations Not(Not(x)) are replaced by x. Semantics are preserved, and
+// nodes carrying a construction error are kept so Err still reports it.
+// Normalize flattens trees built by Balance, so apply it first.
+func (f *Filter) Normalize() *Filter {
+	if f == nil {
+		return nil
+	}
+
+	clone := *f
+	if f.children != nil {
+		clone.children = make([]*Filter, 0, len(f.children))
+		for _, child := range f.children {
+			child = child.Normalize()
+			if isGroup(&clone) && child != nil && child.op == clone.op && child.err == nil {
+				clone.children = append(clone.children, child.children...)
+				continue
+			}
+			clone.children = append(clone.children, child)
+		}
+	}
+	if clone.err != nil {
+		return &clone
+	}
+
+	switch {
+	case isGroup(&clone):
+		return normalizeGroup(&clone)
+	case clone.op == Not && len(clone.children) == 1:
+		inner := clone.children[0]
+		if inner == nil || inner.err != nil {
+			break
+		}
+		switch {
+		case inner.op == Not && len(inner.children) == 1:
+			return inner.children[0]
+		case inner.op == All:
+			return &Filter{op: None}
+		case inner.op == None:
+			return &Filter{op: All}
+		}
+	}
+	return &clone
+}
+
+// normalizeGroup removes All and None children from an And or Or. An And
+// omits All and is None if any child is None; an Or omits None and is All
+// if any child is All, unless another child carries an error. An All
+// standing for a condition skipped by WhereIf is omitted from an Or. A
+// group left with one child is replaced by it, and one left with none by
+// All, or by None if it only held None children.
+func normalizeGroup(g *Filter) *Filter {
+	children := make([]*Filter, 0, len(g.children))
+	sawAll := false
+	for _, child := range g.children {
+		switch {
+		case isAll(child) && g.op == Or && !child.absent && !slices.ContainsFunc(g.children, invalid):
+			return child
+		case isAll(child):
+			sawAll = true
+		case isNone(child) && g.op == And:
+			return child
+		case isNone(child):
+		default:
+			children = append(children, child)
+		}
+	}
+
+	switch {
+	case len(children) == 1:
+		return children[0]
+	case len(children) > 0:
+		g.children = children
+		return g
+	case len(g.children) == 0:
+		return g // Empty groups are left for compilers to reject
+	case g.op == And || sawAll:
+		return &Filter{op: All}
+	default:
+		return &Filter{op: None}
+	}
+}
+
+// Simplify returns a normalized copy of the filter (see Normalize) in which
+// a Gte and an Lte on the same field within one And are merged into a
+// single inclusive Between, placed where the first of the pair appeared.
+// Other conditions are kept as they are, including strict Gt and Lt bounds,
+// which Between cannot express.
+func (f *Filter) Simplify() *Filter {
+	return mergeRanges(f.Normalize())
+}
+
+// mergeRanges merges range pairs in every And of a normalized tree.
+func mergeRanges(f *Filter) *Filter {
+	if f == nil || f.children == nil {
+		return f
+	}
+
+	clone := *f
+	clone.children = make([]*Filter, len(f.children))
+	for i, child := range f.children {
+		clone.children[i] = mergeRanges(child)
+	}
+	if clone.op != And || clone.err != nil {
+		return &clone
+	}
+
+	clone.children = mergeRangePairs(clone.children)
+	if len(clone.children) == 1 {
+		return clone.children[0]
+	}
+	return &clone
+}
+
+// mergeRangePairs replaces each same-field Gte/Lte pair among the children
+// of an And with a Between.
+func mergeRangePairs(children []*Filter) []*Filter {
+	merged := make([]*Filter, 0, len(children))
+	lower := make(map[string]int) // field -> position of an unpaired Gte
+	upper := make(map[string]int) // field -> position of an unpaired Lte
+
+	for _, child := range children {
+		if child == nil || child.err != nil || (child.op != Gte && child.op != Lte) {
+			merged = append(merged, child)
+			continue
+		}
+		pending, opposite := lower, upper
+		if child.op == Lte {
+			pending, opposite = upper, lower
+		}
+		if i, ok := opposite[child.field]; ok {
+			low, high := merged[i].value, child.value
+			if child.op == Gte {
+				low, high = high, low
+			}
+			merged[i] = &Filter{op: Between, field: child.field, key: child.key, value: []any{low, high}, kind: child.kind}
+			delete(opposite, child.field)
+			continue
+		}
+		if _, ok := pending[child.field]; !ok {
+			pending[child.field] = len(merged)
+		}
+		merged = append(merged, child)
+	}
+	return merged
+}
+
+// ExtractEq splits the filter for stores that can only pre-filter on
+// equality of indexed fields. It removes the Eq conditions on the named
+// fields that every match must satisfy, i.e. those reached from the root
+// through And nodes alone, and returns them as a field -> value map for
+// pushdown, along with the residual filter to apply client-side. Nothing
+// under an Or or Not is extracted, so pushdown followed by the residual
+// selects exactly what the filter does. With no fields named, Eq
+// conditions on any field are extracted.
+//
+// Only the first Eq on each field is extracted; later ones stay in the
+// residual. Case-insensitive conditions are never extracted. The residual
+// is All when nothing remains. A filter carrying a construction error is
+// returned unchanged with an empty map.
+func (f *Filter) ExtractEq(fields ...string) (map[string]any, *Filter) {
+	eq := make(map[string]any)
+	if f == nil || f.Err() != nil {
+		return eq, f
+	}
+
+	indexed := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		indexed[field] = true
+	}
+	residual := extractEq(f, func(n *Filter) bool {
+		if n.op != Eq || n.fold || (len(fields) > 0 && !indexed[n.field]) {
+			return false
+		}
+		if _, ok := eq[n.field]; ok {
+			return false
+		}
+		eq[n.field] = n.value
+		return true
+	})
+	if residual == nil {
+		residual = &Filter{op: All}
+	}
+	return eq, residual
+}
+
+// extractEq removes the conditions of f's top-level conjunction that take
+// accepts, returning what remains, or nil if nothing does.
+func extractEq(f *Filter, take func(*Filter) bool) *Filter {
+	if f.op != And {
+		if take(f) {
+			return nil
+		}
+		return f
+	}
+
+	children := make([]*Filter, 0, len(f.children))
+	changed := false
+	for _, child := range f.children {
+		rest := extractEq(child, take)
+		if rest != nil {
+			children = append(children, rest)
+		}
+		changed = changed || rest != child
+	}
+	switch {
+	case !changed:
+		return f
+	case len(children) == 0:
+		return nil
+	case len(children) == 1:
+		return children[0]
+	default:
+		clone := *f
+		clone.children = children
+		return &clone
+	}
+}
@@ -0,0 +1,574 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFilter_RenameField(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	original := builder.And(
+		builder.Where("category").Eq("tech"),
+		builder.Or(
+			builder.Where("score").Gte(0.5),
+			builder.Not(builder.Where("category").In("spam", "junk")),
+		),
+	)
+
+	renamed := original.RenameField("category", "primary_category")
+
+	if got := renamed.Children()[0].Field(); got != "primary_category" {
+		t.Errorf("depth 1 field = %s, want primary_category", got)
+	}
+	if got := renamed.Children()[1].Children()[1].Children()[0].Field(); got != "primary_category" {
+		t.Errorf("depth 3 field = %s, want primary_category", got)
+	}
+	if got := renamed.Children()[1].Children()[0].Field(); got != "score" {
+		t.Errorf("unrelated field = %s, want score", got)
+	}
+	if got := renamed.Children()[0].Value(); got != "tech" {
+		t.Errorf("value = %v, want tech", got)
+	}
+	if renamed.Op() != And || renamed.Children()[1].Op() != Or {
+		t.Error("structure should be preserved")
+	}
+
+	// The original must be untouched
+	if got := original.Children()[0].Field(); got != "category" {
+		t.Errorf("original field = %s, want category", got)
+	}
+	if got := original.Children()[1].Children()[1].Children()[0].Field(); got != "category" {
+		t.Errorf("original nested field = %s, want category", got)
+	}
+}
+
+func TestFilter_RenameField_MapEntry(t *testing.T) {
+	builder, _ := New[attributedMetadata]()
+	filter := builder.Where("attributes.color").Eq("red")
+
+	tests := []struct {
+		newName string
+		want    string
+	}{
+		{"attributes.hue", `"meta"->'attributes'->>'hue' = $1`},
+		{"color", `"meta"->>'color' = $1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.newName, func(t *testing.T) {
+			got, _, err := CompileToJSONB(filter.RenameField("attributes.color", tt.newName), "meta")
+			if err != nil {
+				t.Fatalf("CompileToJSONB() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CompileToJSONB() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_RenameField_Nil(t *testing.T) {
+	var f *Filter
+	if f.RenameField("a", "b") != nil {
+		t.Error("RenameField() on nil filter should return nil")
+	}
+}
+
+func TestFilterSpec_RenameField(t *testing.T) {
+	spec := &FilterSpec{
+		Op: "and",
+		Children: []*FilterSpec{
+			{Op: "eq", Field: "category", Value: "tech"},
+			{
+				Op: "not",
+				Children: []*FilterSpec{
+					{Op: "eq", Field: "category", Value: "spam"},
+				},
+			},
+		},
+	}
+
+	renamed := spec.RenameField("category", "primary_category")
+
+	if renamed.Children[0].Field != "primary_category" {
+		t.Errorf("depth 1 field = %s, want primary_category", renamed.Children[0].Field)
+	}
+	if renamed.Children[1].Children[0].Field != "primary_category" {
+		t.Errorf("depth 2 field = %s, want primary_category", renamed.Children[1].Children[0].Field)
+	}
+	if spec.Children[1].Children[0].Field != "category" {
+		t.Error("original spec should be untouched")
+	}
+
+	// The renamed spec validates against a schema that uses the new name
+	type migrated struct {
+		PrimaryCategory string `json:"primary_category"`
+	}
+	builder, _ := New[migrated]()
+	if err := builder.FromSpec(renamed).Err(); err != nil {
+		t.Errorf("FromSpec(renamed).Err() = %v, want nil", err)
+	}
+
+	t.Run("defs", func(t *testing.T) {
+		spec := &FilterSpec{
+			Op: "or",
+			Defs: map[string]*FilterSpec{
+				"tech": {Op: "eq", Field: "category", Value: "tech"},
+			},
+			Children: []*FilterSpec{
+				{Ref: "tech"},
+				{Op: "eq", Field: "category", Value: "science"},
+			},
+		}
+
+		renamed := spec.RenameField("category", "primary_category")
+		if got := renamed.Defs["tech"].Field; got != "primary_category" {
+			t.Errorf("Defs[tech].Field = %s, want primary_category", got)
+		}
+		if spec.Defs["tech"].Field != "category" {
+			t.Error("original defs should be untouched")
+		}
+		if err := builder.FromSpec(renamed).Err(); err != nil {
+			t.Errorf("FromSpec(renamed).Err() = %v, want nil", err)
+		}
+	})
+
+	t.Run("geo box longitude", func(t *testing.T) {
+		type movedPlace struct {
+			Lat float64 `json:"lat"`
+			Lon float64 `json:"lon"`
+		}
+		places, _ := New[placeMetadata]()
+		moved, _ := New[movedPlace]()
+		spec, err := places.GeoBox("lat", "lng", 1, 2, 3, 4).ToSpec()
+		if err != nil {
+			t.Fatalf("ToSpec() error = %v", err)
+		}
+
+		data, err := json.Marshal(spec)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		var decoded FilterSpec
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+
+		want := moved.GeoBox("lat", "lon", 1, 2, 3, 4)
+		for name, spec := range map[string]*FilterSpec{"box value": spec, "decoded map": &decoded} {
+			rebuilt := moved.FromSpec(spec.RenameField("lng", "lon"))
+			if rebuilt.Err() != nil {
+				t.Fatalf("%s: FromSpec(renamed).Err() = %v, want nil", name, rebuilt.Err())
+			}
+			if !rebuilt.Equal(want) {
+				t.Errorf("%s: FromSpec(renamed) = %s, want %s", name, rebuilt, want)
+			}
+		}
+		if decoded.Value.(map[string]any)["lng_field"] != "lng" {
+			t.Error("original decoded value should be untouched")
+		}
+	})
+}
+
+// filterDepth returns the number of levels in a filter tree.
+func filterDepth(f *Filter) int {
+	depth := 0
+	for _, child := range f.Children() {
+		depth = max(depth, filterDepth(child))
+	}
+	return depth + 1
+}
+
+func TestFilter_Balance(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	children := make([]*Filter, 1000)
+	for i := range children {
+		children[i] = builder.Where("count").Eq(i)
+	}
+	wide := builder.Or(children...)
+
+	balanced := wide.Balance()
+
+	if got := filterDepth(wide); got != 2 {
+		t.Fatalf("wide depth = %d, want 2", got)
+	}
+	if got := filterDepth(balanced); got > 11 {
+		t.Errorf("balanced depth = %d, want <= 11", got)
+	}
+	for _, node := range balanced.Children() {
+		if node.Op() != Or {
+			t.Errorf("inner node op = %v, want %v", node.Op(), Or)
+		}
+	}
+
+	for _, count := range []int{0, 499, 999, 1000, -1} {
+		doc := testMetadata{Count: count}
+		want, err := builder.Match(wide, doc)
+		if err != nil {
+			t.Fatalf("Match(wide) error = %v", err)
+		}
+		got, err := builder.Match(balanced, doc)
+		if err != nil {
+			t.Fatalf("Match(balanced) error = %v", err)
+		}
+		if got != want {
+			t.Errorf("Match(balanced, count=%d) = %v, want %v", count, got, want)
+		}
+	}
+
+	// The original must be untouched
+	if len(wide.Children()) != 1000 {
+		t.Errorf("original children = %d, want 1000", len(wide.Children()))
+	}
+}
+
+func TestFilter_Balance_BelowThreshold(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.And(
+		builder.Where("category").Eq("tech"),
+		builder.Where("score").Gte(0.5),
+		builder.Where("active").Eq(true),
+	)
+
+	balanced := filter.Balance()
+	if len(balanced.Children()) != 3 {
+		t.Errorf("Balance() children = %d, want 3", len(balanced.Children()))
+	}
+
+	balanced = filter.BalanceAt(2)
+	if len(balanced.Children()) != 2 {
+		t.Errorf("BalanceAt(2) children = %d, want 2", len(balanced.Children()))
+	}
+	ok, err := builder.Match(balanced, testMetadata{Category: "tech", Score: 0.5, Active: true})
+	if err != nil || !ok {
+		t.Errorf("Match(balanced) = %v, %v, want true, nil", ok, err)
+	}
+}
+
+func TestFilter_Balance_Nil(t *testing.T) {
+	var f *Filter
+	if f.Balance() != nil {
+		t.Error("Balance() on nil filter should return nil")
+	}
+}
+
+func TestFilter_Walk(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	filter := builder.And(
+		builder.Where("category").Eq("tech"),
+		builder.Or(
+			builder.Where("score").Gte(0.5),
+			builder.Not(builder.Where("count").Lt(3)),
+		),
+	)
+
+	var ops []Op
+	err := filter.Walk(func(f *Filter) error {
+		ops = append(ops, f.Op())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	want := []Op{And, Eq, Or, Gte, Not, Lt}
+	if len(ops) != len(want) {
+		t.Fatalf("Walk() visited %v, want %v", ops, want)
+	}
+	for i := range want {
+		if ops[i] != want[i] {
+			t.Errorf("visit %d = %s, want %s", i, ops[i], want[i])
+		}
+	}
+
+	t.Run("stops on error", func(t *testing.T) {
+		errDeprecated := errors.New("deprecated field")
+		visited := 0
+		err := filter.Walk(func(f *Filter) error {
+			visited++
+			if f.Field() == "score" {
+				return errDeprecated
+			}
+			return nil
+		})
+		if !errors.Is(err, errDeprecated) {
+			t.Errorf("Walk() error = %v, want %v", err, errDeprecated)
+		}
+		if visited != 4 {
+			t.Errorf("Walk() visited %d nodes, want 4", visited)
+		}
+	})
+
+	t.Run("nil filter", func(t *testing.T) {
+		var f *Filter
+		if err := f.Walk(func(*Filter) error { return errors.New("called") }); err != nil {
+			t.Errorf("Walk() error = %v, want nil", err)
+		}
+	})
+}
+
+func TestFilter_Normalize(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	a := builder.Where("category").Eq("a")
+	b := builder.Where("count").Eq(1)
+	c := builder.Where("active").Eq(true)
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"flatten and", builder.And(builder.And(a, b), c), `(category == "a" AND count == 1 AND active == true)`},
+		{"flatten nested or", builder.Or(a, builder.Or(b, builder.Or(c))), `(category == "a" OR count == 1 OR active == true)`},
+		{"keep mixed", builder.And(a, builder.Or(b, c)), `(category == "a" AND (count == 1 OR active == true))`},
+		{"single child", builder.And(builder.Or(a)), `category == "a"`},
+		{"double not", builder.Not(builder.Not(a)), `category == "a"`},
+		{"triple not", builder.Not(builder.Not(builder.Not(a))), `NOT (category == "a")`},
+		{"not inside group", builder.And(a, builder.Not(builder.Not(builder.And(b, c)))), `(category == "a" AND count == 1 AND active == true)`},
+		{"leaf", a, `category == "a"`},
+		{"nil", nil, `<nil>`},
+		{"and with none", builder.And(a, builder.None(), b), `NONE`},
+		{"or drops none", builder.Or(a, builder.None(), b), `(category == "a" OR count == 1)`},
+		{"or of nones", builder.Or(builder.None(), builder.None()), `NONE`},
+		{"nested none", builder.And(a, builder.Or(builder.None(), builder.None())), `NONE`},
+		{"and of nested all", builder.And(builder.Not(builder.None()), a), `category == "a"`},
+		{"or of nested all", builder.Or(a, builder.Not(builder.None()), b), `ALL`},
+		{"not all", builder.Not(builder.All()), `NONE`},
+		{"not none", builder.Not(builder.None()), `ALL`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Normalize().String(); got != tt.want {
+				t.Errorf("Normalize() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("original untouched", func(t *testing.T) {
+		nested := builder.And(builder.And(a, b), c)
+		nested.Normalize()
+		if len(nested.Children()) != 2 {
+			t.Errorf("original children = %d, want 2", len(nested.Children()))
+		}
+	})
+
+	t.Run("propagates errors", func(t *testing.T) {
+		filter := builder.And(builder.And(a, builder.Where("missing").Eq("x")), c)
+		if err := filter.Normalize().Err(); !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("Normalize().Err() = %v, want %v", err, ErrFieldNotFound)
+		}
+	})
+
+	t.Run("or with all keeps errors", func(t *testing.T) {
+		filter := builder.Or(builder.Where("missing").Eq("x"), builder.Not(builder.None()))
+		if err := filter.Normalize().Err(); !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("Normalize().Err() = %v, want %v", err, ErrFieldNotFound)
+		}
+	})
+
+	t.Run("preserves semantics", func(t *testing.T) {
+		filter := builder.Or(
+			builder.And(builder.And(a, b), builder.Not(builder.Not(c))),
+			builder.Or(builder.Where("score").Gt(0.9)),
+		)
+		normalized := filter.Normalize()
+		for _, doc := range []testMetadata{
+			{Category: "a", Count: 1, Active: true},
+			{Category: "a", Count: 1},
+			{Score: 1},
+			{},
+		} {
+			want, _ := builder.Match(filter, doc)
+			got, _ := builder.Match(normalized, doc)
+			if got != want {
+				t.Errorf("Match(normalized, %+v) = %v, want %v", doc, got, want)
+			}
+		}
+	})
+}
+
+func TestFilter_Simplify(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{
+			"merge pair",
+			builder.And(builder.Where("score").Gte(0.2), builder.Where("score").Lte(0.8)),
+			`score BETWEEN 0.2 AND 0.8`,
+		},
+		{
+			"upper bound first",
+			builder.And(builder.Where("count").Lte(10), builder.Where("count").Gte(1)),
+			`count BETWEEN 1 AND 10`,
+		},
+		{
+			"extra conditions kept",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Where("count").Gte(1),
+				builder.Where("active").Eq(true),
+				builder.Where("count").Lte(10),
+			),
+			`(category == "tech" AND count BETWEEN 1 AND 10 AND active == true)`,
+		},
+		{
+			"after flattening",
+			builder.And(builder.Where("count").Gte(1), builder.And(builder.Where("score").Gt(0.5), builder.Where("count").Lte(10))),
+			`(count BETWEEN 1 AND 10 AND score > 0.5)`,
+		},
+		{
+			"mismatched fields",
+			builder.And(builder.Where("score").Gte(0.2), builder.Where("count").Lte(10)),
+			`(score >= 0.2 AND count <= 10)`,
+		},
+		{
+			"strict bounds",
+			builder.And(builder.Where("score").Gt(0.2), builder.Where("score").Lt(0.8)),
+			`(score > 0.2 AND score < 0.8)`,
+		},
+		{
+			"mixed strictness",
+			builder.And(builder.Where("score").Gt(0.2), builder.Where("score").Lte(0.8)),
+			`(score > 0.2 AND score <= 0.8)`,
+		},
+		{
+			"extra bound",
+			builder.And(builder.Where("count").Gte(1), builder.Where("count").Gte(3), builder.Where("count").Lte(10)),
+			`(count BETWEEN 1 AND 10 AND count >= 3)`,
+		},
+		{
+			"or untouched",
+			builder.Or(builder.Where("count").Gte(1), builder.Where("count").Lte(10)),
+			`(count >= 1 OR count <= 10)`,
+		},
+		{
+			"nested and",
+			builder.Or(
+				builder.Where("active").Eq(true),
+				builder.And(builder.Where("score").Gte(0.2), builder.Where("score").Lte(0.8)),
+			),
+			`(active == true OR score BETWEEN 0.2 AND 0.8)`,
+		},
+	}
+
+	docs := []testMetadata{
+		{Score: 0.1, Count: 0},
+		{Score: 0.2, Count: 1},
+		{Score: 0.5, Count: 3, Category: "tech", Active: true},
+		{Score: 0.8, Count: 10, Category: "tech", Active: true},
+		{Score: 0.9, Count: 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simplified := tt.filter.Simplify()
+			if got := simplified.String(); got != tt.want {
+				t.Errorf("Simplify() = %s, want %s", got, tt.want)
+			}
+			for _, doc := range docs {
+				want, _ := builder.Match(tt.filter, doc)
+				got, err := builder.Match(simplified, doc)
+				if err != nil {
+					t.Fatalf("Match() error = %v", err)
+				}
+				if got != want {
+					t.Errorf("Match(simplified, %+v) = %v, want %v", doc, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFilter_ExtractEq(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	folded, _ := New[testMetadata](WithCaseInsensitive("category"))
+	tech := builder.Where("category").Eq("tech")
+	active := builder.Where("active").Eq(true)
+	score := builder.Where("score").Gte(0.5)
+
+	tests := []struct {
+		name         string
+		filter       *Filter
+		fields       []string
+		wantEq       map[string]any
+		wantResidual string
+	}{
+		{"single eq", tech, []string{"category"}, map[string]any{"category": "tech"}, `ALL`},
+		{"indexed only", builder.And(tech, active, score), []string{"category"},
+			map[string]any{"category": "tech"}, `(active == true AND score >= 0.5)`},
+		{"any field", builder.And(tech, active, score), nil,
+			map[string]any{"category": "tech", "active": true}, `score >= 0.5`},
+		{"nested and", builder.And(score, builder.And(tech, active)), []string{"category"},
+			map[string]any{"category": "tech"}, `(score >= 0.5 AND active == true)`},
+		{"under or", builder.Or(tech, active), nil, map[string]any{}, `(category == "tech" OR active == true)`},
+		{"under not", builder.And(builder.Not(tech), active), []string{"category"},
+			map[string]any{}, `(NOT (category == "tech") AND active == true)`},
+		{"duplicate field", builder.And(tech, builder.Where("category").Eq("art")), nil,
+			map[string]any{"category": "tech"}, `category == "art"`},
+		{"case-insensitive", folded.And(folded.Where("category").Eq("Tech"), active), nil,
+			map[string]any{"active": true}, `category == "Tech" IGNORING CASE`},
+		{"no eq", score, nil, map[string]any{}, `score >= 0.5`},
+	}
+
+	docs := []testMetadata{
+		{Category: "tech", Score: 0.75, Active: true},
+		{Category: "tech", Score: 0.25},
+		{Category: "art", Score: 0.75, Active: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eq, residual := tt.filter.ExtractEq(tt.fields...)
+			if !reflect.DeepEqual(eq, tt.wantEq) {
+				t.Errorf("ExtractEq() eq = %v, want %v", eq, tt.wantEq)
+			}
+			if got := residual.String(); got != tt.wantResidual {
+				t.Errorf("ExtractEq() residual = %s, want %s", got, tt.wantResidual)
+			}
+			for _, doc := range docs {
+				want, _ := builder.Match(tt.filter, doc)
+				got, err := builder.Match(residual, doc)
+				if err != nil {
+					t.Fatalf("Match() error = %v", err)
+				}
+				for field, value := range eq {
+					got = got && matchEq(builder, field, value, doc)
+				}
+				if got != want {
+					t.Errorf("ExtractEq() on %+v = %v, want %v", doc, got, want)
+				}
+			}
+		})
+	}
+}
+
+func matchEq(builder *Builder[testMetadata], field string, value any, doc testMetadata) bool {
+	ok, _ := builder.Match(builder.Where(field).Eq(value), doc)
+	return ok
+}
+
+func TestFilter_ExtractEq_Unchanged(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	bad := builder.And(builder.Where("missing").Eq(1), builder.Where("category").Eq("tech"))
+	eq, residual := bad.ExtractEq()
+	if len(eq) != 0 || residual != bad {
+		t.Errorf("ExtractEq() on error filter = %v, %v, want empty map and the filter", eq, residual)
+	}
+
+	var nilFilter *Filter
+	eq, residual = nilFilter.ExtractEq()
+	if len(eq) != 0 || residual != nil {
+		t.Errorf("ExtractEq() on nil = %v, %v, want empty map and nil", eq, residual)
+	}
+}
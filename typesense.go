@@ -0,0 +1,196 @@
+package vecna
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ToTypesense compiles a filter into a Typesense filter_by expression, such
+// as category:=tech && price:[100..500] && tags:=[a,b]. Eq and Ne map to
+// field:=value and field:!=value, In and Nin to field:=[a,b] and
+// field:!=[a,b], Gt, Gte, Lt, and Lte to field:>x and the like, and
+// Between and Approx to the inclusive range field:[lo..hi]. On array fields
+// Contains maps to field:=value, ContainsAny to field:=[a,b], and
+// ContainsAll to one such condition per value. GeoBox renders as a range
+// per field. And and Or map to && and ||, nested groups parenthesized.
+//
+// Typesense has no general negation, so Not is only accepted around Eq,
+// Ne, In, and Nin, which it inverts; Xor and other negations return
+// ErrInvalidFilter. String values containing spaces or filter syntax
+// characters are wrapped in backticks; a value containing a backtick cannot
+// be escaped and returns ErrInvalidFilter. Time values, Like, ILike,
+// NotLike, Regex, StartsWith, EndsWith, IsEmpty, IsNotEmpty, Len, All,
+// None, and case-insensitive comparisons also return ErrInvalidFilter, as
+// does any node with a construction error. Raw filters for backend
+// "typesense" are emitted verbatim.
+func (*Builder[T]) ToTypesense(f *Filter) (string, error) {
+	if err := checkCompilable(f); err != nil {
+		return "", err
+	}
+	if err := checkCaseSensitive(f, "Typesense"); err != nil {
+		return "", err
+	}
+	return compileTypesense(f)
+}
+
+// compileTypesense renders a single filter node.
+func compileTypesense(f *Filter) (string, error) {
+	switch f.op {
+	case And, Or:
+		if len(f.children) == 0 {
+			return "", fmt.Errorf("%w: %s requires at least one child", ErrInvalidFilter, f.op)
+		}
+		parts := make([]string, len(f.children))
+		for i, child := range f.children {
+			part, err := compileTypesense(child)
+			if err != nil {
+				return "", err
+			}
+			if isGroup(child) {
+				part = "(" + part + ")"
+			}
+			parts[i] = part
+		}
+		sep := " && "
+		if f.op == Or {
+			sep = " || "
+		}
+		return strings.Join(parts, sep), nil
+	case Not:
+		if len(f.children) != 1 {
+			return "", fmt.Errorf("%w: %s requires exactly one child", ErrInvalidFilter, f.op)
+		}
+		return typesenseNegate(f.children[0])
+	case Raw:
+		return rawClause(f, "typesense")
+	case Eq, Contains:
+		return typesenseCondition(f.field, ":=", f.value)
+	case Ne:
+		return typesenseCondition(f.field, ":!=", f.value)
+	case Gt:
+		return typesenseCondition(f.field, ":>", f.value)
+	case Gte:
+		return typesenseCondition(f.field, ":>=", f.value)
+	case Lt:
+		return typesenseCondition(f.field, ":<", f.value)
+	case Lte:
+		return typesenseCondition(f.field, ":<=", f.value)
+	case In, ContainsAny:
+		return typesenseList(f.field, ":=", f.value)
+	case Nin:
+		return typesenseList(f.field, ":!=", f.value)
+	case ContainsAll:
+		values, err := sliceValues(f.value)
+		if err != nil {
+			return "", err
+		}
+		parts := make([]string, len(values))
+		for i, v := range values {
+			if parts[i], err = typesenseCondition(f.field, ":=", v); err != nil {
+				return "", err
+			}
+		}
+		return "(" + strings.Join(parts, " && ") + ")", nil
+	case Between, Approx:
+		low, high, err := rangeBounds(f)
+		if err != nil {
+			return "", err
+		}
+		return typesenseRange(f.field, low, high)
+	case GeoBox:
+		box, err := geoBoxValue(f)
+		if err != nil {
+			return "", err
+		}
+		lat, err := typesenseRange(f.field, box.MinLat, box.MaxLat)
+		if err != nil {
+			return "", err
+		}
+		lng, err := typesenseRange(box.LngField, box.MinLng, box.MaxLng)
+		if err != nil {
+			return "", err
+		}
+		return "(" + lat + " && " + lng + ")", nil
+	default:
+		return "", fmt.Errorf("%w: operator %s not supported by Typesense", ErrInvalidFilter, f.op)
+	}
+}
+
+// typesenseNegate renders the negation of an equality or membership
+// condition by inverting its operator.
+func typesenseNegate(f *Filter) (string, error) {
+	if f == nil {
+		return "", fmt.Errorf("%w: nil filter", ErrInvalidFilter)
+	}
+	switch f.op {
+	case Eq:
+		return typesenseCondition(f.field, ":!=", f.value)
+	case Ne:
+		return typesenseCondition(f.field, ":=", f.value)
+	case In:
+		return typesenseList(f.field, ":!=", f.value)
+	case Nin:
+		return typesenseList(f.field, ":=", f.value)
+	default:
+		return "", fmt.Errorf("%w: %s of %s not supported by Typesense", ErrInvalidFilter, Not, f.op)
+	}
+}
+
+// typesenseCondition renders "field<op>value".
+func typesenseCondition(field, op string, value any) (string, error) {
+	lit, err := typesenseValue(value)
+	if err != nil {
+		return "", err
+	}
+	return field + op + lit, nil
+}
+
+// typesenseList renders "field<op>[a,b]".
+func typesenseList(field, op string, value any) (string, error) {
+	values, err := sliceValues(value)
+	if err != nil {
+		return "", err
+	}
+	lits := make([]string, len(values))
+	for i, v := range values {
+		if lits[i], err = typesenseValue(v); err != nil {
+			return "", err
+		}
+	}
+	return field + op + "[" + strings.Join(lits, ",") + "]", nil
+}
+
+// typesenseRange renders the inclusive range "field:[low..high]".
+func typesenseRange(field string, low, high any) (string, error) {
+	lower, err := typesenseValue(low)
+	if err != nil {
+		return "", err
+	}
+	upper, err := typesenseValue(high)
+	if err != nil {
+		return "", err
+	}
+	return field + ":[" + lower + ".." + upper + "]", nil
+}
+
+// typesenseValue renders a filter value, backtick-quoting strings that
+// contain spaces or filter syntax characters.
+func typesenseValue(value any) (string, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return "", fmt.Errorf("%w: time values not supported by Typesense; filter on a numeric timestamp field", ErrInvalidFilter)
+	case string:
+		if strings.Contains(v, "`") {
+			return "", fmt.Errorf("%w: Typesense cannot escape the backtick in %q", ErrInvalidFilter, v)
+		}
+		if v == "" || strings.ContainsAny(v, typesenseSyntax) {
+			return "`" + v + "`", nil
+		}
+		return v, nil
+	}
+	return scalarLiteral(value, nil)
+}
+
+// typesenseSyntax holds the characters that end an unquoted filter value.
+const typesenseSyntax = " \t\n,&|()[]:=!<>."
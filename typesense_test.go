@@ -0,0 +1,131 @@
+package vecna
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBuilder_ToTypesense(t *testing.T) {
+	builder, _ := New[testMetadata]()
+
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   string
+	}{
+		{"eq", builder.Where("category").Eq("tech"), "category:=tech"},
+		{"ne", builder.Where("category").Ne("tech"), "category:!=tech"},
+		{"gt", builder.Where("score").Gt(0.5), "score:>0.5"},
+		{"gte", builder.Where("score").Gte(0.5), "score:>=0.5"},
+		{"lt", builder.Where("count").Lt(10), "count:<10"},
+		{"lte negative", builder.Where("count").Lte(-1), "count:<=-1"},
+		{"bool", builder.Where("active").Eq(true), "active:=true"},
+		{"in", builder.Where("category").In("a", "b"), "category:=[a,b]"},
+		{"nin", builder.Where("category").Nin("a", "b"), "category:!=[a,b]"},
+		{"between", builder.Where("count").Between(100, 500), "count:[100..500]"},
+		{"approx", builder.Where("score").Approx(0.5, 0.25), "score:[0.25..0.75]"},
+		{"contains", builder.Where("tags").Contains("go"), "tags:=go"},
+		{"contains any", builder.Where("tags").ContainsAny("go", "db"), "tags:=[go,db]"},
+		{"contains all", builder.Where("tags").ContainsAll("go", "db"), "(tags:=go && tags:=db)"},
+		{"quoted space", builder.Where("category").Eq("data science"), "category:=`data science`"},
+		{"quoted syntax", builder.Where("category").In("a,b", "c&&d", "x"), "category:=[`a,b`,`c&&d`,x]"},
+		{"quoted empty", builder.Where("category").Eq(""), "category:=``"},
+		{"not eq", builder.Not(builder.Where("category").Eq("spam")), "category:!=spam"},
+		{"not nin", builder.Not(builder.Where("category").Nin("a", "b")), "category:=[a,b]"},
+		{"raw", builder.Raw("typesense", json.RawMessage(`"location:(48.85, 2.29, 5 km)"`)), "(location:(48.85, 2.29, 5 km))"},
+		{
+			"and",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Where("count").Between(100, 500),
+				builder.Where("tags").ContainsAny("a", "b"),
+			),
+			"category:=tech && count:[100..500] && tags:=[a,b]",
+		},
+		{
+			"nested",
+			builder.And(
+				builder.Where("category").Eq("tech"),
+				builder.Or(
+					builder.Where("score").Gt(0.5),
+					builder.Where("active").Eq(true),
+				),
+			),
+			"category:=tech && (score:>0.5 || active:=true)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := builder.ToTypesense(tt.filter)
+			if err != nil {
+				t.Fatalf("ToTypesense() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToTypesense() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_ToTypesense_GeoBox(t *testing.T) {
+	builder, _ := New[placeMetadata]()
+
+	got, err := builder.ToTypesense(builder.GeoBox("lat", "lng", 1, 2, 3, 4))
+	if err != nil {
+		t.Fatalf("ToTypesense() error = %v", err)
+	}
+	if want := "(lat:[1..3] && lng:[2..4])"; got != want {
+		t.Errorf("ToTypesense() = %s, want %s", got, want)
+	}
+}
+
+func TestBuilder_ToTypesense_MapEntry(t *testing.T) {
+	builder, _ := New[attributedMetadata]()
+
+	got, err := builder.ToTypesense(builder.Where("attributes.color").Eq("red"))
+	if err != nil {
+		t.Fatalf("ToTypesense() error = %v", err)
+	}
+	if want := "attributes.color:=red"; got != want {
+		t.Errorf("ToTypesense() = %s, want %s", got, want)
+	}
+}
+
+func TestBuilder_ToTypesense_Errors(t *testing.T) {
+	builder, _ := New[testMetadata]()
+	events, _ := New[eventMetadata]()
+	folded, _ := New[testMetadata](WithCaseInsensitive("category"))
+
+	tests := []struct {
+		name    string
+		filter  *Filter
+		wantErr error
+	}{
+		{"filter error", builder.Where("missing").Eq("x"), ErrFieldNotFound},
+		{"nil filter", nil, ErrInvalidFilter},
+		{"like", builder.Where("category").Like("te%"), ErrInvalidFilter},
+		{"regex", builder.Where("category").Regex("^te"), ErrInvalidFilter},
+		{"prefix", builder.Where("category").StartsWith("te"), ErrInvalidFilter},
+		{"is empty", builder.Where("tags").IsEmpty(), ErrInvalidFilter},
+		{"all", builder.All(), ErrInvalidFilter},
+		{"none", builder.None(), ErrInvalidFilter},
+		{"not range", builder.Not(builder.Where("score").Gt(0.5)), ErrInvalidFilter},
+		{"not group", builder.Not(builder.Or(builder.Where("active").Eq(true), builder.Where("count").Eq(1))), ErrInvalidFilter},
+		{"xor", builder.Xor(builder.Where("active").Eq(true), builder.Where("count").Eq(1)), ErrInvalidFilter},
+		{"backtick", builder.Where("category").Eq("a`b"), ErrInvalidFilter},
+		{"time", events.Where("created_at").Gte(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)), ErrInvalidFilter},
+		{"case insensitive", folded.Where("category").Eq("Tech"), ErrInvalidFilter},
+		{"other raw backend", builder.Raw("sql", json.RawMessage(`"1 = 1"`)), ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := builder.ToTypesense(tt.filter); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ToTypesense() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}